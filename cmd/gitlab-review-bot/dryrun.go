@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/app"
+)
+
+// runDryRunReport prints what policies in dry-run mode would do and what happened:
+//
+//	gitlab-review-bot -config config/config.yml dry-run-report -period 168h
+func runDryRunReport(args []string) error {
+	fs := flag.NewFlagSet("dry-run-report", flag.ExitOnError)
+
+	period := fs.Duration("period", 7*24*time.Hour, "age of decisions to include")
+
+	_ = fs.Parse(args)
+
+	reports, err := app.DryRunReport(fConfigPath, *period)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(service.FormatDryRunReport(reports))
+
+	return nil
+}
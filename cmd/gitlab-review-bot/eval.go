@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/evaluation"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/app"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/client/openai"
+)
+
+// runEval replays the corpus through the review pipeline:
+//
+//	gitlab-review-bot -config config/config.yml eval -corpus testdata/corpus -out report.json -baseline old.json
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+
+	corpus := fs.String("corpus", "", "directory with *.json review cases")
+	out := fs.String("out", "", "path to save the report (optional)")
+	baselinePath := fs.String("baseline", "", "report of an earlier run to compare with (optional)")
+	fake := fs.Bool("fake", false, "use deterministic fake LLM instead of OpenAI (for CI)")
+	minRecall := fs.Float64("min-recall", 0, "fail if average recall of expected findings is lower")
+	minOverlap := fs.Float64("min-overlap", 0, "fail if average overlap with the baseline is lower")
+
+	_ = fs.Parse(args)
+
+	if *corpus == "" {
+		return errors.New("-corpus is required")
+	}
+
+	cases, err := evaluation.LoadCorpus(*corpus)
+	if err != nil {
+		return err
+	}
+
+	var baseline *evaluation.Report
+	if *baselinePath != "" {
+		baseline, err = evaluation.LoadReport(*baselinePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	var report *evaluation.Report
+	if *fake {
+		// without a database cases are redacted by builtin detectors only
+		report, err = evaluation.Run(evaluation.FakeLLM{Version: openai.PromptVersion}, cases, baseline, nil)
+	} else {
+		report, err = app.Evaluate(fConfigPath, cases, baseline)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	report.Print(os.Stdout)
+
+	if *out != "" {
+		err = report.Save(*out)
+		if err != nil {
+			return err
+		}
+	}
+
+	if report.Summary.Valid < report.Summary.Cases {
+		return errors.Errorf("%d of %d reviews have invalid format", report.Summary.Cases-report.Summary.Valid, report.Summary.Cases)
+	}
+
+	if report.Summary.ExpectedRecall < *minRecall {
+		return errors.Errorf("recall %.2f is lower than %.2f", report.Summary.ExpectedRecall, *minRecall)
+	}
+
+	if report.Summary.BaselineOverlap != nil && *report.Summary.BaselineOverlap < *minOverlap {
+		return errors.Errorf("overlap %.2f is lower than %.2f", *report.Summary.BaselineOverlap, *minOverlap)
+	}
+
+	return nil
+}
+
+// runExport saves stored merge requests and commits as review cases:
+//
+//	gitlab-review-bot -config config/config.yml eval-export -corpus testdata/corpus -limit 20
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("eval-export", flag.ExitOnError)
+
+	corpus := fs.String("corpus", "", "directory to save *.json review cases")
+	limit := fs.Int("limit", 20, "max number of merge requests and commits per project")
+
+	_ = fs.Parse(args)
+
+	if *corpus == "" {
+		return errors.New("-corpus is required")
+	}
+
+	err := os.MkdirAll(*corpus, 0o750)
+	if err != nil {
+		return errors.Wrap(err, "failed to create corpus directory")
+	}
+
+	return app.ExportCorpus(fConfigPath, *corpus, *limit)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/app"
+)
+
+// runFeedbackReport prints usefulness of AI comments by teams and categories:
+//
+//	gitlab-review-bot -config config/config.yml feedback-report -period 720h
+func runFeedbackReport(args []string) error {
+	fs := flag.NewFlagSet("feedback-report", flag.ExitOnError)
+
+	period := fs.Duration("period", 30*24*time.Hour, "age of AI comments to include")
+
+	_ = fs.Parse(args)
+
+	reports, err := app.FeedbackReport(fConfigPath, *period)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(service.FormatFeedbackReport(reports))
+
+	return nil
+}
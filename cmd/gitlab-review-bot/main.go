@@ -20,6 +20,50 @@ func init() {
 func main() {
 	flag.Parse()
 
+	// subcommands
+	switch flag.Arg(0) {
+	case "eval":
+		err := runEval(flag.Args()[1:])
+		if err != nil {
+			log.Error().Err(err).Msg("evaluation failed")
+			os.Exit(1)
+		}
+
+		return
+	case "eval-export":
+		err := runExport(flag.Args()[1:])
+		if err != nil {
+			log.Error().Err(err).Msg("export failed")
+			os.Exit(2)
+		}
+
+		return
+	case "feedback-report":
+		err := runFeedbackReport(flag.Args()[1:])
+		if err != nil {
+			log.Error().Err(err).Msg("feedback report failed")
+			os.Exit(2)
+		}
+
+		return
+	case "dry-run-report":
+		err := runDryRunReport(flag.Args()[1:])
+		if err != nil {
+			log.Error().Err(err).Msg("dry-run report failed")
+			os.Exit(2)
+		}
+
+		return
+	case "trainee-stats":
+		err := runTraineeStats(flag.Args()[1:])
+		if err != nil {
+			log.Error().Err(err).Msg("trainee stats failed")
+			os.Exit(2)
+		}
+
+		return
+	}
+
 	a, err := app.New(fConfigPath)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create app")
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/app"
+)
+
+// runTraineeStats prints how many reviews trainees shadowed:
+//
+//	gitlab-review-bot -config config/config.yml trainee-stats
+func runTraineeStats(args []string) error {
+	fs := flag.NewFlagSet("trainee-stats", flag.ExitOnError)
+
+	_ = fs.Parse(args)
+
+	stats, err := app.TraineeStats(fConfigPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(service.FormatTraineeStats(stats))
+
+	return nil
+}
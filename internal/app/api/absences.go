@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+type AbsenceService interface {
+	DeclareAbsence(absence *ds.Absence) error
+	UserAbsences(userID int) ([]*ds.Absence, error)
+	CancelAbsence(userID int, id string) (bool, error)
+	ImportAbsences(userID int, calendar []byte) (int, error)
+}
+
+type absenceRequest struct {
+	UserID int       `json:"user_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+type absenceResponse struct {
+	ID     string           `json:"id"`
+	UserID int              `json:"user_id"`
+	From   time.Time        `json:"from"`
+	To     time.Time        `json:"to"`
+	Reason string           `json:"reason"`
+	Source ds.AbsenceSource `json:"source"`
+}
+
+type importResponse struct {
+	Imported int `json:"imported"`
+}
+
+func toResponse(absence *ds.Absence) absenceResponse {
+	return absenceResponse{
+		ID:     absence.ID,
+		UserID: absence.UserID,
+		From:   absence.From,
+		To:     absence.To,
+		Reason: absence.Reason,
+		Source: absence.Source,
+	}
+}
+
+// absences handles
+//
+//	GET /api/absences?user_id=1 - current and upcoming absences of the user
+//	POST /api/absences - declares the absence, "to" is exclusive
+func (s *Server) absences(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		userID, err := userIDParam(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		absences, err := s.svc.UserAbsences(userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		res := make([]absenceResponse, 0, len(absences))
+		for _, absence := range absences {
+			res = append(res, toResponse(absence))
+		}
+
+		writeJSON(w, http.StatusOK, res)
+	case http.MethodPost:
+		body := absenceRequest{}
+
+		err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBodySize)).Decode(&body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid body"))
+			return
+		}
+
+		absence := &ds.Absence{
+			UserID: body.UserID,
+			From:   body.From,
+			To:     body.To,
+			Reason: body.Reason,
+			Source: ds.AbsenceSourceAPI,
+		}
+
+		err = s.svc.DeclareAbsence(absence)
+		if err != nil {
+			writeError(w, serviceStatus(err), err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, toResponse(absence))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s is not allowed", req.Method))
+	}
+}
+
+// absence handles
+//
+//	DELETE /api/absences/<id>?user_id=1 - cancels the absence
+//	POST /api/absences/import?user_id=1 - replaces imported absences of the user by events of the ICS body
+func (s *Server) absence(w http.ResponseWriter, req *http.Request) {
+	id := strings.TrimPrefix(req.URL.Path, "/api/absences/")
+
+	userID, err := userIDParam(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case id == "import" && req.Method == http.MethodPost:
+		calendar, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "failed to read calendar"))
+			return
+		}
+
+		imported, err := s.svc.ImportAbsences(userID, calendar)
+		if err != nil {
+			writeError(w, serviceStatus(err), err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, importResponse{Imported: imported})
+	case id != "" && id != "import" && req.Method == http.MethodDelete:
+		deleted, err := s.svc.CancelAbsence(userID, id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !deleted {
+			writeError(w, http.StatusNotFound, errors.Errorf("absence %s is not found", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, errors.Errorf("%s %s is not found", req.Method, req.URL.Path))
+	}
+}
+
+func userIDParam(req *http.Request) (int, error) {
+	userID, err := strconv.Atoi(req.URL.Query().Get("user_id"))
+	if err != nil || userID <= 0 {
+		return 0, errors.New("user_id is required")
+	}
+
+	return userID, nil
+}
+
+func serviceStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidAbsence) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+type fakeAbsences struct {
+	declared []*ds.Absence
+	calendar string
+}
+
+func (f *fakeAbsences) DeclareAbsence(absence *ds.Absence) error {
+	if !absence.To.After(absence.From) {
+		return errors.Wrap(service.ErrInvalidAbsence, "absence must end after it starts")
+	}
+
+	absence.ID = "a1"
+	f.declared = append(f.declared, absence)
+
+	return nil
+}
+
+func (f *fakeAbsences) UserAbsences(userID int) ([]*ds.Absence, error) {
+	return f.declared, nil
+}
+
+func (f *fakeAbsences) CancelAbsence(userID int, id string) (bool, error) {
+	return id == "a1", nil
+}
+
+func (f *fakeAbsences) ImportAbsences(userID int, calendar []byte) (int, error) {
+	f.calendar = string(calendar)
+	return 2, nil
+}
+
+func TestServer_absences(t *testing.T) {
+	t.Parallel()
+
+	svc := &fakeAbsences{}
+	s, err := New(":0", "secret", svc)
+	require.NoError(t, err)
+
+	do := func(method, target, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		s.http.Handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	tests := []struct {
+		name, method, target, body, token string
+		wantStatus                        int
+		wantBody                          string
+	}{
+		{
+			name: "invalid token", method: http.MethodGet, target: "/api/absences?user_id=1", token: "guess",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "declare", method: http.MethodPost, target: "/api/absences", token: "secret",
+			body:       `{"user_id": 1, "from": "2030-05-06T00:00:00Z", "to": "2030-05-11T00:00:00Z", "reason": "vacation"}`,
+			wantStatus: http.StatusCreated, wantBody: `"id":"a1"`,
+		},
+		{
+			name: "invalid absence", method: http.MethodPost, target: "/api/absences", token: "secret",
+			body:       `{"user_id": 1, "from": "2030-05-11T00:00:00Z", "to": "2030-05-06T00:00:00Z"}`,
+			wantStatus: http.StatusBadRequest, wantBody: "must end after it starts",
+		},
+		{
+			name: "list", method: http.MethodGet, target: "/api/absences?user_id=1", token: "secret",
+			wantStatus: http.StatusOK, wantBody: `"source":"api"`,
+		},
+		{
+			name: "list without user", method: http.MethodGet, target: "/api/absences", token: "secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "cancel", method: http.MethodDelete, target: "/api/absences/a1?user_id=1", token: "secret",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "cancel unknown", method: http.MethodDelete, target: "/api/absences/a2?user_id=1", token: "secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "import", method: http.MethodPost, target: "/api/absences/import?user_id=1", token: "secret",
+			body: "BEGIN:VCALENDAR", wantStatus: http.StatusOK, wantBody: `{"imported":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		w := do(tt.method, tt.target, tt.body, tt.token)
+		require.Equal(t, tt.wantStatus, w.Code, tt.name)
+		require.Contains(t, w.Body.String(), tt.wantBody, tt.name)
+	}
+
+	require.Equal(t, "BEGIN:VCALENDAR", svc.calendar)
+
+	_, err = New(":0", "", svc)
+	require.Error(t, err, "token is required")
+}
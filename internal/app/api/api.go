@@ -0,0 +1,101 @@
+// Package api serves the HTTP API of the bot, requests are authorized by the bearer token.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	readTimeout = 10 * time.Second
+	// maxBodySize limits uploaded calendars
+	maxBodySize = 4 << 20
+)
+
+type Server struct {
+	token string
+	svc   AbsenceService
+	http  *http.Server
+}
+
+func New(listen, token string, svc AbsenceService) (*Server, error) {
+	if token == "" {
+		return nil, errors.New("api token is required")
+	}
+
+	s := &Server{
+		token: token,
+		svc:   svc,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/absences", s.authorized(s.absences))
+	mux.HandleFunc("/api/absences/", s.authorized(s.absence))
+
+	s.http = &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: readTimeout,
+		ReadTimeout:       readTimeout,
+	}
+
+	return s, nil
+}
+
+// Run serves requests in the background
+func (s *Server) Run() {
+	go func() {
+		err := s.http.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Str("addr", s.http.Addr).Msg("api server stopped")
+		}
+	}()
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), readTimeout)
+	defer cancel()
+
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+
+		next(w, req)
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write api response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		log.Error().Err(err).Msg("api request failed")
+	}
+
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,47 @@
+package ds
+
+import "time"
+
+type AbsenceSource string
+
+const (
+	AbsenceSourceSlack AbsenceSource = "slack"
+	AbsenceSourceAPI   AbsenceSource = "api"
+	// AbsenceSourceICS absences are replaced on every import of the user calendar
+	AbsenceSourceICS AbsenceSource = "ics"
+)
+
+// Absence is a vacation or a day off of a user, absent users are not picked as reviewers and not reminded
+type Absence struct {
+	ID string `bson:"_id"`
+	// UserID is GitLab ID of the user
+	UserID int       `bson:"user_id"`
+	From   time.Time `bson:"from"`
+	// To is exclusive
+	To     time.Time     `bson:"to"`
+	Reason string        `bson:"reason"`
+	Source AbsenceSource `bson:"source"`
+	// ExternalID is UID of the imported calendar event
+	ExternalID string `bson:"external_id,omitempty"`
+	// HandledMRs are IDs of merge requests flagged or reassigned because of the absence
+	HandledMRs []int     `bson:"handled_mrs"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// Active checks if the user is absent at the moment
+func (a *Absence) Active(at time.Time) bool {
+	return !at.Before(a.From) && at.Before(a.To)
+}
+
+// AbsentUsers returns GitLab IDs of users with active absences at the moment
+func AbsentUsers(absences []*Absence, at time.Time) map[int]bool {
+	absent := make(map[int]bool, len(absences))
+
+	for _, absence := range absences {
+		if absence.Active(at) {
+			absent[absence.UserID] = true
+		}
+	}
+
+	return absent
+}
@@ -0,0 +1,102 @@
+package ds
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+type ActionType string
+
+const (
+	ActionAddLabels    ActionType = "add_labels"
+	ActionRemoveLabels ActionType = "remove_labels"
+	ActionComment      ActionType = "comment"
+	// ActionMergeWhenPipelineSucceeds merges the approved revision after the pipeline succeeds
+	ActionMergeWhenPipelineSucceeds ActionType = "merge_when_pipeline_succeeds"
+	// ActionNotifyAuthor sends a Slack message to the author
+	ActionNotifyAuthor ActionType = "notify_author"
+	// ActionWebhook posts the merge request as JSON to the URL
+	ActionWebhook ActionType = "webhook"
+)
+
+// Action is done once every time the merge request becomes approved by the policy
+type Action struct {
+	Type   ActionType `bson:"type"`
+	Labels []string   `bson:"labels,omitempty"`
+	// Comment is the note of the comment action
+	Comment string `bson:"comment,omitempty"`
+	// Message of notify_author, the default one mentions the merge request
+	Message string `bson:"message,omitempty"`
+	// URL of the webhook
+	URL string `bson:"url,omitempty"`
+}
+
+func (a Action) Validate() error {
+	switch a.Type {
+	case ActionAddLabels, ActionRemoveLabels:
+		if len(a.Labels) == 0 {
+			return errors.New("labels are required")
+		}
+	case ActionComment:
+		if a.Comment == "" {
+			return errors.New("comment is required")
+		}
+	case ActionWebhook:
+		u, err := url.Parse(a.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("invalid url %q", a.URL)
+		}
+	case ActionMergeWhenPipelineSucceeds, ActionNotifyAuthor:
+	default:
+		return errors.Errorf("unknown action type %q", a.Type)
+	}
+
+	return nil
+}
+
+// ValidateActions checks actions of the setting with the name, e.g. "on_approved"
+func ValidateActions(name string, actions []Action) error {
+	for i, action := range actions {
+		err := action.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "%s[%d]", name, i)
+		}
+	}
+
+	return nil
+}
+
+// ActionsRecord is kept in policy metadata to do actions once per transition to approved
+type ActionsRecord struct {
+	// Approved is the state of the last processing
+	Approved bool `bson:"approved"`
+	// Transitions counts transitions to approved
+	Transitions int `bson:"transitions"`
+	// Done are keys of actions done since the last transition
+	Done []string `bson:"done"`
+}
+
+// Update sets the approval state, a transition to approved resets done actions, true on the transition
+func (r *ActionsRecord) Update(approved bool) bool {
+	if !approved {
+		r.Approved = false
+		return false
+	}
+
+	if r.Approved {
+		return false
+	}
+
+	r.Approved = true
+	r.Transitions++
+	r.Done = []string{}
+
+	return true
+}
+
+// ActionKey identifies the action of the list in ActionsRecord
+func ActionKey(i int, action Action) string {
+	return fmt.Sprintf("%d:%s", i, action.Type)
+}
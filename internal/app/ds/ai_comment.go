@@ -0,0 +1,30 @@
+package ds
+
+import "time"
+
+// AIComment is a discussion created by the bot with AI review output, its feedback is polled from GitLab
+type AIComment struct {
+	DiscussionID    string `bson:"discussion_id"`
+	NoteID          int    `bson:"note_id"`
+	ProjectID       int    `bson:"project_id"`
+	MergeRequestIID int    `bson:"mr_iid"`
+	// AuthorID is GitLab ID of the merge request author, it links the comment to teams
+	AuthorID      int        `bson:"author_id"`
+	Category      string     `bson:"category"`
+	PromptVersion string     `bson:"prompt_version"`
+	Model         string     `bson:"model"`
+	Feedback      AIFeedback `bson:"feedback"`
+	CreatedAt     time.Time  `bson:"created_at"`
+}
+
+// AIFeedback is award emoji and replies of other users
+type AIFeedback struct {
+	Up        int       `bson:"up"`
+	Down      int       `bson:"down"`
+	Replies   int       `bson:"replies"`
+	CheckedAt time.Time `bson:"checked_at"`
+}
+
+func (f AIFeedback) Votes() int {
+	return f.Up + f.Down
+}
@@ -0,0 +1,15 @@
+package ds
+
+import "time"
+
+// AIReview is a cached result of the AI code review.
+// Reviews are content-addressed: the same diff reviewed with the same prompt version and model
+// produces the same Hash, so the stored Comment can be reused instead of calling the LLM again.
+type AIReview struct {
+	Hash          string    `bson:"hash"`
+	PromptVersion string    `bson:"prompt_version"`
+	Model         string    `bson:"model"`
+	Comment       string    `bson:"comment"`
+	CreatedAt     time.Time `bson:"created_at"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
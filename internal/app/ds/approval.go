@@ -0,0 +1,17 @@
+package ds
+
+import "time"
+
+// Approval is an approve of the merge request with the revision it was given at
+type Approval struct {
+	UserID int `bson:"user_id"`
+	// SHA and VersionID are of the merge request diff version the approve was given at, empty until resolved
+	SHA       string `bson:"sha"`
+	VersionID int    `bson:"version_id,omitempty"`
+	// CheckedSHA is the last head of the merge request the approve was checked against
+	CheckedSHA string `bson:"checked_sha,omitempty"`
+	// Stale approves were given before big changes, policies don't count them until the user approves again
+	Stale bool `bson:"stale"`
+	// StaleAt is when the approve became stale, approves given after it count again
+	StaleAt *time.Time `bson:"stale_at,omitempty"`
+}
@@ -0,0 +1,103 @@
+package ds
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// PolicyNone of a branch rule leaves merge requests of matching branches without a policy
+const PolicyNone PolicyName = "none"
+
+// BranchRule routes merge requests of matching branches to another policy or other settings of the team policy
+type BranchRule struct {
+	// TargetBranch and SourceBranch are anchored globs matching whole branch names, an empty one matches any branch
+	TargetBranch string `bson:"target_branch,omitempty"`
+	SourceBranch string `bson:"source_branch,omitempty"`
+	// Policy of matching merge requests, the policy of the team if empty
+	Policy PolicyName `bson:"policy,omitempty"`
+	// PolicySettings of matching merge requests, settings of the team are kept only for the same policy
+	PolicySettings bson.Raw `bson:"policy_settings,omitempty"`
+}
+
+// Match checks if branches of the merge request match the rule
+func (r BranchRule) Match(mr *MergeRequest) bool {
+	if r.TargetBranch != "" && !glob.MatchAnchored(r.TargetBranch, mr.TargetBranch) {
+		return false
+	}
+
+	if r.SourceBranch != "" && !glob.MatchAnchored(r.SourceBranch, mr.SourceBranch) {
+		return false
+	}
+
+	return true
+}
+
+// moreSpecific checks if the rule has more patterns than the other one, or longer literal parts of patterns
+func (r BranchRule) moreSpecific(other BranchRule) bool {
+	patterns := func(rule BranchRule) int {
+		n := 0
+		if rule.TargetBranch != "" {
+			n++
+		}
+
+		if rule.SourceBranch != "" {
+			n++
+		}
+
+		return n
+	}
+
+	literals := func(rule BranchRule) int {
+		wildcards := func(r rune) bool { return r == '*' || r == '?' }
+
+		return len(strings.Join(strings.FieldsFunc(rule.TargetBranch+rule.SourceBranch, wildcards), ""))
+	}
+
+	if patterns(r) != patterns(other) {
+		return patterns(r) > patterns(other)
+	}
+
+	return literals(r) > literals(other)
+}
+
+// ForMergeRequest returns the team with the policy and settings of the most specific branch rule matching
+// the merge request (the first of equally specific ones), or the team itself if no rule matches
+func (t *Team) ForMergeRequest(mr *MergeRequest) *Team {
+	var best *BranchRule
+
+	for i := range t.Branches {
+		rule := t.Branches[i]
+		if !rule.Match(mr) {
+			continue
+		}
+
+		if best == nil || rule.moreSpecific(*best) {
+			best = &t.Branches[i]
+		}
+	}
+
+	if best == nil {
+		return t
+	}
+
+	return t.WithRule(*best)
+}
+
+// WithRule returns a copy of the team with the policy and settings of the branch rule
+func (t *Team) WithRule(rule BranchRule) *Team {
+	routed := *t
+
+	if rule.Policy != "" && rule.Policy != t.Policy {
+		routed.Policy = rule.Policy
+		routed.PolicySettings = nil
+	}
+
+	if rule.PolicySettings != nil {
+		routed.PolicySettings = rule.PolicySettings
+	}
+
+	return &routed
+}
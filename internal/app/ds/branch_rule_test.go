@@ -0,0 +1,51 @@
+package ds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTeam_ForMergeRequest(t *testing.T) {
+	strict, err := bson.Marshal(bson.M{"required_developers": 3})
+	require.NoError(t, err)
+
+	team := &Team{
+		Policy:         "rd",
+		PolicySettings: bson.Raw{},
+		Branches: []BranchRule{
+			{TargetBranch: "release/*", PolicySettings: strict},
+			{TargetBranch: "release/*", SourceBranch: "hotfix/*", Policy: "tlar"},
+			{SourceBranch: "docs/*", Policy: "dr"},
+			{SourceBranch: "docs/changelog-*", Policy: PolicyNone},
+			{TargetBranch: "main", Policy: "tlar"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		source       string
+		target       string
+		wantPolicy   PolicyName
+		wantSettings bson.Raw
+	}{
+		{name: "no rule", source: "feature/1", target: "master", wantPolicy: "rd", wantSettings: bson.Raw{}},
+		{name: "settings of the policy", source: "feature/1", target: "release/1.2", wantPolicy: "rd", wantSettings: strict},
+		{name: "both branches are more specific", source: "hotfix/1", target: "release/1.2", wantPolicy: "tlar"},
+		{name: "another policy", source: "docs/readme", target: "master", wantPolicy: "dr"},
+		{name: "longer pattern is more specific", source: "docs/changelog-1.2", target: "master", wantPolicy: PolicyNone},
+		{name: "whole branch name", source: "feature/1", target: "main", wantPolicy: "tlar"},
+		{name: "not a part of branch name", source: "feature/1", target: "feature/main", wantPolicy: "rd", wantSettings: bson.Raw{}},
+	}
+
+	for _, tt := range tests {
+		routed := team.ForMergeRequest(&MergeRequest{SourceBranch: tt.source, TargetBranch: tt.target})
+
+		require.Equal(t, tt.wantPolicy, routed.Policy, tt.name)
+		require.Equal(t, tt.wantSettings, routed.PolicySettings, tt.name)
+	}
+
+	require.Same(t, team, team.ForMergeRequest(&MergeRequest{SourceBranch: "feature/1", TargetBranch: "master"}),
+		"the team itself without matching rules")
+}
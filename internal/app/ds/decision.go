@@ -0,0 +1,32 @@
+package ds
+
+import "time"
+
+type DecisionKind string
+
+const (
+	// DecisionReviewers is the reviewers list the policy would set
+	DecisionReviewers DecisionKind = "reviewers"
+	// DecisionApproved is a transition to approved by the policy with actions it would do
+	DecisionApproved DecisionKind = "approved"
+)
+
+// Decision is what a policy in dry-run mode would do instead of calling GitLab
+type Decision struct {
+	Policy         PolicyName   `bson:"policy"`
+	MergeRequestID int          `bson:"mr_id"`
+	ProjectID      int          `bson:"project_id"`
+	IID            int          `bson:"iid"`
+	Kind           DecisionKind `bson:"kind"`
+	// Reviewers are GitLab IDs of all reviewers of DecisionReviewers
+	Reviewers []int `bson:"reviewers,omitempty"`
+	// Team and Actions are of DecisionApproved
+	Team      string    `bson:"team,omitempty"`
+	Actions   []Action  `bson:"actions,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// DryRun is the name policy metadata and decisions of the policy in dry-run mode are stored under
+func (n PolicyName) DryRun() PolicyName {
+	return n + ":dry-run"
+}
@@ -0,0 +1,29 @@
+package ds
+
+// DiffRulesSettings are deterministic checks of the changes, they work without an LLM
+type DiffRulesSettings struct {
+	Rules []DiffRule `bson:"rules"`
+	// Positioned posts line findings of merge requests as comments on the lines instead of a single note
+	Positioned bool `bson:"positioned"`
+}
+
+// DiffRule is triggered by an added line matching AddedLine or by changed Paths without any RequireChanged file.
+//
+// Examples:
+//
+//	{name: "todo", paths: ["*.go"], added_line: "TODO", added_line_exclude: "TODO\\([A-Z]+-\\d+\\)"}
+//	{name: "rollback", paths: ["migrations/*.up.sql"], require_changed: ["migrations/*.down.sql"]}
+type DiffRule struct {
+	Name    string `bson:"name"`
+	Message string `bson:"message"`
+	// Paths are globs of files the rule applies to, all files if empty
+	Paths []string `bson:"paths"`
+	// ExcludePaths are globs of files the rule ignores
+	ExcludePaths []string `bson:"exclude_paths"`
+	// AddedLine is a regular expression matched against every added line
+	AddedLine string `bson:"added_line"`
+	// AddedLineExclude is a regular expression of added lines which are fine even if AddedLine matches
+	AddedLineExclude string `bson:"added_line_exclude"`
+	// RequireChanged are globs, at least one matching file must be changed along with Paths
+	RequireChanged []string `bson:"require_changed"`
+}
@@ -0,0 +1,40 @@
+package ds
+
+import "time"
+
+// DefaultShadows is the number of trainees shadowing every review
+const DefaultShadows = 1
+
+// MentorshipSettings of trainees (members with the trainee label) of the team
+type MentorshipSettings struct {
+	// Shadows is how many trainees review on top of the required reviewers, DefaultShadows if zero
+	Shadows int `bson:"shadows,omitempty"`
+	// GraduateAfter shadowed reviews the trainee becomes a developer, never if zero
+	GraduateAfter int `bson:"graduate_after,omitempty"`
+}
+
+// ShadowReview is a review of a trainee on top of the required reviewers, approves of trainees are not counted
+type ShadowReview struct {
+	TeamID         string    `bson:"team_id"`
+	TraineeID      int       `bson:"trainee_id"`
+	MergeRequestID int       `bson:"mr_id"`
+	ProjectID      int       `bson:"project_id"`
+	IID            int       `bson:"iid"`
+	AssignedAt     time.Time `bson:"assigned_at"`
+	// ApprovedAt is when the trainee approved the merge request, the review counts as shadowed since then
+	ApprovedAt *time.Time `bson:"approved_at,omitempty"`
+}
+
+// MentorshipSettings returns mentorship settings of the team with defaults
+func (t *Team) MentorshipSettings() MentorshipSettings {
+	s := MentorshipSettings{}
+	if t.Mentorship != nil {
+		s = *t.Mentorship
+	}
+
+	if s.Shadows <= 0 {
+		s.Shadows = DefaultShadows
+	}
+
+	return s
+}
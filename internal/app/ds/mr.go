@@ -1,6 +1,7 @@
 package ds
 
 import (
+	"sort"
 	"strings"
 	"time"
 )
@@ -31,6 +32,7 @@ type MergeRequest struct {
 	Assignees    []*BasicUser `bson:"assignees"`
 	Reviewers    []*BasicUser `bson:"reviewers"`
 	Draft        bool         `bson:"draft"`
+	Labels       []string     `bson:"labels"`
 	SHA          string       `bson:"sha"`
 	URL          string       `bson:"url"`
 	UpdatedAt    *time.Time   `bson:"updated_at"`
@@ -38,6 +40,73 @@ type MergeRequest struct {
 
 	// Additional information
 	Approves []*BasicUser `bson:"approves"`
+	// Assignments are times reviewers were assigned, kept while they are reviewers
+	Assignments []*Assignment `bson:"assignments,omitempty"`
+	// Approvals are revisions of approves, stale ones are not in Approves
+	Approvals []*Approval `bson:"approvals,omitempty"`
+	Risk      *Risk       `bson:"risk,omitempty"`
+	// InvolvedTeams are IDs of teams whose paths are touched, besides teams of the author
+	InvolvedTeams []string `bson:"involved_teams,omitempty"`
+	// ChangedPaths are new and old paths of files changed by the revision,
+	// stored only when teams are routed by paths or match skills
+	ChangedPaths []string `bson:"changed_paths,omitempty"`
+	// ReviewedSHA is the last revision reviewed successfully, it is saved after the review separately
+	ReviewedSHA string `bson:"reviewed_sha,omitempty"`
+	// PostedFindings are keys of rule findings commented already, they are saved separately as well
+	PostedFindings []string `bson:"posted_findings,omitempty"`
+	// PickReasons are saved by the reviewer selection separately, they are never set with the merge request
+	PickReasons []*PickReason `bson:"pick_reasons,omitempty"`
+	// PendingTeams are IDs of involved teams whose policies don't approve the merge request yet,
+	// set before policies process it
+	PendingTeams []string `bson:"-"`
+}
+
+// Assignment is the time the reviewer was assigned to the merge request (seen assigned by the bot first)
+type Assignment struct {
+	UserID     int       `bson:"user_id"`
+	AssignedAt time.Time `bson:"assigned_at"`
+}
+
+// AssignedAt returns when the reviewer was assigned, nil if the user isn't a tracked reviewer.
+// Reviewers of merge requests stored before assignments were tracked are taken as assigned at creation.
+func (a *MergeRequest) AssignedAt(userID int) *time.Time {
+	for _, assignment := range a.Assignments {
+		if assignment.UserID == userID {
+			return &assignment.AssignedAt
+		}
+	}
+
+	if len(a.Assignments) > 0 {
+		return nil
+	}
+
+	for _, reviewer := range a.Reviewers {
+		if reviewer.GitLabID == userID {
+			return a.CreatedAt
+		}
+	}
+
+	return nil
+}
+
+// PickReason returns why the reviewer was picked, nil if the reviewer was not picked for a reason
+func (a *MergeRequest) PickReason(userID int) *PickReason {
+	for _, reason := range a.PickReasons {
+		if reason.UserID == userID {
+			return reason
+		}
+	}
+
+	return nil
+}
+
+// RiskLevel returns the classified risk level, empty if the merge request is not classified
+func (a *MergeRequest) RiskLevel() RiskLevel {
+	if a == nil || a.Risk == nil {
+		return ""
+	}
+
+	return a.Risk.Level
 }
 
 // IsEqual checks if two merge requests are equal (according to basic information)
@@ -94,6 +163,10 @@ func (a *MergeRequest) IsEqual(b *MergeRequest) bool {
 		return false
 	}
 
+	if !areLabelsEqual(a.Labels, b.Labels) {
+		return false
+	}
+
 	if a.SHA != b.SHA {
 		return false
 	}
@@ -112,3 +185,44 @@ func (a *MergeRequest) IsEqual(b *MergeRequest) bool {
 
 	return true
 }
+
+// WaitsForTeams checks if policies of other involved teams don't approve the merge request yet
+func (a *MergeRequest) WaitsForTeams(teamID string) bool {
+	for _, id := range a.PendingTeams {
+		if id != teamID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasLabel checks if the merge request has the label
+func (a *MergeRequest) HasLabel(label string) bool {
+	for _, l := range a.Labels {
+		if l == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+func areLabelsEqual(left, right []string) bool {
+	if len(left) != len(right) {
+		return false
+	}
+
+	l := append([]string{}, left...)
+	r := append([]string{}, right...)
+	sort.Strings(l)
+	sort.Strings(r)
+
+	for i := range l {
+		if l[i] != r[i] {
+			return false
+		}
+	}
+
+	return true
+}
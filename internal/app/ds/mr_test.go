@@ -52,6 +52,18 @@ func TestMergeRequest_IsEqual(t *testing.T) {
 			b:    &MergeRequest{Title: "title2"},
 			want: false,
 		},
+		{
+			name: "same labels in different order",
+			a:    &MergeRequest{Labels: []string{"backend", "risk::low"}},
+			b:    &MergeRequest{Labels: []string{"risk::low", "backend"}},
+			want: true,
+		},
+		{
+			name: "different labels",
+			a:    &MergeRequest{Labels: []string{"backend"}},
+			b:    &MergeRequest{Labels: []string{"backend", "risk::low"}},
+			want: false,
+		},
 		{
 			name: "different assignees",
 			a:    &MergeRequest{Assignees: []*BasicUser{{GitLabID: 123}}},
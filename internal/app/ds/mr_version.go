@@ -0,0 +1,10 @@
+package ds
+
+import "time"
+
+// MergeRequestVersion is a diff version of the merge request, GitLab creates one on every push
+type MergeRequestVersion struct {
+	ID        int
+	HeadSHA   string
+	CreatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package ds
+
+import "time"
+
+// Note is a comment or a system event (e.g. an approve) of the merge request timeline
+type Note struct {
+	AuthorID  int
+	Body      string
+	System    bool
+	CreatedAt time.Time
+	// ReviewRequested are users requested to review in the system note
+	ReviewRequested []int
+}
+
+// noteApproved is the body of the system note of an approve
+const noteApproved = "approved this merge request"
+
+// IsApprove checks if the note is the system note of an approve
+func (n *Note) IsApprove() bool {
+	return n.System && n.Body == noteApproved
+}
@@ -0,0 +1,10 @@
+package ds
+
+// PickReason explains why the reviewer was picked
+type PickReason struct {
+	UserID int `bson:"user_id"`
+	// Skills of the reviewer needed by changed paths
+	Skills []string `bson:"skills,omitempty"`
+	// CodeOwner of changed files
+	CodeOwner bool `bson:"code_owner,omitempty"`
+}
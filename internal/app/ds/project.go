@@ -3,8 +3,30 @@ package ds
 import "time"
 
 type Project struct {
-	ID        int       `bson:"id"`
-	Name      string    `bson:"name"`
-	URL       string    `bson:"url"`
-	CreatedAt time.Time `bson:"created_at"`
+	ID            int                   `bson:"id"`
+	Name          string                `bson:"name"`
+	URL           string                `bson:"url"`
+	CreatedAt     time.Time             `bson:"created_at"`
+	Redaction     RedactionSettings     `bson:"redaction"`
+	ReviewContext ReviewContextSettings `bson:"review_context"`
+	DiffRules     DiffRulesSettings     `bson:"diff_rules"`
+	Risk          RiskSettings          `bson:"risk"`
+}
+
+// RedactionSettings controls masking of secrets and personal data in diffs before they are sent to the LLM.
+// Builtin detectors are enabled by default.
+type RedactionSettings struct {
+	// Disabled turns off redaction for the project completely
+	Disabled bool `bson:"disabled"`
+	// DisabledDetectors is a list of builtin detectors to skip (e.g. "email", "high_entropy_string")
+	DisabledDetectors []string `bson:"disabled_detectors"`
+	// Patterns are project specific regular expressions to mask
+	Patterns []RedactionPattern `bson:"patterns"`
+	// WarnOnSecrets posts a separate note when a likely secret was committed
+	WarnOnSecrets bool `bson:"warn_on_secrets"`
+}
+
+type RedactionPattern struct {
+	Name  string `bson:"name"`
+	Regex string `bson:"regex"`
 }
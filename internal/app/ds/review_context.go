@@ -0,0 +1,37 @@
+package ds
+
+// ReviewContextSettings controls enrichment of AI review prompts with the surrounding code of changed files
+type ReviewContextSettings struct {
+	Enabled bool `bson:"enabled"`
+	// Lines is a number of lines added before and after every changed hunk
+	Lines int `bson:"lines"`
+	// WholeFunction extends every changed hunk to the enclosing top-level declaration
+	WholeFunction bool `bson:"whole_function"`
+	// RelatedFiles adds files related to the changed ones (e.g. the test file next to the changed file)
+	RelatedFiles bool `bson:"related_files"`
+	// MaxTokens is a budget of the context in the prompt
+	MaxTokens int `bson:"max_tokens"`
+}
+
+const (
+	DefaultReviewContextLines     = 20
+	DefaultReviewContextMaxTokens = 4000
+)
+
+// ContextLines returns configured number of lines or default one
+func (s ReviewContextSettings) ContextLines() int {
+	if s.Lines <= 0 {
+		return DefaultReviewContextLines
+	}
+
+	return s.Lines
+}
+
+// TokenBudget returns configured budget or default one
+func (s ReviewContextSettings) TokenBudget() int {
+	if s.MaxTokens <= 0 {
+		return DefaultReviewContextMaxTokens
+	}
+
+	return s.MaxTokens
+}
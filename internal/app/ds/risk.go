@@ -0,0 +1,84 @@
+package ds
+
+import "time"
+
+type RiskLevel string
+
+const (
+	RiskLow    RiskLevel = "low"
+	RiskMedium RiskLevel = "medium"
+	RiskHigh   RiskLevel = "high"
+)
+
+// Rank orders risk levels, unknown level is the lowest
+func (l RiskLevel) Rank() int {
+	switch l {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Risk is a classification of the merge request revision
+type Risk struct {
+	Level RiskLevel `bson:"level"`
+	// Areas are touched parts of the system (e.g. db, api, infra, ui)
+	Areas  []string `bson:"areas"`
+	Reason string   `bson:"reason"`
+	// SHA is the classified revision
+	SHA          string    `bson:"sha"`
+	ClassifiedAt time.Time `bson:"classified_at"`
+}
+
+// RiskSettings enables risk classification of merge requests of the project
+type RiskSettings struct {
+	Enabled bool `bson:"enabled"`
+	// LLM asks the LLM for a judgement in addition to diff stats and touched paths
+	LLM bool `bson:"llm"`
+	// Labels applies level and area labels to merge requests
+	Labels bool `bson:"labels"`
+	// LevelLabelPrefix default is "risk::" (scoped label)
+	LevelLabelPrefix string `bson:"level_label_prefix"`
+	// AreaLabelPrefix default is "area:", not a scoped label, since a merge request may touch several areas
+	// and GitLab keeps only one scoped label of a scope
+	AreaLabelPrefix string `bson:"area_label_prefix"`
+	// Areas are globs of paths by area name, DefaultRiskAreas are used if empty
+	Areas map[string][]string `bson:"areas"`
+}
+
+// DefaultRiskAreas are used if a project has no own areas
+var DefaultRiskAreas = map[string][]string{
+	"db":    {"migrations/", "*.sql", "**/repository/**"},
+	"api":   {"*.proto", "api/", "openapi*", "swagger*"},
+	"infra": {"Dockerfile", "docker-compose*", "*.tf", ".gitlab-ci.yml", ".github/", "helm/", "k8s/", "deploy/"},
+	"ui":    {"*.tsx", "*.jsx", "*.vue", "*.css", "*.scss", "*.html"},
+}
+
+func (s RiskSettings) AreaGlobs() map[string][]string {
+	if len(s.Areas) == 0 {
+		return DefaultRiskAreas
+	}
+
+	return s.Areas
+}
+
+func (s RiskSettings) LevelLabel(level RiskLevel) string {
+	if s.LevelLabelPrefix == "" {
+		return "risk::" + string(level)
+	}
+
+	return s.LevelLabelPrefix + string(level)
+}
+
+func (s RiskSettings) AreaLabel(area string) string {
+	if s.AreaLabelPrefix == "" {
+		return "area:" + area
+	}
+
+	return s.AreaLabelPrefix + area
+}
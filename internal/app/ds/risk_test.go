@@ -0,0 +1,19 @@
+package ds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskSettings_Labels(t *testing.T) {
+	t.Parallel()
+
+	defaults := RiskSettings{}
+	require.Equal(t, "risk::high", defaults.LevelLabel(RiskHigh), "levels exclude each other, so the label is scoped")
+	require.Equal(t, "area:db", defaults.AreaLabel("db"), "several areas are labeled, so the label is not scoped")
+
+	custom := RiskSettings{LevelLabelPrefix: "risk/", AreaLabelPrefix: "touches/"}
+	require.Equal(t, "risk/low", custom.LevelLabel(RiskLow))
+	require.Equal(t, "touches/api", custom.AreaLabel("api"))
+}
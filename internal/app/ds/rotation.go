@@ -0,0 +1,12 @@
+package ds
+
+import "time"
+
+// RotationCursor is the last picked reviewer of a team pool in round-robin rotation
+type RotationCursor struct {
+	TeamID string `bson:"team_id"`
+	Pool   string `bson:"pool"`
+	// Last is GitLab ID of the last picked reviewer
+	Last      int       `bson:"last"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package ds
+
+import "time"
+
+type SLAEscalationTarget string
+
+const (
+	// SLAEscalateToLeads sends direct messages to leads of the team
+	SLAEscalateToLeads SLAEscalationTarget = "leads"
+	// SLAEscalateToChannel posts to the notifications channel of the team
+	SLAEscalateToChannel SLAEscalationTarget = "channel"
+)
+
+// SLASettings are review deadlines of the team, durations are working time like "4h" or working days like "2d"
+type SLASettings struct {
+	// FirstResponse is the time to the first comment or approve of a reviewer, empty disables it
+	FirstResponse string `bson:"first_response"`
+	// Approve is the time to the approve of a reviewer, empty disables it
+	Approve string `bson:"approve"`
+	// EscalateAfter is the time from the reviewer nudge to the escalation, the escalation comes with the nudge if empty
+	EscalateAfter string              `bson:"escalate_after"`
+	EscalateTo    SLAEscalationTarget `bson:"escalate_to"`
+	// ReassignAfter is the time from the escalation to the reviewer replacement, reviewers are not replaced if empty
+	ReassignAfter string       `bson:"reassign_after"`
+	WorkingHours  WorkingHours `bson:"working_hours"`
+}
+
+type WorkingHours struct {
+	// Timezone is the IANA name, UTC if empty
+	Timezone string `bson:"timezone"`
+	// Start and End are "HH:MM", 10:00-19:00 if empty
+	Start string `bson:"start"`
+	End   string `bson:"end"`
+	// Weekdays are working days from 0 (Sunday) to 6, Monday to Friday if empty
+	Weekdays []time.Weekday `bson:"weekdays"`
+}
+
+// ReviewSLA is the state of review deadlines of the team reviewers of the merge request
+type ReviewSLA struct {
+	MergeRequestID int            `bson:"mr_id"`
+	TeamID         string         `bson:"team_id"`
+	Reviewers      []*SLAReviewer `bson:"reviewers"`
+}
+
+type SLAReviewer struct {
+	ID int `bson:"id"`
+	// RequestedAt is the time of the review request in the merge request timeline
+	RequestedAt time.Time  `bson:"requested_at"`
+	RespondedAt *time.Time `bson:"responded_at,omitempty"`
+	// Fired are "<kind>:<step>" of fired steps
+	Fired []string `bson:"fired"`
+}
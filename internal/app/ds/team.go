@@ -1,15 +1,51 @@
 package ds
 
-import "time"
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
 
 type PolicyName string
 
+type CodeOwnersMode string
+
+const (
+	// CodeOwnersPrefer picks at least one owner of every pool if there is no owner among reviewers yet
+	CodeOwnersPrefer CodeOwnersMode = "prefer"
+	// CodeOwnersRequire picks owners only, slots stay empty if there are not enough of them
+	CodeOwnersRequire CodeOwnersMode = "require"
+)
+
 type Team struct {
-	ID      string  `bson:"_id"`
-	Name    string  `bson:"name"`
-	Members []*User `bson:"members"`
-	// TODO: add PolicySettings as bson.RawDocument
-	Policy        PolicyName           `bson:"policy"`
+	ID      string     `bson:"_id"`
+	Name    string     `bson:"name"`
+	Members []*User    `bson:"members"`
+	Policy  PolicyName `bson:"policy"`
+	// PolicySettings is decoded by the policy into its own settings
+	PolicySettings bson.Raw `bson:"policy_settings,omitempty"`
+	// Branches route merge requests of some branches to other policies or settings, the most specific rule wins
+	Branches []BranchRule `bson:"branches,omitempty"`
+	// Selection is the reviewer selection strategy of the team (e.g. "round_robin"), the bot default if empty
+	Selection string `bson:"selection,omitempty"`
+	// CodeOwners is how owners of changed files from CODEOWNERS are picked as reviewers, ignored if empty
+	CodeOwners CodeOwnersMode `bson:"code_owners,omitempty"`
+	// OnApproved actions are done once every time a merge request becomes approved by the policy
+	OnApproved []Action `bson:"on_approved,omitempty"`
+	// Paths are globs of paths owned by the team, merge requests of other teams touching them are reviewed
+	// by the team as well
+	Paths []string `bson:"paths,omitempty"`
+	// Skills maps skills of members to globs of paths needing them, e.g. {"db": ["migrations/"]}
+	Skills map[string][]string `bson:"skills,omitempty"`
+	// Mentorship adds trainees as shadow reviewers, defaults are used if nil
+	Mentorship *MentorshipSettings `bson:"mentorship,omitempty"`
+	// DryRun policy only stores and logs its decisions without calling GitLab
+	DryRun bool `bson:"dry_run,omitempty"`
+	// SLA escalates reviews without a response in time, disabled if nil
+	SLA           *SLASettings         `bson:"sla,omitempty"`
 	Notifications NotificationSettings `bson:"notifications"`
 	CreatedAt     time.Time            `bson:"created_at"`
 }
@@ -25,6 +61,50 @@ func (t *Team) Teammate(user *BasicUser) bool {
 	return false
 }
 
+// Involved checks if the merge request is authored by a teammate or touches paths of the team
+func (t *Team) Involved(mr *MergeRequest) bool {
+	if t.Teammate(mr.Author) {
+		return true
+	}
+
+	for _, id := range mr.InvolvedTeams {
+		if id == t.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SkillsOf returns sorted skills needed by the paths
+func (t *Team) SkillsOf(paths []string) []string {
+	skills := make([]string, 0)
+
+	for skill, patterns := range t.Skills {
+		for _, path := range paths {
+			if glob.MatchAny(patterns, path) {
+				skills = append(skills, skill)
+				break
+			}
+		}
+	}
+
+	sort.Strings(skills)
+
+	return skills
+}
+
+// Member returns the member by GitLab ID, nil if the user is not a member
+func (t *Team) Member(id int) *User {
+	for _, member := range t.Members {
+		if member.GitLabID == id {
+			return member
+		}
+	}
+
+	return nil
+}
+
 // Developers returns all developers of a team/list of users
 func Developers(users []*User) []*User {
 	devs := make([]*User, 0, len(users))
@@ -50,3 +130,16 @@ func Leads(users []*User) []*User {
 
 	return leads
 }
+
+// Trainees returns trainees of a team/list of users
+func Trainees(users []*User) []*User {
+	trainees := make([]*User, 0, len(users))
+
+	for _, user := range users {
+		if user.Labels.Has(TraineeLabel) {
+			trainees = append(trainees, user)
+		}
+	}
+
+	return trainees
+}
@@ -50,6 +50,8 @@ type UserLabel string
 const (
 	LeadLabel      UserLabel = "lead"
 	DeveloperLabel UserLabel = "developer"
+	// TraineeLabel is of new members shadowing reviews, their approves are not counted by policies
+	TraineeLabel UserLabel = "trainee"
 )
 
 type UserLabels []UserLabel
@@ -62,4 +64,11 @@ type User struct {
 	*BasicUser `bson:"basic_user"`
 	SlackID    string     `bson:"slack_id"`
 	Labels     UserLabels `bson:"labels"`
+	// Skills are free-form expertise tags (e.g. backend, db, ios) matched with skills of changed paths
+	Skills []string `bson:"skills,omitempty"`
+}
+
+// MatchedSkills returns skills of the user among needed ones in the order of needed
+func (u *User) MatchedSkills(needed []string) []string {
+	return lo.Filter(needed, func(skill string, _ int) bool { return lo.Contains(u.Skills, skill) })
 }
@@ -4,9 +4,12 @@ type UserEventType int
 
 const (
 	UserEventTypeMRRequest UserEventType = iota
+	UserEventTypeAbsence
 )
 
 type UserEvent struct {
 	Type   UserEventType
 	UserID string
+	// Text are arguments of the slash command
+	Text string
 }
@@ -0,0 +1,150 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// Case is a stored review input with optional expectations
+type Case struct {
+	Name string `json:"name"`
+	// ProjectID selects redaction settings of the project, builtin detectors are used if it is unknown
+	ProjectID   int           `json:"project_id,omitempty"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Diffs       []CaseDiff    `json:"diffs"`
+	Context     []CaseContext `json:"context,omitempty"`
+	// Expected are phrases (case-insensitive) which should be found in the review findings
+	Expected []string `json:"expected,omitempty"`
+}
+
+type CaseDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Content     string `json:"content"`
+	NewFile     bool   `json:"new_file,omitempty"`
+	RenamedFile bool   `json:"renamed_file,omitempty"`
+	DeletedFile bool   `json:"deleted_file,omitempty"`
+}
+
+type CaseContext struct {
+	Path     string `json:"path"`
+	FromLine int    `json:"from_line"`
+	ToLine   int    `json:"to_line"`
+	Content  string `json:"content"`
+	Related  bool   `json:"related,omitempty"`
+}
+
+func (c *Case) diffs() []*service.Diff {
+	diffs := make([]*service.Diff, 0, len(c.Diffs))
+
+	for _, d := range c.Diffs {
+		diffs = append(diffs, &service.Diff{
+			Content:     d.Content,
+			NewPath:     d.NewPath,
+			OldPath:     d.OldPath,
+			NewFile:     d.NewFile,
+			RenamedFile: d.RenamedFile,
+			DeletedFile: d.DeletedFile,
+		})
+	}
+
+	return diffs
+}
+
+func (c *Case) contexts() []*service.FileContext {
+	contexts := make([]*service.FileContext, 0, len(c.Context))
+
+	for _, fc := range c.Context {
+		contexts = append(contexts, &service.FileContext{
+			Path:     fc.Path,
+			FromLine: fc.FromLine,
+			ToLine:   fc.ToLine,
+			Content:  fc.Content,
+			Related:  fc.Related,
+		})
+	}
+
+	return contexts
+}
+
+// NewCase converts review input into a case
+func NewCase(name string, projectID int, title, description string, diffs []*service.Diff) *Case {
+	c := &Case{
+		Name:        name,
+		ProjectID:   projectID,
+		Title:       title,
+		Description: description,
+		Diffs:       make([]CaseDiff, 0, len(diffs)),
+	}
+
+	for _, d := range diffs {
+		c.Diffs = append(c.Diffs, CaseDiff{
+			OldPath:     d.OldPath,
+			NewPath:     d.NewPath,
+			Content:     d.Content,
+			NewFile:     d.NewFile,
+			RenamedFile: d.RenamedFile,
+			DeletedFile: d.DeletedFile,
+		})
+	}
+
+	return c
+}
+
+// LoadCorpus reads all *.json cases from the directory, sorted by name
+func LoadCorpus(dir string) ([]*Case, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list corpus files")
+	}
+
+	cases := make([]*Case, 0, len(files))
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read case %s", file)
+		}
+
+		c := &Case{}
+
+		err = json.Unmarshal(raw, c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode case %s", file)
+		}
+
+		if c.Name == "" {
+			c.Name = filepath.Base(file)
+		}
+
+		cases = append(cases, c)
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].Name < cases[j].Name
+	})
+
+	return cases, nil
+}
+
+// SaveCase writes the case into the directory as <name>.json
+func SaveCase(dir string, c *Case) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode case")
+	}
+
+	err = os.WriteFile(filepath.Join(dir, c.Name+".json"), raw, 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write case %s", c.Name)
+	}
+
+	return nil
+}
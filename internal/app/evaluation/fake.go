@@ -0,0 +1,52 @@
+package evaluation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FakeLLM is a deterministic reviewer without any API calls, it lets the harness run in CI.
+// It reports a few well-known smells of added lines.
+type FakeLLM struct {
+	Version string
+}
+
+var fakeSmells = []struct {
+	marker  string
+	finding string
+}{
+	{marker: "TODO", finding: "TODO without a ticket reference"},
+	{marker: "fmt.Println", finding: "fmt.Println in production code, use the logger"},
+	{marker: "panic(", finding: "panic instead of returning an error"},
+	{marker: "[REDACTED", finding: "likely secret is committed"},
+}
+
+func (f FakeLLM) GenerateAICodeReviewComment(message string) (string, error) {
+	var review strings.Builder
+
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+
+		for _, smell := range fakeSmells {
+			if strings.Contains(line, smell.marker) {
+				review.WriteString(fmt.Sprintf("- %s: `%s`\n", smell.finding, strings.TrimSpace(line[1:])))
+			}
+		}
+	}
+
+	if review.Len() == 0 {
+		return "No issues found.", nil
+	}
+
+	return review.String(), nil
+}
+
+func (f FakeLLM) Model() string {
+	return "fake"
+}
+
+func (f FakeLLM) PromptVersion() string {
+	return f.Version
+}
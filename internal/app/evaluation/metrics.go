@@ -0,0 +1,120 @@
+package evaluation
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// listItem matches top-level markdown list items: "- x", "* x", "1. x", "1) x"
+	listItem = regexp.MustCompile(`^ {0,1}(?:[-*+]|\d+[.)])\s+(.+)$`)
+	noIssues = regexp.MustCompile(`(?i)\b(no issues|lgtm|looks good)\b`)
+	wordsRe  = regexp.MustCompile(`[\pL\pN_]+`)
+)
+
+// similarityThreshold of words sets, when two findings are considered the same
+const similarityThreshold = 0.5
+
+// ParseFindings returns top-level list items of the review
+func ParseFindings(review string) []string {
+	findings := make([]string, 0)
+
+	for _, line := range strings.Split(review, "\n") {
+		m := listItem.FindStringSubmatch(strings.TrimRight(line, " \t\r"))
+		if m == nil {
+			continue
+		}
+
+		findings = append(findings, strings.TrimSpace(m[1]))
+	}
+
+	return findings
+}
+
+// ValidFormat checks that the review is either a list of findings or an explicit "no issues" answer
+func ValidFormat(review string) bool {
+	review = strings.TrimSpace(review)
+	if review == "" {
+		return false
+	}
+
+	return len(ParseFindings(review)) > 0 || noIssues.MatchString(review)
+}
+
+// ExpectedRecall is a share of the expected phrases found in the findings, 1 if nothing is expected
+func ExpectedRecall(findings []string, expected []string) float64 {
+	if len(expected) == 0 {
+		return 1
+	}
+
+	found := 0
+
+	for _, phrase := range expected {
+		for _, finding := range findings {
+			if strings.Contains(strings.ToLower(finding), strings.ToLower(phrase)) {
+				found++
+				break
+			}
+		}
+	}
+
+	return float64(found) / float64(len(expected))
+}
+
+// Overlap is a share of the findings matched between two runs, similar findings are matched once
+func Overlap(current, previous []string) float64 {
+	if len(current) == 0 && len(previous) == 0 {
+		return 1
+	}
+
+	used := make([]bool, len(previous))
+	matched := 0
+
+	for _, cur := range current {
+		for i, prev := range previous {
+			if used[i] || similarity(cur, prev) < similarityThreshold {
+				continue
+			}
+
+			used[i] = true
+			matched++
+
+			break
+		}
+	}
+
+	total := len(current)
+	if len(previous) > total {
+		total = len(previous)
+	}
+
+	return float64(matched) / float64(total)
+}
+
+// similarity is Jaccard index of lower-cased words
+func similarity(a, b string) float64 {
+	wa := words(a)
+	wb := words(b)
+
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+
+	inter := 0
+	for w := range wa {
+		if wb[w] {
+			inter++
+		}
+	}
+
+	return float64(inter) / float64(len(wa)+len(wb)-inter)
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range wordsRe.FindAllString(strings.ToLower(s), -1) {
+		set[w] = true
+	}
+
+	return set
+}
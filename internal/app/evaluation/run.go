@@ -0,0 +1,206 @@
+// Package evaluation replays stored review inputs through the AI review pipeline
+// and measures the outputs, so prompt changes can be compared before deploy.
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/pkg/redact"
+)
+
+type CaseResult struct {
+	Name           string   `json:"name"`
+	Output         string   `json:"output"`
+	Error          string   `json:"error,omitempty"`
+	Findings       []string `json:"findings"`
+	Valid          bool     `json:"valid"`
+	ExpectedRecall float64  `json:"expected_recall"`
+	// BaselineOverlap is set only if the case is found in the baseline report
+	BaselineOverlap *float64 `json:"baseline_overlap,omitempty"`
+}
+
+type Summary struct {
+	Cases    int `json:"cases"`
+	Failed   int `json:"failed"`
+	Valid    int `json:"valid"`
+	Findings int `json:"findings"`
+	// ExpectedRecall and BaselineOverlap are averages over the cases
+	ExpectedRecall  float64  `json:"expected_recall"`
+	BaselineOverlap *float64 `json:"baseline_overlap,omitempty"`
+}
+
+type Report struct {
+	PromptVersion string        `json:"prompt_version"`
+	Model         string        `json:"model"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Cases         []*CaseResult `json:"cases"`
+	Summary       Summary       `json:"summary"`
+}
+
+// Run reviews every case with the same redaction and prompt composition as the service does:
+// cases are redacted by settings of their projects, cases of unknown projects by builtin detectors.
+// If the baseline is passed, findings are compared with the baseline ones.
+func Run(llm service.OpenAIClient, cases []*Case, baseline *Report, projects []*ds.Project) (*Report, error) {
+	defaultRedactor, err := service.NewRedactor(ds.RedactionSettings{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init redactor")
+	}
+
+	redactors, err := projectRedactors(projects)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[string]*CaseResult)
+	if baseline != nil {
+		for _, result := range baseline.Cases {
+			previous[result.Name] = result
+		}
+	}
+
+	report := &Report{
+		PromptVersion: llm.PromptVersion(),
+		Model:         llm.Model(),
+		CreatedAt:     time.Now(),
+		Cases:         make([]*CaseResult, 0, len(cases)),
+	}
+
+	overlapSum, overlapCount := 0.0, 0
+
+	for _, c := range cases {
+		diffs := c.diffs()
+
+		redactor, ok := redactors[c.ProjectID]
+		if !ok {
+			redactor = defaultRedactor
+		}
+
+		if redactor != nil {
+			diffs, _ = service.RedactDiffs(redactor, diffs)
+		}
+
+		message := service.ComposeMessageForAI(c.Title, c.Description, diffs, c.contexts()...)
+
+		result := &CaseResult{Name: c.Name}
+
+		result.Output, err = llm.GenerateAICodeReviewComment(message)
+		if err != nil {
+			result.Error = err.Error()
+			report.Summary.Failed++
+		}
+
+		result.Findings = ParseFindings(result.Output)
+		result.Valid = err == nil && ValidFormat(result.Output)
+		result.ExpectedRecall = ExpectedRecall(result.Findings, c.Expected)
+
+		if prev, ok := previous[c.Name]; ok {
+			overlap := Overlap(result.Findings, prev.Findings)
+			result.BaselineOverlap = &overlap
+			overlapSum += overlap
+			overlapCount++
+		}
+
+		if result.Valid {
+			report.Summary.Valid++
+		}
+
+		report.Summary.Findings += len(result.Findings)
+		report.Summary.ExpectedRecall += result.ExpectedRecall
+		report.Cases = append(report.Cases, result)
+	}
+
+	report.Summary.Cases = len(cases)
+	if len(cases) > 0 {
+		report.Summary.ExpectedRecall /= float64(len(cases))
+	}
+
+	if overlapCount > 0 {
+		avg := overlapSum / float64(overlapCount)
+		report.Summary.BaselineOverlap = &avg
+	}
+
+	return report, nil
+}
+
+// projectRedactors compiles redaction settings of the projects, projects with disabled redaction have nil redactors
+func projectRedactors(projects []*ds.Project) (map[int]*redact.Redactor, error) {
+	redactors := make(map[int]*redact.Redactor, len(projects))
+
+	for _, project := range projects {
+		if project.Redaction.Disabled {
+			redactors[project.ID] = nil
+			continue
+		}
+
+		redactor, err := service.NewRedactor(project.Redaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redaction settings of project %s", project.Name)
+		}
+
+		redactors[project.ID] = redactor
+	}
+
+	return redactors, nil
+}
+
+// LoadReport reads the report saved by an earlier run
+func LoadReport(path string) (*Report, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read report")
+	}
+
+	report := &Report{}
+
+	err = json.Unmarshal(raw, report)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode report")
+	}
+
+	return report, nil
+}
+
+func (r *Report) Save(path string) error {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode report")
+	}
+
+	err = os.WriteFile(path, raw, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+
+	return nil
+}
+
+// Print writes human-readable summary of the report
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "prompt version: %s, model: %s\n\n", r.PromptVersion, r.Model)
+	fmt.Fprintf(w, "%-40s %8s %6s %8s %8s\n", "case", "findings", "valid", "recall", "overlap")
+
+	for _, c := range r.Cases {
+		fmt.Fprintf(w, "%-40s %8d %6t %8.2f %8s\n",
+			c.Name, len(c.Findings), c.Valid, c.ExpectedRecall, percent(c.BaselineOverlap))
+	}
+
+	fmt.Fprintf(w, "\ncases: %d, failed: %d, valid: %d, findings: %d, recall: %.2f, overlap: %s\n",
+		r.Summary.Cases, r.Summary.Failed, r.Summary.Valid, r.Summary.Findings,
+		r.Summary.ExpectedRecall, percent(r.Summary.BaselineOverlap))
+}
+
+func percent(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f", *v)
+}
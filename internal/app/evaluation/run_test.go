@@ -0,0 +1,125 @@
+package evaluation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRun(t *testing.T) {
+	cases, err := LoadCorpus("testdata/corpus")
+	require.NoError(t, err)
+	require.Len(t, cases, 3)
+
+	report, err := Run(FakeLLM{Version: "1"}, cases, nil, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "1", report.PromptVersion)
+	require.Equal(t, "fake", report.Model)
+	require.Equal(t, Summary{Cases: 3, Valid: 3, Findings: 3, ExpectedRecall: 1}, report.Summary)
+
+	byName := make(map[string]*CaseResult)
+	for _, c := range report.Cases {
+		byName[c.Name] = c
+	}
+
+	require.Equal(t, []string{
+		"TODO without a ticket reference: `// TODO: make it configurable`",
+		"fmt.Println in production code, use the logger: `fmt.Println(\"hello\")`",
+	}, byName["todo-and-println"].Findings)
+	require.Equal(t, []string{
+		"likely secret is committed: `GITLAB_TOKEN=\"[REDACTED:gitlab_token]\"`",
+	}, byName["committed-secret"].Findings, "secrets must be masked before the LLM")
+	require.Empty(t, byName["clean-change"].Findings)
+
+	t.Run("compare with baseline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, report.Save(path))
+
+		baseline, err := LoadReport(path)
+		require.NoError(t, err)
+
+		baseline.Cases[0].Findings = nil // clean-change had no findings before as well
+
+		next, err := Run(FakeLLM{Version: "2"}, cases, baseline, nil)
+		require.NoError(t, err)
+		require.NotNil(t, next.Summary.BaselineOverlap)
+		require.Equal(t, 1.0, *next.Summary.BaselineOverlap)
+	})
+}
+
+func TestRun_ProjectRedaction(t *testing.T) {
+	t.Parallel()
+
+	diffs := []CaseDiff{{OldPath: "main.go", NewPath: "main.go", Content: "@@ -1 +1 @@\n+host := \"billing.internal\"\n"}}
+
+	cases := []*Case{
+		{Name: "custom-pattern", ProjectID: 1, Diffs: diffs},
+		{Name: "disabled", ProjectID: 2, Diffs: diffs},
+		{Name: "unknown-project", ProjectID: 3, Diffs: diffs},
+	}
+
+	projects := []*ds.Project{
+		{ID: 1, Name: "billing", Redaction: ds.RedactionSettings{
+			Patterns: []ds.RedactionPattern{{Name: "internal_host", Regex: `[a-z]+\.internal`}},
+		}},
+		{ID: 2, Name: "docs", Redaction: ds.RedactionSettings{Disabled: true}},
+	}
+
+	report, err := Run(FakeLLM{Version: "1"}, cases, nil, projects)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"likely secret is committed: `host := \"[REDACTED:internal_host]\"`"}, report.Cases[0].Findings,
+		"patterns of the project are applied")
+	require.Empty(t, report.Cases[1].Findings, "redaction is disabled for the project")
+	require.Empty(t, report.Cases[2].Findings, "builtin detectors only")
+
+	_, err = Run(FakeLLM{Version: "1"}, cases, nil, []*ds.Project{
+		{ID: 1, Name: "billing", Redaction: ds.RedactionSettings{Patterns: []ds.RedactionPattern{{Name: "bad", Regex: "("}}}},
+	})
+	require.Error(t, err)
+}
+
+func TestOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		current  []string
+		previous []string
+		want     float64
+	}{
+		{name: "both empty", want: 1},
+		{name: "nothing before", current: []string{"a"}, want: 0},
+		{
+			name:     "same findings, other wording",
+			current:  []string{"Rename variable x to count", "Handle the error"},
+			previous: []string{"Variable x should be renamed to count", "Error of Close is not handled"},
+			want:     0.5,
+		},
+		{
+			name:     "one finding is lost",
+			current:  []string{"handle the error of Close"},
+			previous: []string{"handle the error of Close", "log the error"},
+			want:     0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.want, Overlap(tt.current, tt.previous), 0.001)
+		})
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, ValidFormat("1. Rename x\n2. Handle error"))
+	require.True(t, ValidFormat("LGTM, no issues found."))
+	require.False(t, ValidFormat(""))
+	require.False(t, ValidFormat("The change adds a greeting."))
+}
@@ -0,0 +1,160 @@
+// Package actions does actions of teams when merge requests become approved by policies.
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type GitlabClient interface {
+	// UpdateMergeRequestLabels adds and removes labels of the merge request
+	UpdateMergeRequestLabels(projectID int, iid int, add []string, remove []string) error
+	// CommentMergeRequest adds a note to the merge request
+	CommentMergeRequest(mr *ds.MergeRequest, comment string) error
+	// MergeWhenPipelineSucceeds sets auto-merge of the revision
+	MergeWhenPipelineSucceeds(mr *ds.MergeRequest) error
+}
+
+type SlackClient interface {
+	SendMessage(recipientID string, message string) error
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Runner struct {
+	g     GitlabClient
+	slack SlackClient
+	http  HTTPClient
+}
+
+func New(g GitlabClient, slack SlackClient, http HTTPClient) *Runner {
+	return &Runner{
+		g:     g,
+		slack: slack,
+		http:  http,
+	}
+}
+
+// OnApproved does actions once per transition of the merge request to approved, done actions are kept in rec,
+// so failed actions are retried without repeating the done ones
+func (r *Runner) OnApproved(team *ds.Team, mr *ds.MergeRequest, approved bool, actions []ds.Action, rec *ds.ActionsRecord) error {
+	// the merge request is approved when policies of all involved teams approve it
+	rec.Update(approved && !mr.WaitsForTeams(team.ID))
+	if !rec.Approved {
+		return nil
+	}
+
+	for i, action := range actions {
+		key := ds.ActionKey(i, action)
+		if lo.Contains(rec.Done, key) {
+			continue
+		}
+
+		err := r.do(team, mr, action, fmt.Sprintf("%d-%d-%s", mr.ID, rec.Transitions, key))
+		if err != nil {
+			return errors.Wrapf(err, "action %s failed", action.Type)
+		}
+
+		rec.Done = append(rec.Done, key)
+	}
+
+	return nil
+}
+
+func (r *Runner) do(team *ds.Team, mr *ds.MergeRequest, action ds.Action, idempotencyKey string) error {
+	switch action.Type {
+	case ds.ActionAddLabels:
+		return r.g.UpdateMergeRequestLabels(mr.ProjectID, mr.IID, action.Labels, nil)
+	case ds.ActionRemoveLabels:
+		return r.g.UpdateMergeRequestLabels(mr.ProjectID, mr.IID, nil, action.Labels)
+	case ds.ActionComment:
+		return r.g.CommentMergeRequest(mr, action.Comment)
+	case ds.ActionMergeWhenPipelineSucceeds:
+		return r.g.MergeWhenPipelineSucceeds(mr)
+	case ds.ActionNotifyAuthor:
+		return r.notifyAuthor(team, mr, action)
+	case ds.ActionWebhook:
+		return r.webhook(team, mr, action, idempotencyKey)
+	default:
+		return errors.Errorf("unknown action type %q", action.Type)
+	}
+}
+
+func (r *Runner) notifyAuthor(team *ds.Team, mr *ds.MergeRequest, action ds.Action) error {
+	author, ok := lo.Find(team.Members, func(member *ds.User) bool { return member.GitLabID == mr.Author.GitLabID })
+	if !ok || author.SlackID == "" {
+		log.Warn().Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("author without Slack ID is not notified")
+		return nil
+	}
+
+	msg := action.Message
+	if msg == "" {
+		msg = fmt.Sprintf(":white_check_mark: <%s|%s> is approved.", mr.URL, mr.Title)
+	}
+
+	return r.slack.SendMessage(author.SlackID, msg)
+}
+
+// WebhookPayload is posted by the webhook action
+type WebhookPayload struct {
+	Event     string `json:"event"`
+	Team      string `json:"team"`
+	ProjectID int    `json:"project_id"`
+	IID       int    `json:"iid"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	SHA       string `json:"sha"`
+}
+
+// webhook posts WebhookPayload, receivers may drop repeated deliveries by the Idempotency-Key header
+func (r *Runner) webhook(team *ds.Team, mr *ds.MergeRequest, action ds.Action, idempotencyKey string) error {
+	body, err := json.Marshal(WebhookPayload{
+		Event:     "approved",
+		Team:      team.Name,
+		ProjectID: mr.ProjectID,
+		IID:       mr.IID,
+		Title:     mr.Title,
+		URL:       mr.URL,
+		SHA:       mr.SHA,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal webhook payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
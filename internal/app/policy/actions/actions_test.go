@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type fakeGitlab struct {
+	added    []string
+	comments []string
+	merged   int
+	fail     bool
+}
+
+func (f *fakeGitlab) UpdateMergeRequestLabels(_ int, _ int, add []string, _ []string) error {
+	f.added = append(f.added, add...)
+	return nil
+}
+
+func (f *fakeGitlab) CommentMergeRequest(_ *ds.MergeRequest, comment string) error {
+	if f.fail {
+		return errors.New("gitlab is down")
+	}
+
+	f.comments = append(f.comments, comment)
+	return nil
+}
+
+func (f *fakeGitlab) MergeWhenPipelineSucceeds(*ds.MergeRequest) error {
+	f.merged++
+	return nil
+}
+
+type fakeSlack struct {
+	messages map[string][]string
+}
+
+func (f *fakeSlack) SendMessage(recipientID string, message string) error {
+	if f.messages == nil {
+		f.messages = map[string][]string{}
+	}
+
+	f.messages[recipientID] = append(f.messages[recipientID], message)
+	return nil
+}
+
+func testTeam() *ds.Team {
+	return &ds.Team{
+		ID:   "backend",
+		Name: "backend",
+		Members: []*ds.User{
+			{BasicUser: &ds.BasicUser{GitLabID: 1}, SlackID: "U1"},
+			{BasicUser: &ds.BasicUser{GitLabID: 2}},
+		},
+	}
+}
+
+func TestRunner_OnApproved(t *testing.T) {
+	t.Parallel()
+
+	gitlab := &fakeGitlab{}
+	r := New(gitlab, nil, nil)
+
+	mr := &ds.MergeRequest{ID: 7, Author: &ds.BasicUser{GitLabID: 1}}
+	actions := []ds.Action{
+		{Type: ds.ActionAddLabels, Labels: []string{"approved"}},
+		{Type: ds.ActionComment, Comment: "ready to merge"},
+		{Type: ds.ActionMergeWhenPipelineSucceeds},
+	}
+	rec := &ds.ActionsRecord{}
+
+	require.NoError(t, r.OnApproved(testTeam(), mr, false, actions, rec))
+	require.Empty(t, gitlab.comments, "not approved")
+
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Equal(t, []string{"approved"}, gitlab.added)
+	require.Equal(t, []string{"ready to merge"}, gitlab.comments)
+	require.Equal(t, 1, gitlab.merged)
+	require.Equal(t, 1, rec.Transitions)
+
+	// approve is lost and given again
+	require.NoError(t, r.OnApproved(testTeam(), mr, false, actions, rec))
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Equal(t, []string{"ready to merge", "ready to merge"}, gitlab.comments)
+	require.Equal(t, 2, gitlab.merged)
+	require.Equal(t, 2, rec.Transitions)
+}
+
+func TestRunner_OnApprovedRetry(t *testing.T) {
+	t.Parallel()
+
+	gitlab := &fakeGitlab{fail: true}
+	r := New(gitlab, nil, nil)
+
+	mr := &ds.MergeRequest{ID: 7, Author: &ds.BasicUser{GitLabID: 1}}
+	actions := []ds.Action{
+		{Type: ds.ActionAddLabels, Labels: []string{"approved"}},
+		{Type: ds.ActionComment, Comment: "ready to merge"},
+	}
+	rec := &ds.ActionsRecord{}
+
+	require.Error(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Equal(t, []string{"0:add_labels"}, rec.Done)
+
+	gitlab.fail = false
+
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Equal(t, []string{"approved"}, gitlab.added, "done actions are not repeated")
+	require.Equal(t, []string{"ready to merge"}, gitlab.comments, "failed actions are retried")
+	require.Equal(t, 1, rec.Transitions)
+}
+
+func TestRunner_OnApprovedPendingTeams(t *testing.T) {
+	t.Parallel()
+
+	gitlab := &fakeGitlab{}
+	r := New(gitlab, nil, nil)
+
+	mr := &ds.MergeRequest{ID: 7, Author: &ds.BasicUser{GitLabID: 1}, PendingTeams: []string{"backend", "platform"}}
+	actions := []ds.Action{{Type: ds.ActionComment, Comment: "ready to merge"}}
+	rec := &ds.ActionsRecord{}
+
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Empty(t, gitlab.comments, "waits for the platform team")
+
+	mr.PendingTeams = []string{"backend"}
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.Equal(t, []string{"ready to merge"}, gitlab.comments)
+}
+
+func TestRunner_NotifyAuthor(t *testing.T) {
+	t.Parallel()
+
+	slack := &fakeSlack{}
+	r := New(&fakeGitlab{}, slack, nil)
+
+	actions := []ds.Action{{Type: ds.ActionNotifyAuthor, Message: "approved!"}}
+
+	mr := &ds.MergeRequest{ID: 7, Author: &ds.BasicUser{GitLabID: 1}}
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, &ds.ActionsRecord{}))
+	require.Equal(t, []string{"approved!"}, slack.messages["U1"])
+
+	// author without Slack ID is skipped
+	mr = &ds.MergeRequest{ID: 8, Author: &ds.BasicUser{GitLabID: 2}}
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, &ds.ActionsRecord{}))
+	require.Len(t, slack.messages, 1)
+}
+
+func TestRunner_Webhook(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys     []string
+		payloads []WebhookPayload
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		payload := WebhookPayload{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+
+		keys = append(keys, req.Header.Get("Idempotency-Key"))
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	r := New(&fakeGitlab{}, nil, srv.Client())
+
+	mr := &ds.MergeRequest{ID: 7, ProjectID: 3, IID: 12, SHA: "abc", Author: &ds.BasicUser{GitLabID: 1}}
+	actions := []ds.Action{{Type: ds.ActionWebhook, URL: srv.URL}}
+	rec := &ds.ActionsRecord{}
+
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+	require.NoError(t, r.OnApproved(testTeam(), mr, false, actions, rec))
+	require.NoError(t, r.OnApproved(testTeam(), mr, true, actions, rec))
+
+	require.Equal(t, []string{"7-1-0:webhook", "7-2-0:webhook"}, keys)
+	require.Equal(t, WebhookPayload{
+		Event: "approved", Team: "backend", ProjectID: 3, IID: 12, SHA: "abc",
+	}, payloads[0])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	actions = []ds.Action{{Type: ds.ActionWebhook, URL: failing.URL}}
+	require.Error(t, r.OnApproved(testTeam(), mr, true, actions, &ds.ActionsRecord{}))
+}
@@ -0,0 +1,150 @@
+package codeowners
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/codeowners"
+)
+
+const (
+	// cacheSize limits cached merge requests and owners, the cache is dropped when exceeded
+	cacheSize = 1000
+	// ownersTTL is how long resolved users and groups are reused
+	ownersTTL = time.Hour
+)
+
+type resolved struct {
+	users []int
+	at    time.Time
+}
+
+type GitlabClient interface {
+	// GetRawFile returns content of the file at the ref, nil if the file does not exist
+	GetRawFile(projectID int, path string, ref string) ([]byte, error)
+	// MergeRequestChangedPaths returns new and old paths of all changed files of the merge request
+	MergeRequestChangedPaths(projectID int, iid int) ([]string, error)
+	// CodeOwnerUsers resolves the owner (@username, @group or email) into GitLab IDs of users
+	CodeOwnerUsers(owner string) ([]int, error)
+}
+
+// Resolver finds code owners of merge requests by CODEOWNERS of the target branch
+type Resolver struct {
+	g GitlabClient
+
+	mu     sync.Mutex
+	byMR   map[string][]int
+	owners map[string]resolved
+}
+
+func New(g GitlabClient) *Resolver {
+	return &Resolver{
+		g:      g,
+		byMR:   make(map[string][]int),
+		owners: make(map[string]resolved),
+	}
+}
+
+// Owners returns GitLab IDs of owners of the changed files, empty if the project has no CODEOWNERS
+func (r *Resolver) Owners(mr *ds.MergeRequest) ([]int, error) {
+	key := fmt.Sprintf("%d:%s", mr.ID, mr.SHA)
+
+	r.mu.Lock()
+	owners, ok := r.byMR[key]
+	r.mu.Unlock()
+
+	if ok {
+		return owners, nil
+	}
+
+	file, err := r.file(mr)
+	if err != nil {
+		return nil, err
+	}
+
+	owners = make([]int, 0)
+
+	if file != nil {
+		paths, err := r.g.MergeRequestChangedPaths(mr.ProjectID, mr.IID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get changed paths")
+		}
+
+		refs := make([]string, 0)
+		for _, path := range paths {
+			refs = append(refs, file.Owners(path)...)
+		}
+
+		for _, ref := range lo.Uniq(refs) {
+			users, err := r.users(ref)
+			if err != nil {
+				return nil, err
+			}
+
+			owners = append(owners, users...)
+		}
+
+		owners = lo.Uniq(owners)
+	}
+
+	r.mu.Lock()
+	if len(r.byMR) >= cacheSize {
+		r.byMR = make(map[string][]int)
+	}
+	r.byMR[key] = owners
+	r.mu.Unlock()
+
+	return owners, nil
+}
+
+// file returns the first found CODEOWNERS of the target branch, nil if there is none
+func (r *Resolver) file(mr *ds.MergeRequest) (*codeowners.File, error) {
+	for _, location := range codeowners.Locations {
+		content, err := r.g.GetRawFile(mr.ProjectID, location, mr.TargetBranch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s", location)
+		}
+
+		if content == nil {
+			continue
+		}
+
+		file, err := codeowners.Parse(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", location)
+		}
+
+		return file, nil
+	}
+
+	return nil, nil
+}
+
+func (r *Resolver) users(owner string) ([]int, error) {
+	r.mu.Lock()
+	cached, ok := r.owners[owner]
+	r.mu.Unlock()
+
+	if ok && time.Since(cached.at) < ownersTTL {
+		return cached.users, nil
+	}
+
+	users, err := r.g.CodeOwnerUsers(owner)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve owner %s", owner)
+	}
+
+	r.mu.Lock()
+	if len(r.owners) >= cacheSize {
+		r.owners = make(map[string]resolved)
+	}
+	r.owners[owner] = resolved{users: users, at: time.Now()}
+	r.mu.Unlock()
+
+	return users, nil
+}
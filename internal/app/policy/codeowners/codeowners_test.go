@@ -0,0 +1,73 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type fakeGitlab struct {
+	files    map[string]string
+	paths    []string
+	users    map[string][]int
+	resolved int
+}
+
+func (f *fakeGitlab) GetRawFile(_ int, path string, ref string) ([]byte, error) {
+	content, ok := f.files[ref+":"+path]
+	if !ok {
+		return nil, nil
+	}
+
+	return []byte(content), nil
+}
+
+func (f *fakeGitlab) MergeRequestChangedPaths(int, int) ([]string, error) {
+	return f.paths, nil
+}
+
+func (f *fakeGitlab) CodeOwnerUsers(owner string) ([]int, error) {
+	f.resolved++
+	return f.users[owner], nil
+}
+
+func TestResolver_Owners(t *testing.T) {
+	t.Parallel()
+
+	g := &fakeGitlab{
+		files: map[string]string{
+			"master:.gitlab/CODEOWNERS": "*.go @alice @backend\n/docs/ @bob\n",
+			"develop:CODEOWNERS":        "* @carol\n",
+		},
+		paths: []string{"cmd/main.go", "internal/app/app.go", "docs/README.md"},
+		users: map[string][]int{
+			"@alice":   {1},
+			"@backend": {1, 2, 3},
+			"@bob":     {4},
+		},
+	}
+
+	r := New(g)
+	mr := &ds.MergeRequest{ID: 1, SHA: "abc", TargetBranch: "master"}
+
+	owners, err := r.Owners(mr)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3, 4}, owners)
+	require.Equal(t, 3, g.resolved)
+
+	// cached by the revision
+	_, err = r.Owners(mr)
+	require.NoError(t, err)
+	require.Equal(t, 3, g.resolved)
+
+	// CODEOWNERS of the target branch
+	owners, err = r.Owners(&ds.MergeRequest{ID: 2, SHA: "abc", TargetBranch: "develop"})
+	require.NoError(t, err)
+	require.Empty(t, owners, "@carol is unknown")
+
+	owners, err = r.Owners(&ds.MergeRequest{ID: 3, SHA: "abc", TargetBranch: "release"})
+	require.NoError(t, err)
+	require.Empty(t, owners, "no CODEOWNERS")
+}
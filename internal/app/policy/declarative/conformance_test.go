@@ -0,0 +1,13 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	policytest.Conformance(t, team(t, validSettings), newPolicy)
+}
@@ -0,0 +1,293 @@
+package declarative
+
+/**
+
+Policy:					Declarative
+Reviewers Rotation:		random pick from each pool of the team settings
+Final Approve:			required approvals from each pool of the team settings
+
+*/
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"github.com/zyedidia/generic/set"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+const PolicyName ds.PolicyName = "declarative"
+
+type Repository interface {
+	// PolicyMetadata returns policy metadata for the given merge request
+	PolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName) (bson.Raw, error)
+	// UpdatePolicyMetadata updates policy metadata for the given merge request
+	UpdatePolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName, d bson.Raw) error
+}
+
+type GitlabClient interface {
+	// SetReviewers overwrites reviewers list for the merge request
+	SetReviewers(mr *ds.MergeRequest, reviewers []int) error
+}
+
+type ActionRunner interface {
+	// OnApproved does actions once per transition of the merge request to approved
+	OnApproved(team *ds.Team, mr *ds.MergeRequest, approved bool, actions []ds.Action, rec *ds.ActionsRecord) error
+}
+
+type Policy struct {
+	r Repository
+	g GitlabClient
+	s selection.Strategy
+	a ActionRunner
+}
+
+func New(r Repository, g GitlabClient, s selection.Strategy, a ActionRunner) *Policy {
+	return &Policy{
+		r: r,
+		g: g,
+		s: s,
+		a: a,
+	}
+}
+
+type metadata struct {
+	ApprovedByPolicy  bool  `bson:"approved_by_policy"`
+	ReviewersSet      bool  `bson:"reviewers_set"`
+	ReviewersByPolicy []int `bson:"reviewers_by_policy"`
+	// Actions are on_approved actions done since the last transition to approved
+	Actions ds.ActionsRecord `bson:"actions"`
+	// ActionsDone is replaced by Actions, it is kept to not repeat actions of merge requests approved before
+	ActionsDone bool `bson:"actions_done,omitempty"`
+}
+
+// ValidateSettings checks policy settings of the team when teams are loaded
+func (p *Policy) ValidateSettings(team *ds.Team) error {
+	_, err := DecodeSettings(team)
+
+	return err
+}
+
+func (p *Policy) settings(team *ds.Team) (*Settings, bool) {
+	s, err := DecodeSettings(team)
+	if err != nil {
+		log.Error().Err(err).Str("team", team.Name).Msg("invalid declarative policy settings")
+		return nil, false
+	}
+
+	return s, true
+}
+
+func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team, s *Settings) bool {
+	// belongs to the team or touches its paths
+	if !team.Involved(mr) {
+		return true
+	}
+
+	// skip closed, merged, locked
+	if !mr.State.Is(ds.StateOpened) {
+		return true
+	}
+
+	if mr.Draft && s.Skip.SkipDrafts() {
+		return true
+	}
+
+	if glob.MatchAny(s.Skip.SourceBranches, mr.SourceBranch) || glob.MatchAny(s.Skip.TargetBranches, mr.TargetBranch) {
+		return true
+	}
+
+	for _, label := range s.Skip.Labels {
+		if mr.HasLabel(label) {
+			return true
+		}
+	}
+
+	return lo.Contains(s.Skip.Authors, mr.Author.GitLabID)
+}
+
+func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error) {
+	s, ok := p.settings(team)
+	if !ok || p.skip(mr, team, s) {
+		return nil
+	}
+
+	// load metadata
+	md := metadata{}
+
+	raw, err := p.r.PolicyMetadata(mr, team, PolicyName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get policy metadata")
+	}
+
+	if raw != nil {
+		err = bson.Unmarshal(raw, &md)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal policy metadata")
+		}
+	}
+
+	// save metadata
+	defer func() {
+		raw, saveErr := bson.Marshal(md)
+		if saveErr == nil {
+			saveErr = p.r.UpdatePolicyMetadata(mr, team, PolicyName, raw)
+		}
+
+		// the error of processing is more important
+		if err == nil && saveErr != nil {
+			err = errors.Wrap(saveErr, "failed to save policy metadata")
+		}
+	}()
+
+	// weren't set before
+	if md.ReviewersSet {
+		// check if approved by policy
+		md.ApprovedByPolicy = p.approved(team, mr, s)
+
+		if md.ActionsDone {
+			md.ActionsDone = false
+			md.Actions = ds.ActionsRecord{Approved: true, Transitions: 1, Done: doneKeys(s.actions(team))}
+		}
+
+		err = p.a.OnApproved(team, mr, md.ApprovedByPolicy, s.actions(team), &md.Actions)
+		if err != nil {
+			return errors.Wrap(err, "failed to do actions on approve")
+		}
+
+		return nil
+	}
+
+	// then set reviewers
+	err = p.setReviewers(team, mr, s, &md)
+	if err != nil {
+		return errors.Wrap(err, "failed to set reviewers")
+	}
+
+	return nil
+}
+
+func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, s *Settings, md *metadata) error {
+	md.ReviewersSet = true
+
+	reviewersSet := set.NewMapset[int]()
+	for _, reviewer := range mr.Reviewers {
+		reviewersSet.Put(reviewer.GitLabID)
+	}
+
+	for _, pool := range s.Pools {
+		poolSet := set.NewMapset[int]()
+		for _, user := range pool.members(team) {
+			// without author
+			if user.GitLabID != mr.Author.GitLabID {
+				poolSet.Put(user.GitLabID)
+			}
+		}
+
+		// pool members who are reviewers
+		inner := reviewersSet.Intersection(poolSet)
+
+		// count of pool members which needed to set as reviewers
+		need := pool.Pick - inner.Size()
+
+		// pool members who are not reviewers
+		picked, err := p.s.Pick(team, pool.Name, mr, poolSet.Difference(reviewersSet).Keys(), need)
+		if err != nil {
+			md.ReviewersSet = false
+			return errors.Wrapf(err, "failed to pick reviewers of pool %s", pool.Name)
+		}
+
+		for _, user := range picked {
+			md.ReviewersByPolicy = append(md.ReviewersByPolicy, user)
+
+			reviewersSet.Put(user)
+		}
+	}
+
+	err := p.g.SetReviewers(mr, reviewersSet.Keys())
+	if err != nil {
+		md.ReviewersSet = false
+		return errors.Wrap(err, "failed to set reviewers")
+	}
+
+	return nil
+}
+
+// doneKeys are keys of all actions in ds.ActionsRecord
+func doneKeys(actions []ds.Action) []string {
+	return lo.Map(actions, func(action ds.Action, i int) string {
+		return ds.ActionKey(i, action)
+	})
+}
+
+func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds.BasicUser) bool {
+	// with invalid settings only approves of the users are checked
+	s, ok := p.settings(team)
+	if ok && p.skip(mr, team, s) {
+		// true means the MR meet "need approve" state yet
+		// or closed, merged, locked
+		return true
+	}
+
+	if len(byAll) == 0 {
+		return false
+	}
+
+	allNeeded := set.NewMapset[int]()
+	for _, user := range byAll {
+		allNeeded.Put(user.GitLabID)
+	}
+
+	approvesSet := set.NewMapset[int]()
+	for _, approve := range mr.Approves {
+		approvesSet.Put(approve.GitLabID)
+	}
+
+	return allNeeded.Difference(approvesSet).Size() == 0 // all passed users approved the merge request
+}
+
+func (p *Policy) ApprovedByPolicy(team *ds.Team, mr *ds.MergeRequest) bool {
+	s, ok := p.settings(team)
+	if !ok {
+		// a misconfigured team must not approve merge requests and do actions silently, the error is logged
+		return false
+	}
+
+	if p.skip(mr, team, s) {
+		// true means the MR meet "need approve" state yet
+		// or closed, merged, locked
+		return true
+	}
+
+	return p.approved(team, mr, s)
+}
+
+// approved checks required approvals of every pool, the author's approval is not counted
+func (p *Policy) approved(team *ds.Team, mr *ds.MergeRequest, s *Settings) bool {
+	approves := set.NewMapset[int]()
+	for _, user := range mr.Approves {
+		if user.GitLabID != mr.Author.GitLabID {
+			approves.Put(user.GitLabID)
+		}
+	}
+
+	for _, pool := range s.Pools {
+		left := pool.Approvals
+
+		for _, user := range pool.members(team) {
+			if approves.Has(user.GitLabID) {
+				left--
+			}
+		}
+
+		if left > 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,176 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+func team(t *testing.T, settings bson.M) *ds.Team {
+	return policytest.WithSettings(t, policytest.Team("backend", PolicyName,
+		policytest.Developer(1), policytest.Developer(2), policytest.Developer(3), policytest.Lead(10)), settings)
+}
+
+func newPolicy(k *policytest.Kit) service.Policy {
+	return New(k.Repository, k.Gitlab, k.Strategy, k.Runner)
+}
+
+var validSettings = bson.M{
+	"pools": bson.A{
+		bson.M{"name": "devs", "labels": bson.A{"developer"}, "pick": 2, "approvals": 1},
+		bson.M{"name": "leads", "labels": bson.A{"lead"}, "pick": 1, "approvals": 1},
+	},
+	"skip": bson.M{
+		"source_branches": bson.A{"release/**"},
+		"labels":          bson.A{"no-review"},
+	},
+	"on_approved": bson.A{
+		bson.M{"type": "add_labels", "labels": bson.A{"approved"}},
+		bson.M{"type": "comment", "comment": "ready to merge"},
+	},
+}
+
+func TestDecodeSettings(t *testing.T) {
+	t.Parallel()
+
+	s, err := DecodeSettings(team(t, validSettings))
+	require.NoError(t, err)
+	require.Len(t, s.Pools, 2)
+	require.True(t, s.Skip.SkipDrafts())
+
+	invalid := map[string]bson.M{
+		"no pools":        {"pools": bson.A{}},
+		"unknown key":     {"pools": bson.A{bson.M{"name": "devs", "labels": bson.A{"developer"}, "approves": 1}}},
+		"no approvals":    {"pools": bson.A{bson.M{"name": "devs", "labels": bson.A{"developer"}, "pick": 2}}},
+		"too few members": {"pools": bson.A{bson.M{"name": "leads", "labels": bson.A{"lead"}, "approvals": 2}}},
+		"duplicated pool": {"pools": bson.A{
+			bson.M{"name": "devs", "labels": bson.A{"developer"}, "approvals": 1},
+			bson.M{"name": "devs", "labels": bson.A{"lead"}},
+		}},
+		"unknown action": {
+			"pools":       bson.A{bson.M{"name": "devs", "labels": bson.A{"developer"}, "approvals": 1}},
+			"on_approved": bson.A{bson.M{"type": "merge"}},
+		},
+	}
+
+	for name, settings := range invalid {
+		_, err = DecodeSettings(team(t, settings))
+		require.Error(t, err, name)
+	}
+
+	_, err = DecodeSettings(&ds.Team{Name: "empty"})
+	require.Error(t, err, "settings are required")
+}
+
+func TestPolicy_skip(t *testing.T) {
+	t.Parallel()
+
+	tm := team(t, validSettings)
+	s, err := DecodeSettings(tm)
+	require.NoError(t, err)
+
+	p := newPolicy(policytest.NewKit()).(*Policy)
+
+	mr := func(modify func(mr *ds.MergeRequest)) *ds.MergeRequest {
+		m := policytest.MergeRequest(1, 1)
+		modify(m)
+
+		return m
+	}
+
+	tests := []struct {
+		name string
+		mr   *ds.MergeRequest
+		want bool
+	}{
+		{name: "regular", mr: mr(func(*ds.MergeRequest) {}), want: false},
+		{name: "not a teammate", mr: mr(func(m *ds.MergeRequest) { m.Author = &ds.BasicUser{GitLabID: 100} }), want: true},
+		{name: "touches paths of the team", mr: mr(func(m *ds.MergeRequest) {
+			m.Author = &ds.BasicUser{GitLabID: 100}
+			m.InvolvedTeams = []string{"backend"}
+		}), want: false},
+		{name: "merged", mr: mr(func(m *ds.MergeRequest) { m.State = ds.StateMerged }), want: true},
+		{name: "draft", mr: mr(func(m *ds.MergeRequest) { m.Draft = true }), want: true},
+		{name: "release branch", mr: mr(func(m *ds.MergeRequest) { m.SourceBranch = "release/1.2" }), want: true},
+		{name: "skip label", mr: mr(func(m *ds.MergeRequest) { m.Labels = []string{"no-review"} }), want: true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, p.skip(tt.mr, tm, s), tt.name)
+	}
+}
+
+func TestPolicy_ProcessChanges(t *testing.T) {
+	t.Parallel()
+
+	mr := policytest.MergeRequest(1, 1)
+	mr.Reviewers = []*ds.BasicUser{{GitLabID: 2}}
+
+	s := policytest.NewScenario(t, team(t, validSettings), mr, newPolicy).Open()
+	require.Len(t, s.Reviewers(), 3, "2 devs and 1 lead")
+	require.Contains(t, s.Reviewers(), 2, "existing reviewer is kept")
+	require.Contains(t, s.Reviewers(), 10, "the lead is picked")
+	require.NotContains(t, s.Reviewers(), 1, "the author is not picked")
+
+	s.ApproveBy(1, 2)
+	require.False(t, s.Approved(), "the author's approve is not counted, no lead approve")
+	require.Empty(t, s.Kit.Gitlab.Comments)
+
+	s.ApproveBy(10)
+	require.True(t, s.Approved())
+	require.True(t, s.Policy.ApprovedByUser(s.Team, s.MR, &ds.BasicUser{GitLabID: 10}))
+	require.False(t, s.Policy.ApprovedByUser(s.Team, s.MR, &ds.BasicUser{GitLabID: 3}))
+
+	s.Process()
+	require.Equal(t, []string{"approved"}, s.Kit.Gitlab.AddedLabels, "actions are done once")
+	require.Equal(t, []string{"ready to merge"}, s.Kit.Gitlab.Comments, "actions are done once")
+
+	// new commits reset approves, the next approve is a new transition
+	s.Push("sha-2").ApproveBy(2, 10)
+	require.Equal(t, []string{"ready to merge", "ready to merge"}, s.Kit.Gitlab.Comments)
+}
+
+func TestPolicy_ProcessChangesActionsDone(t *testing.T) {
+	t.Parallel()
+
+	mr := policytest.MergeRequest(1, 1)
+	mr.Approves = []*ds.BasicUser{{GitLabID: 2}, {GitLabID: 10}}
+	mr.Reviewers = []*ds.BasicUser{{GitLabID: 2}, {GitLabID: 10}}
+
+	s := policytest.NewScenario(t, team(t, validSettings), mr, newPolicy)
+
+	// approved and processed before actions were recorded by transitions
+	raw, err := bson.Marshal(bson.M{"reviewers_set": true, "approved_by_policy": true, "actions_done": true})
+	require.NoError(t, err)
+	require.NoError(t, s.Kit.Repository.UpdatePolicyMetadata(s.MR, s.Team, PolicyName, raw))
+
+	s.Process()
+	require.Empty(t, s.Kit.Gitlab.Comments, "actions are not repeated")
+
+	raw, err = s.Kit.Repository.PolicyMetadata(s.MR, s.Team, PolicyName)
+	require.NoError(t, err)
+
+	md := metadata{}
+	require.NoError(t, bson.Unmarshal(raw, &md))
+	require.False(t, md.ActionsDone)
+	require.Equal(t, 1, md.Actions.Transitions)
+}
+
+func TestPolicy_InvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	tm := team(t, bson.M{"pools": bson.A{}})
+	p := newPolicy(policytest.NewKit())
+
+	mr := policytest.MergeRequest(1, 1)
+	mr.Approves = []*ds.BasicUser{{GitLabID: 2}}
+
+	require.False(t, p.ApprovedByPolicy(tm, mr), "misconfigured teams don't approve")
+	require.True(t, p.ApprovedByUser(tm, mr, &ds.BasicUser{GitLabID: 2}))
+	require.False(t, p.ApprovedByUser(tm, mr, &ds.BasicUser{GitLabID: 3}))
+}
@@ -0,0 +1,147 @@
+package declarative
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/settings"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// Settings is the policy_settings document of a team, e.g.:
+//
+//	pools:
+//	  - {name: devs, labels: [developer], pick: 2, approvals: 1}
+//	  - {name: leads, labels: [lead], pick: 1, approvals: 1}
+//	skip:
+//	  source_branches: ["release/**"]
+//	  labels: ["no-review"]
+//	on_approved:
+//	  - {type: add_labels, labels: ["approved"]}
+//	  - {type: comment, comment: "Approved by policy, ready to merge"}
+type Settings struct {
+	Pools []Pool `bson:"pools"`
+	Skip  Skip   `bson:"skip"`
+	// OnApproved overrides on_approved actions of the team
+	OnApproved []ds.Action `bson:"on_approved"`
+}
+
+// Pool is a group of teammates with any of the labels
+type Pool struct {
+	Name   string         `bson:"name"`
+	Labels []ds.UserLabel `bson:"labels"`
+	// Pick is the number of reviewers from the pool, existing reviewers of the pool are counted
+	Pick int `bson:"pick"`
+	// Approvals is the number of approvals from the pool required by the policy
+	Approvals int `bson:"approvals"`
+}
+
+// Skip conditions of merge requests the policy does nothing with
+type Skip struct {
+	// Drafts skips draft merge requests, true by default
+	Drafts *bool `bson:"drafts,omitempty"`
+	// SourceBranches and TargetBranches are globs (e.g. "release/**")
+	SourceBranches []string `bson:"source_branches"`
+	TargetBranches []string `bson:"target_branches"`
+	// Labels skip merge requests with any of them
+	Labels []string `bson:"labels"`
+	// Authors are GitLab IDs of users whose merge requests are skipped
+	Authors []int `bson:"authors"`
+}
+
+func (s Skip) SkipDrafts() bool {
+	return s.Drafts == nil || *s.Drafts
+}
+
+// DecodeSettings decodes and validates settings of the team
+func DecodeSettings(team *ds.Team) (*Settings, error) {
+	s := &Settings{}
+
+	if len(team.PolicySettings) == 0 {
+		return nil, errors.New("policy_settings are required")
+	}
+
+	err := settings.Decode(team.PolicySettings, s)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validate(team)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Settings) validate(team *ds.Team) error {
+	if len(s.Pools) == 0 {
+		return errors.New("at least one pool is required")
+	}
+
+	names := make(map[string]bool, len(s.Pools))
+	approvals := 0
+
+	for i, pool := range s.Pools {
+		if pool.Name == "" {
+			return errors.Errorf("pools[%d]: name is required", i)
+		}
+
+		if names[pool.Name] {
+			return errors.Errorf("pool %s: duplicated name", pool.Name)
+		}
+		names[pool.Name] = true
+
+		if len(pool.Labels) == 0 {
+			return errors.Errorf("pool %s: labels are required", pool.Name)
+		}
+
+		if pool.Pick < 0 || pool.Approvals < 0 {
+			return errors.Errorf("pool %s: pick and approvals must not be negative", pool.Name)
+		}
+
+		if members := len(pool.members(team)); members < pool.Approvals {
+			return errors.Errorf("pool %s: %d approvals required, but only %d members", pool.Name, pool.Approvals, members)
+		}
+
+		approvals += pool.Approvals
+	}
+
+	if approvals == 0 {
+		return errors.New("at least one approval is required")
+	}
+
+	for _, pattern := range append(append([]string{}, s.Skip.SourceBranches...), s.Skip.TargetBranches...) {
+		_, err := glob.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid branch pattern %q", pattern)
+		}
+	}
+
+	return ds.ValidateActions("on_approved", s.OnApproved)
+}
+
+// actions returns on_approved actions of the policy settings, or of the team
+func (s *Settings) actions(team *ds.Team) []ds.Action {
+	if len(s.OnApproved) > 0 {
+		return s.OnApproved
+	}
+
+	return team.OnApproved
+}
+
+// members returns teammates with any of the pool labels
+func (p Pool) members(team *ds.Team) []*ds.User {
+	members := make([]*ds.User, 0, len(team.Members))
+
+	for _, user := range team.Members {
+		for _, label := range p.Labels {
+			if user.Labels.Has(label) {
+				members = append(members, user)
+				break
+			}
+		}
+	}
+
+	return members
+}
@@ -0,0 +1,37 @@
+package developers_riot
+
+/**
+
+Policy:					Developers riot
+Reviewers Rotation:		random pick 2 developers from the team
+Final Approve:			1 developer approve
+
+It is Reinventing Democracy with fewer approves, so it's the same policy with other default settings.
+
+*/
+
+import (
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	rd "github.com/jokerlee/gitlab-review-bot/internal/app/policy/reinventing-democracy"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+)
+
+const (
+	PolicyName ds.PolicyName = "dr"
+	// RequiredDevelopersCount default number of developers to be picked as reviewers
+	RequiredDevelopersCount = 2
+	// RequiredApprovesCount default number of developer approves
+	RequiredApprovesCount = 1
+)
+
+func DefaultSettings() rd.Settings {
+	s := rd.DefaultSettings()
+	s.RequiredDevelopers = RequiredDevelopersCount
+	s.RequiredApproves = RequiredApprovesCount
+
+	return s
+}
+
+func New(r rd.Repository, g rd.GitlabClient, s selection.Strategy, a rd.ActionRunner) *rd.Policy {
+	return rd.NewVariant(PolicyName, DefaultSettings(), r, g, s, a)
+}
@@ -0,0 +1,112 @@
+package developers_riot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+func team() *ds.Team {
+	return policytest.Team("backend", PolicyName,
+		policytest.Developer(1), policytest.Developer(2), policytest.Developer(3), policytest.Lead(10))
+}
+
+func newPolicy(k *policytest.Kit) service.Policy {
+	return New(k.Repository, k.Gitlab, k.Strategy, k.Runner)
+}
+
+func TestPolicy_ValidateSettings(t *testing.T) {
+	t.Parallel()
+
+	p := newPolicy(policytest.NewKit()).(service.SettingsValidator)
+
+	require.NoError(t, p.ValidateSettings(team()))
+	require.NoError(t, p.ValidateSettings(policytest.WithSettings(t, team(), bson.M{"required_developers": 3, "required_approves": 2})))
+
+	invalid := map[string]bson.M{
+		"unknown key":                {"required_leads": 1},
+		"zero developers":            {"required_developers": 0},
+		"approves exceed developers": {"required_developers": 1, "required_approves": 2},
+	}
+
+	for name, settings := range invalid {
+		require.Error(t, p.ValidateSettings(policytest.WithSettings(t, team(), settings)), name)
+	}
+}
+
+func TestPolicy_ProcessChanges(t *testing.T) {
+	t.Parallel()
+
+	t.Run("picks two developers", func(t *testing.T) {
+		t.Parallel()
+
+		s := policytest.NewScenario(t, team(), policytest.MergeRequest(1, 1), newPolicy).Open()
+
+		require.Equal(t, []int{2, 3}, s.Reviewers())
+	})
+
+	t.Run("lead reviewer is not counted", func(t *testing.T) {
+		t.Parallel()
+
+		mr := policytest.MergeRequest(1, 1)
+		mr.Reviewers = []*ds.BasicUser{{GitLabID: 10}}
+
+		s := policytest.NewScenario(t, team(), mr, newPolicy).Open()
+
+		require.Equal(t, []int{2, 3, 10}, s.Reviewers())
+	})
+
+	t.Run("skips release branches", func(t *testing.T) {
+		t.Parallel()
+
+		for _, branch := range []string{"release/1.2", "team/release/1.2", "hotfix-release/1.2"} {
+			mr := policytest.MergeRequest(1, 1)
+			mr.SourceBranch = branch
+
+			s := policytest.NewScenario(t, team(), mr, newPolicy).Open()
+
+			require.Zero(t, s.Kit.Gitlab.Calls(), branch)
+			require.True(t, s.Approved(), branch)
+		}
+	})
+}
+
+func TestPolicy_ApprovedByPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings bson.M
+		approves []int
+		want     bool
+	}{
+		{name: "no approves", want: false},
+		{name: "developer approve", approves: []int{2}, want: true},
+		{name: "author approve is not counted", approves: []int{1}, want: false},
+		{name: "lead approve is not counted", approves: []int{10}, want: false},
+		{name: "not a teammate", approves: []int{100}, want: false},
+		{name: "two approves required", settings: bson.M{"required_approves": 2}, approves: []int{2}, want: false},
+		{name: "two approves", settings: bson.M{"required_approves": 2}, approves: []int{2, 3}, want: true},
+	}
+
+	for _, tt := range tests {
+		tm := team()
+		if tt.settings != nil {
+			policytest.WithSettings(t, tm, tt.settings)
+		}
+
+		s := policytest.NewScenario(t, tm, policytest.MergeRequest(1, 1), newPolicy).Open()
+		s.MR.Approves = nil
+
+		for _, id := range tt.approves {
+			s.MR.Approves = append(s.MR.Approves, &ds.BasicUser{GitLabID: id})
+		}
+
+		require.Equal(t, tt.want, s.Approved(), tt.name)
+	}
+}
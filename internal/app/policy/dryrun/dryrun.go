@@ -0,0 +1,99 @@
+// Package dryrun lets policies make their decisions without calling GitLab, decisions are stored and logged.
+package dryrun
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type Repository interface {
+	// PolicyMetadata returns policy metadata for the given merge request
+	PolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName) (bson.Raw, error)
+	// UpdatePolicyMetadata updates policy metadata for the given merge request
+	UpdatePolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName, d bson.Raw) error
+	AddDecision(decision *ds.Decision) error
+}
+
+// Recorder is the repository, the GitLab client and the action runner of a policy in dry-run mode.
+// Metadata is kept under the dry-run name of the policy, so the policy starts from scratch when dry-run is off.
+type Recorder struct {
+	r      Repository
+	policy ds.PolicyName
+	now    func() time.Time
+}
+
+func New(r Repository, policy ds.PolicyName) *Recorder {
+	return &Recorder{
+		r:      r,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+func (r *Recorder) PolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName) (bson.Raw, error) {
+	return r.r.PolicyMetadata(mr, team, policy.DryRun())
+}
+
+func (r *Recorder) UpdatePolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName, d bson.Raw) error {
+	return r.r.UpdatePolicyMetadata(mr, team, policy.DryRun(), d)
+}
+
+// SetReviewers records reviewers the policy would set
+func (r *Recorder) SetReviewers(mr *ds.MergeRequest, reviewers []int) error {
+	log.Info().
+		Str("policy", string(r.policy)).
+		Int("project_id", mr.ProjectID).
+		Int("iid", mr.IID).
+		Ints("reviewers", reviewers).
+		Msg("dry-run: reviewers would be set")
+
+	return r.record(mr, &ds.Decision{
+		Kind:      ds.DecisionReviewers,
+		Reviewers: reviewers,
+	})
+}
+
+// OnApproved records transitions to approved with actions the policy would do
+func (r *Recorder) OnApproved(team *ds.Team, mr *ds.MergeRequest, approved bool, actions []ds.Action, rec *ds.ActionsRecord) error {
+	if !rec.Update(approved && !mr.WaitsForTeams(team.ID)) {
+		return nil
+	}
+
+	// actions are recorded once per transition like done ones
+	rec.Done = lo.Map(actions, func(action ds.Action, i int) string { return ds.ActionKey(i, action) })
+
+	log.Info().
+		Str("policy", string(r.policy)).
+		Str("team", team.Name).
+		Int("project_id", mr.ProjectID).
+		Int("iid", mr.IID).
+		Strs("actions", lo.Map(actions, func(action ds.Action, _ int) string { return string(action.Type) })).
+		Msg("dry-run: merge request would be approved")
+
+	return r.record(mr, &ds.Decision{
+		Kind:    ds.DecisionApproved,
+		Team:    team.Name,
+		Actions: actions,
+	})
+}
+
+func (r *Recorder) record(mr *ds.MergeRequest, decision *ds.Decision) error {
+	decision.Policy = r.policy
+	decision.MergeRequestID = mr.ID
+	decision.ProjectID = mr.ProjectID
+	decision.IID = mr.IID
+	decision.CreatedAt = r.now()
+
+	err := r.r.AddDecision(decision)
+	if err != nil {
+		return errors.Wrap(err, "failed to store decision")
+	}
+
+	return nil
+}
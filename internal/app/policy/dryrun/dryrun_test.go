@@ -0,0 +1,69 @@
+package dryrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	repo := policytest.NewRepository()
+	r := New(repo, "rd")
+	r.now = func() time.Time { return ts }
+
+	team := policytest.Team("backend", "rd")
+	mr := policytest.MergeRequest(12, 1)
+
+	raw, err := bson.Marshal(bson.M{"reviewers_set": true})
+	require.NoError(t, err)
+	require.NoError(t, r.UpdatePolicyMetadata(mr, team, "rd", raw))
+
+	md, err := r.PolicyMetadata(mr, team, "rd")
+	require.NoError(t, err)
+	require.Equal(t, bson.Raw(raw), md)
+
+	md, err = repo.PolicyMetadata(mr, team, "rd")
+	require.NoError(t, err)
+	require.Nil(t, md, "metadata is kept apart")
+
+	require.NoError(t, r.SetReviewers(mr, []int{2, 3}))
+
+	actions := []ds.Action{{Type: ds.ActionComment, Comment: "ready to merge"}}
+	rec := &ds.ActionsRecord{}
+
+	require.NoError(t, r.OnApproved(team, mr, true, actions, rec))
+	require.NoError(t, r.OnApproved(team, mr, true, actions, rec))
+	require.Equal(t, []string{"0:comment"}, rec.Done)
+
+	require.Equal(t, []*ds.Decision{
+		{Policy: "rd", MergeRequestID: 12, ProjectID: 1, IID: 12, Kind: ds.DecisionReviewers, Reviewers: []int{2, 3}, CreatedAt: ts},
+		{Policy: "rd", MergeRequestID: 12, ProjectID: 1, IID: 12, Kind: ds.DecisionApproved, Team: "backend", Actions: actions, CreatedAt: ts},
+	}, repo.Decisions(), "approve is recorded once per transition")
+}
+
+func TestSelection(t *testing.T) {
+	t.Parallel()
+
+	repo := policytest.NewRepository()
+	_, err := repo.AdvanceRotationCursor("backend", "developers", 0, 3)
+	require.NoError(t, err)
+
+	s := NewSelection(repo)
+
+	moved, err := s.AdvanceRotationCursor("backend", "developers", 3, 4)
+	require.NoError(t, err)
+	require.True(t, moved)
+
+	cursor, err := s.RotationCursor("backend", "developers")
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor, "the live cursor is not moved")
+}
@@ -0,0 +1,33 @@
+package dryrun
+
+import "github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+
+type CursorRepository interface {
+	// RotationCursor returns GitLab ID of the last picked reviewer of the team pool, 0 if nobody was picked yet
+	RotationCursor(teamID, pool string) (int, error)
+}
+
+// Selection is the state of reviewer selection for policies in dry-run mode: the live state is read,
+// but picks don't change it, so dry-run picks are what live policies would pick and don't shift their rotation
+// or explain reviewers set by live policies
+type Selection struct {
+	r CursorRepository
+}
+
+func NewSelection(r CursorRepository) *Selection {
+	return &Selection{r: r}
+}
+
+func (s *Selection) RotationCursor(teamID, pool string) (int, error) {
+	return s.r.RotationCursor(teamID, pool)
+}
+
+// AdvanceRotationCursor pretends the cursor is moved
+func (s *Selection) AdvanceRotationCursor(string, string, int, int) (bool, error) {
+	return true, nil
+}
+
+// AddPickReasons drops reasons of reviewers which are not set
+func (s *Selection) AddPickReasons(int, []*ds.PickReason) error {
+	return nil
+}
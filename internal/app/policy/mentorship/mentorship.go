@@ -0,0 +1,286 @@
+// Package mentorship adds trainees of a team as shadow reviewers on top of reviewers of any policy.
+// Approves of trainees are not counted by the policy, approved shadow reviews graduate trainees.
+package mentorship
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// PolicyName is the name metadata of shadow reviews is stored under
+const PolicyName ds.PolicyName = "mentorship"
+
+type Repository interface {
+	// PolicyMetadata returns policy metadata for the given merge request
+	PolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName) (bson.Raw, error)
+	// UpdatePolicyMetadata updates policy metadata for the given merge request
+	UpdatePolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName, d bson.Raw) error
+	// AddShadowReview stores the shadow review of the trainee once, the first one is kept
+	AddShadowReview(review *ds.ShadowReview) error
+	// ApproveShadowReview marks the shadow review of the trainee approved
+	ApproveShadowReview(mrID int, traineeID int, at time.Time) error
+	// ShadowedReviewsCount returns how many shadow reviews of the team the trainee approved
+	ShadowedReviewsCount(teamID string, traineeID int) (int, error)
+	// UpdateMemberLabels replaces labels of the team member
+	UpdateMemberLabels(teamID string, gitlabID int, labels ds.UserLabels) error
+}
+
+type GitlabClient interface {
+	// SetReviewers overwrites reviewers list for the merge request
+	SetReviewers(mr *ds.MergeRequest, reviewers []int) error
+}
+
+type TeamsReloader interface {
+	// ReloadTeams replaces teams with stored ones
+	ReloadTeams() error
+}
+
+// Policy wraps the policy of the team with shadow reviews of trainees
+type Policy struct {
+	next service.Policy
+	r    Repository
+	g    GitlabClient
+	s    selection.Strategy
+	t    TeamsReloader
+	now  func() time.Time
+}
+
+func New(next service.Policy, r Repository, g GitlabClient, s selection.Strategy, t TeamsReloader) *Policy {
+	return &Policy{
+		next: next,
+		r:    r,
+		g:    g,
+		s:    s,
+		t:    t,
+		now:  time.Now,
+	}
+}
+
+type metadata struct {
+	ShadowsSet bool `bson:"shadows_set"`
+	// Shadows are GitLab IDs of trainees added as shadow reviewers
+	Shadows []int `bson:"shadows"`
+	// Approved are shadows whose approves are counted already
+	Approved []int `bson:"approved"`
+}
+
+func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
+	return len(ds.Trainees(team.Members)) == 0 ||
+		!team.Involved(mr) ||
+		!mr.State.Is(ds.StateOpened) ||
+		mr.Draft
+}
+
+func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error) {
+	required := withoutTrainees(team, mr)
+
+	err = p.next.ProcessChanges(team, required)
+
+	// reviewers may be set by the policy
+	mr.Reviewers = required.Reviewers
+
+	if err != nil {
+		return err
+	}
+
+	if p.skip(mr, team) {
+		return nil
+	}
+
+	md := metadata{}
+
+	raw, err := p.r.PolicyMetadata(mr, team, PolicyName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get mentorship metadata")
+	}
+
+	if raw != nil {
+		err = bson.Unmarshal(raw, &md)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal mentorship metadata")
+		}
+	}
+
+	changed := false
+
+	defer func() {
+		if !changed {
+			return
+		}
+
+		raw, saveErr := bson.Marshal(md)
+		if saveErr == nil {
+			saveErr = p.r.UpdatePolicyMetadata(mr, team, PolicyName, raw)
+		}
+
+		// the error of processing is more important
+		if err == nil && saveErr != nil {
+			err = errors.Wrap(saveErr, "failed to save mentorship metadata")
+		}
+	}()
+
+	// shadows join once required reviewers are set and the merge request waits for approves
+	if !md.ShadowsSet && len(mr.Reviewers) > 0 && !p.next.ApprovedByPolicy(team, required) {
+		changed = true
+
+		err = p.addShadows(team, mr, &md)
+		if err != nil {
+			return errors.Wrap(err, "failed to add shadow reviewers")
+		}
+	}
+
+	for _, id := range md.Shadows {
+		if lo.Contains(md.Approved, id) || !lo.ContainsBy(mr.Approves, func(u *ds.BasicUser) bool { return u.GitLabID == id }) {
+			continue
+		}
+
+		changed = true
+
+		err = p.shadowed(team, mr, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to count shadowed review")
+		}
+
+		md.Approved = append(md.Approved, id)
+	}
+
+	return nil
+}
+
+func (p *Policy) addShadows(team *ds.Team, mr *ds.MergeRequest, md *metadata) error {
+	reviewers := lo.Map(mr.Reviewers, func(u *ds.BasicUser, _ int) int { return u.GitLabID })
+
+	trainees := lo.FilterMap(ds.Trainees(team.Members), func(u *ds.User, _ int) (int, bool) {
+		return u.GitLabID, u.GitLabID != mr.Author.GitLabID
+	})
+
+	// trainees set as reviewers already (e.g. by a failed attempt) shadow the review too
+	shadows := lo.Intersect(trainees, reviewers)
+	candidates := lo.Without(trainees, reviewers...)
+
+	if count := team.MentorshipSettings().Shadows - len(shadows); count > 0 {
+		picked, err := p.s.Pick(team, selection.PoolTrainees, mr, candidates, count)
+		if err != nil {
+			return errors.Wrap(err, "failed to pick trainees")
+		}
+
+		if len(picked) > 0 {
+			err = p.g.SetReviewers(mr, append(reviewers, picked...))
+			if err != nil {
+				return err
+			}
+
+			shadows = append(shadows, picked...)
+		}
+	}
+
+	for _, id := range shadows {
+		err := p.r.AddShadowReview(&ds.ShadowReview{
+			TeamID:         team.ID,
+			TraineeID:      id,
+			MergeRequestID: mr.ID,
+			ProjectID:      mr.ProjectID,
+			IID:            mr.IID,
+			AssignedAt:     p.now(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to store shadow review")
+		}
+	}
+
+	// shadows are set once all of them are stored, failed attempts are retried
+	md.Shadows = shadows
+	md.ShadowsSet = true
+
+	return nil
+}
+
+// shadowed counts the approved shadow review and graduates the trainee after enough of them
+func (p *Policy) shadowed(team *ds.Team, mr *ds.MergeRequest, traineeID int) error {
+	err := p.r.ApproveShadowReview(mr.ID, traineeID, p.now())
+	if err != nil {
+		return err
+	}
+
+	after := team.MentorshipSettings().GraduateAfter
+	if after <= 0 {
+		return nil
+	}
+
+	trainee := team.Member(traineeID)
+	if trainee == nil || !trainee.Labels.Has(ds.TraineeLabel) {
+		return nil
+	}
+
+	count, err := p.r.ShadowedReviewsCount(team.ID, traineeID)
+	if err != nil {
+		return err
+	}
+
+	if count < after {
+		return nil
+	}
+
+	// teams are shared with concurrent processing, so labels are changed in storage only
+	labels := lo.Uniq(append(lo.Without(trainee.Labels, ds.TraineeLabel), ds.DeveloperLabel))
+
+	err = p.r.UpdateMemberLabels(team.ID, traineeID, labels)
+	if err != nil {
+		return errors.Wrap(err, "failed to graduate trainee")
+	}
+
+	// the trainee is picked as a developer from now on
+	err = p.t.ReloadTeams()
+	if err != nil {
+		return errors.Wrap(err, "failed to reload teams")
+	}
+
+	log.Info().
+		Str("team", team.Name).
+		Int("trainee_id", traineeID).
+		Int("shadowed", count).
+		Msg("trainee graduated")
+
+	return nil
+}
+
+func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds.BasicUser) bool {
+	return p.next.ApprovedByUser(team, mr, byAll...)
+}
+
+func (p *Policy) ApprovedByPolicy(team *ds.Team, mr *ds.MergeRequest) bool {
+	return p.next.ApprovedByPolicy(team, withoutTrainees(team, mr))
+}
+
+// ValidateSettings checks settings of the wrapped policy
+func (p *Policy) ValidateSettings(team *ds.Team) error {
+	validator, ok := p.next.(service.SettingsValidator)
+	if !ok {
+		return nil
+	}
+
+	return validator.ValidateSettings(team)
+}
+
+// withoutTrainees returns a copy of the merge request without approves of trainees of the team
+func withoutTrainees(team *ds.Team, mr *ds.MergeRequest) *ds.MergeRequest {
+	trainees := ds.Trainees(team.Members)
+	if len(trainees) == 0 {
+		return mr
+	}
+
+	required := *mr
+	required.Approves = lo.Filter(mr.Approves, func(u *ds.BasicUser, _ int) bool {
+		return !lo.ContainsBy(trainees, func(trainee *ds.User) bool { return trainee.GitLabID == u.GitLabID })
+	})
+
+	return &required
+}
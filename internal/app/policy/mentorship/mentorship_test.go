@@ -0,0 +1,135 @@
+package mentorship
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+	rd "github.com/jokerlee/gitlab-review-bot/internal/app/policy/reinventing-democracy"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+func newPolicy(k *policytest.Kit) service.Policy {
+	return New(rd.New(k.Repository, k.Gitlab, k.Strategy, k.Runner), k.Repository, k.Gitlab, k.Strategy, k.Teams)
+}
+
+// owners are code owners of any merge request
+type owners []int
+
+func (o owners) Owners(*ds.MergeRequest) ([]int, error) {
+	return o, nil
+}
+
+func scenario(t *testing.T, team *ds.Team) *policytest.Scenario {
+	return policytest.NewScenario(t, team, policytest.MergeRequest(1, 1), newPolicy)
+}
+
+func team() *ds.Team {
+	return policytest.Team("backend", rd.PolicyName,
+		policytest.Developer(1), policytest.Developer(2), policytest.Developer(3),
+		policytest.User(4, ds.TraineeLabel), policytest.User(5, ds.TraineeLabel))
+}
+
+func TestPolicy_ProcessChanges(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds a shadow on top of required reviewers", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, team()).Open().Process()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 2, "required reviewers, then the shadow")
+		require.Len(t, s.Reviewers(), 3)
+		require.Subset(t, s.Reviewers(), []int{2, 3})
+
+		reviews := s.Kit.Repository.ShadowReviews()
+		require.Len(t, reviews, 1)
+		require.Contains(t, []int{4, 5}, reviews[0].TraineeID)
+	})
+
+	t.Run("approves of trainees are not counted", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, team()).Open()
+
+		shadow := s.Kit.Repository.ShadowReviews()[0].TraineeID
+
+		s.ApproveBy(shadow, 2)
+		require.False(t, s.Approved())
+		require.NotNil(t, s.Kit.Repository.ShadowReviews()[0].ApprovedAt, "the shadow review is counted")
+
+		s.ApproveBy(3)
+		require.True(t, s.Approved())
+	})
+
+	t.Run("graduates trainees", func(t *testing.T) {
+		t.Parallel()
+
+		tm := team()
+		tm.Mentorship = &ds.MentorshipSettings{GraduateAfter: 1}
+
+		s := scenario(t, tm).Open()
+
+		shadow := s.Kit.Repository.ShadowReviews()[0].TraineeID
+		s.ApproveBy(shadow).Process()
+
+		require.Equal(t, ds.UserLabels{ds.DeveloperLabel}, s.Kit.Repository.Labels(shadow))
+		require.Equal(t, 1, s.Kit.Teams.Reloads(), "graduated trainees are picked as developers after reload")
+		require.Equal(t, ds.UserLabels{ds.TraineeLabel}, tm.Member(shadow).Labels, "loaded teams are not changed")
+	})
+
+	t.Run("retries shadows after a failure", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, team())
+		s.Kit.Repository.ShadowReviewErr = errors.New("storage is down")
+		s.MR.State = ds.StateOpened
+
+		require.Error(t, s.Policy.ProcessChanges(s.Team, s.MR))
+		require.Empty(t, s.Kit.Repository.ShadowReviews())
+
+		s.Kit.Repository.ShadowReviewErr = nil
+		s.Process().Process()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 2, "the assigned trainee shadows the review, no one else is picked")
+
+		reviews := s.Kit.Repository.ShadowReviews()
+		require.Len(t, reviews, 1)
+		require.Contains(t, s.Reviewers(), reviews[0].TraineeID)
+	})
+
+	t.Run("trainees shadow teams requiring code owners", func(t *testing.T) {
+		t.Parallel()
+
+		tm := team()
+		tm.CodeOwners = ds.CodeOwnersRequire
+
+		s := policytest.NewScenario(t, tm, policytest.MergeRequest(1, 1), func(k *policytest.Kit) service.Policy {
+			strategy := selection.NewConstraints(k.Strategy.(selection.ConstrainedStrategy), owners{2, 3}, k.Repository)
+
+			return New(rd.New(k.Repository, k.Gitlab, strategy, k.Runner), k.Repository, k.Gitlab, strategy, k.Teams)
+		}).Open()
+
+		require.Len(t, s.Reviewers(), 3, "owners and a trainee who is not an owner")
+
+		reviews := s.Kit.Repository.ShadowReviews()
+		require.Len(t, reviews, 1)
+		require.NotContains(t, lo.Map(s.Kit.Repository.PickReasons(1), func(r *ds.PickReason, _ int) int { return r.UserID }),
+			reviews[0].TraineeID, "shadows are not picked as owners")
+	})
+
+	t.Run("team without trainees", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, policytest.Team("backend", rd.PolicyName,
+			policytest.Developer(1), policytest.Developer(2), policytest.Developer(3))).Open().Process()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 1)
+		require.Empty(t, s.Kit.Repository.ShadowReviews())
+	})
+}
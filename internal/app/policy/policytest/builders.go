@@ -0,0 +1,62 @@
+package policytest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func User(id int, labels ...ds.UserLabel) *ds.User {
+	return &ds.User{
+		BasicUser: &ds.BasicUser{GitLabID: id, Name: fmt.Sprintf("user %d", id)},
+		Labels:    labels,
+	}
+}
+
+func Developer(id int) *ds.User {
+	return User(id, ds.DeveloperLabel)
+}
+
+func Lead(id int) *ds.User {
+	return User(id, ds.LeadLabel)
+}
+
+// Team returns a team with the ID and the name of id
+func Team(id string, policy ds.PolicyName, members ...*ds.User) *ds.Team {
+	return &ds.Team{
+		ID:      id,
+		Name:    id,
+		Members: members,
+		Policy:  policy,
+	}
+}
+
+// WithSettings sets policy_settings of the team
+func WithSettings(t testing.TB, team *ds.Team, settings interface{}) *ds.Team {
+	raw, err := bson.Marshal(settings)
+	require.NoError(t, err)
+
+	team.PolicySettings = raw
+
+	return team
+}
+
+// MergeRequest returns an opened merge request of a feature branch without reviewers
+func MergeRequest(id int, authorID int) *ds.MergeRequest {
+	return &ds.MergeRequest{
+		ID:           id,
+		IID:          id,
+		ProjectID:    1,
+		SourceBranch: fmt.Sprintf("feature/%d", id),
+		TargetBranch: "master",
+		Title:        fmt.Sprintf("Merge request %d", id),
+		State:        ds.StateOpened,
+		Author:       &ds.BasicUser{GitLabID: authorID},
+		SHA:          fmt.Sprintf("sha-%d", id),
+		URL:          fmt.Sprintf("https://gitlab.example.com/group/project/-/merge_requests/%d", id),
+	}
+}
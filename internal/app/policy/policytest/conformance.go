@@ -0,0 +1,150 @@
+package policytest
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// outsiderID is a GitLab user of no team
+const outsiderID = 100500
+
+// Conformance checks the contract of service.Policy around skip semantics and idempotency.
+// The first member of the team is the author of merge requests, approves of all other members must satisfy the policy.
+// Actions on approve must post a single comment, the suite sets on_approved of the team to it.
+func Conformance(t *testing.T, team *ds.Team, newPolicy Factory) {
+	require.NotEmpty(t, team.ID, "team needs an ID")
+	require.Greater(t, len(team.Members), 1, "team needs the author and reviewers")
+
+	authorID := team.Members[0].GitLabID
+
+	scenario := func(t *testing.T, modify func(mr *ds.MergeRequest)) *Scenario {
+		tm := *team
+
+		mr := MergeRequest(1, authorID)
+		modify(mr)
+
+		return NewScenario(t, &tm, mr, newPolicy)
+	}
+
+	skipped := map[string]func(mr *ds.MergeRequest){
+		"merge request of other team": func(mr *ds.MergeRequest) { mr.Author = &ds.BasicUser{GitLabID: outsiderID} },
+		"merged merge request":        func(mr *ds.MergeRequest) { mr.State = ds.StateMerged },
+		"closed merge request":        func(mr *ds.MergeRequest) { mr.State = ds.StateClosed },
+		"draft":                       func(mr *ds.MergeRequest) { mr.Draft = true },
+	}
+
+	for name, modify := range skipped {
+		modify := modify
+
+		t.Run("skips "+name, func(t *testing.T) {
+			t.Parallel()
+
+			s := scenario(t, modify).Process().Process()
+
+			require.Zero(t, s.Kit.Gitlab.Calls(), "skipped merge requests are not changed")
+			require.Zero(t, s.Kit.Repository.Writes(), "metadata of skipped merge requests is not saved")
+			require.True(t, s.Approved(), "skipped merge requests don't wait for approves")
+			require.True(t, s.Policy.ApprovedByUser(s.Team, s.MR, s.Team.Members[1].BasicUser),
+				"skipped merge requests don't wait for approves")
+		})
+	}
+
+	t.Run("picks teammates once", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(*ds.MergeRequest) {}).Open()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 1)
+		require.NotEmpty(t, s.Reviewers())
+		require.NotContains(t, s.Reviewers(), authorID, "the author doesn't review own merge request")
+
+		for _, id := range s.Reviewers() {
+			require.True(t, s.Team.Teammate(&ds.BasicUser{GitLabID: id}), "reviewers are teammates")
+		}
+
+		reviewers := s.Reviewers()
+		s.Process().Process()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 1, "reviewers are set once")
+		require.Equal(t, reviewers, s.Reviewers())
+	})
+
+	t.Run("keeps reviewers removed by people", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(*ds.MergeRequest) {}).Open()
+
+		removed := s.Reviewers()[0]
+		s.RemoveReviewer(removed)
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 1, "removed reviewers are not set again")
+		require.NotContains(t, s.Reviewers(), removed)
+	})
+
+	t.Run("waits for approves", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(*ds.MergeRequest) {}).Open()
+		reviewer := &ds.BasicUser{GitLabID: s.Reviewers()[0]}
+
+		require.False(t, s.Approved())
+		require.False(t, s.Policy.ApprovedByUser(s.Team, s.MR), "nobody approved")
+		require.False(t, s.Policy.ApprovedByUser(s.Team, s.MR, reviewer))
+
+		s.ApproveBy(reviewer.GitLabID)
+		require.True(t, s.Policy.ApprovedByUser(s.Team, s.MR, reviewer))
+	})
+
+	t.Run("does actions once per approve", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(*ds.MergeRequest) {})
+		s.Team.OnApproved = []ds.Action{{Type: ds.ActionComment, Comment: "approved"}}
+
+		others := lo.FilterMap(s.Team.Members, func(member *ds.User, _ int) (int, bool) {
+			return member.GitLabID, member.GitLabID != authorID
+		})
+
+		s.Open().ApproveBy(others...).Process()
+
+		require.True(t, s.Approved(), "approves of the whole team satisfy the policy")
+		// policies may override actions of the team by own settings, one comment is expected anyway
+		require.Len(t, s.Kit.Gitlab.Comments, 1, "actions are done once")
+
+		s.Push("sha-2")
+		require.False(t, s.Approved(), "new revision needs approves")
+
+		s.ApproveBy(others...)
+		require.Len(t, s.Kit.Gitlab.Comments, 2, "actions are done on every approve")
+	})
+
+	t.Run("reviews merge requests touching paths of the team", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(mr *ds.MergeRequest) {
+			mr.Author = &ds.BasicUser{GitLabID: outsiderID}
+			mr.InvolvedTeams = []string{team.ID}
+		}).Open()
+
+		require.Len(t, s.Kit.Gitlab.ReviewerCalls, 1)
+		require.False(t, s.Approved())
+	})
+
+	t.Run("waits for other involved teams", func(t *testing.T) {
+		t.Parallel()
+
+		s := scenario(t, func(mr *ds.MergeRequest) { mr.PendingTeams = []string{"other"} })
+		s.Team.OnApproved = []ds.Action{{Type: ds.ActionComment, Comment: "approved"}}
+
+		others := lo.FilterMap(s.Team.Members, func(member *ds.User, _ int) (int, bool) {
+			return member.GitLabID, member.GitLabID != authorID
+		})
+
+		s.Open().ApproveBy(others...)
+		require.Empty(t, s.Kit.Gitlab.Comments, "actions wait for approves of other teams")
+	})
+}
@@ -0,0 +1,329 @@
+// Package policytest helps to test review policies: in-memory dependencies, builders of teams and merge requests,
+// scenarios of merge request changes and the conformance suite of service.Policy.
+package policytest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/actions"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// Kit is a set of dependencies of a policy
+type Kit struct {
+	Repository *Repository
+	Gitlab     *Gitlab
+	Runner     *actions.Runner
+	Teams      *Teams
+	// Strategy picks reviewers randomly with a fixed seed
+	Strategy selection.Strategy
+}
+
+// Factory creates the policy under test with dependencies of the kit
+type Factory func(k *Kit) service.Policy
+
+func NewKit() *Kit {
+	g := &Gitlab{}
+
+	return &Kit{
+		Repository: NewRepository(),
+		Gitlab:     g,
+		Runner:     actions.New(g, nil, nil),
+		Teams:      &Teams{},
+		Strategy:   selection.NewRandom(rand.New(rand.NewSource(1))),
+	}
+}
+
+type metadataKey struct {
+	mrID   int
+	teamID string
+	policy ds.PolicyName
+}
+
+type cursorKey struct {
+	teamID string
+	pool   string
+}
+
+// Repository keeps policy metadata, shadow reviews, labels of members, dry-run decisions,
+// rotation cursors and reasons of picks in memory
+type Repository struct {
+	mu     sync.Mutex
+	md     map[metadataKey]bson.Raw
+	writes int
+
+	shadowReviews []*ds.ShadowReview
+	labels        map[int]ds.UserLabels
+	decisions     []*ds.Decision
+	cursors       map[cursorKey]int
+	reasons       map[int][]*ds.PickReason
+
+	// ShadowReviewErr fails AddShadowReview when set
+	ShadowReviewErr error
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		md:      make(map[metadataKey]bson.Raw),
+		labels:  make(map[int]ds.UserLabels),
+		cursors: make(map[cursorKey]int),
+		reasons: make(map[int][]*ds.PickReason),
+	}
+}
+
+func (r *Repository) PolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName) (bson.Raw, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.md[metadataKey{mrID: mr.ID, teamID: team.ID, policy: policy}], nil
+}
+
+func (r *Repository) UpdatePolicyMetadata(mr *ds.MergeRequest, team *ds.Team, policy ds.PolicyName, d bson.Raw) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.md[metadataKey{mrID: mr.ID, teamID: team.ID, policy: policy}] = append(bson.Raw{}, d...)
+	r.writes++
+
+	return nil
+}
+
+// Writes returns how many times metadata was saved
+func (r *Repository) Writes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.writes
+}
+
+func (r *Repository) AddShadowReview(review *ds.ShadowReview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ShadowReviewErr != nil {
+		return r.ShadowReviewErr
+	}
+
+	for _, added := range r.shadowReviews {
+		if added.MergeRequestID == review.MergeRequestID && added.TraineeID == review.TraineeID {
+			return nil
+		}
+	}
+
+	saved := *review
+	r.shadowReviews = append(r.shadowReviews, &saved)
+
+	return nil
+}
+
+func (r *Repository) ApproveShadowReview(mrID int, traineeID int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, review := range r.shadowReviews {
+		if review.MergeRequestID == mrID && review.TraineeID == traineeID && review.ApprovedAt == nil {
+			review.ApprovedAt = &at
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) ShadowedReviewsCount(teamID string, traineeID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+
+	for _, review := range r.shadowReviews {
+		if review.TeamID == teamID && review.TraineeID == traineeID && review.ApprovedAt != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ShadowReviews returns copies of added shadow reviews
+func (r *Repository) ShadowReviews() []*ds.ShadowReview {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]*ds.ShadowReview, 0, len(r.shadowReviews))
+	for _, review := range r.shadowReviews {
+		saved := *review
+		res = append(res, &saved)
+	}
+
+	return res
+}
+
+func (r *Repository) UpdateMemberLabels(_ string, gitlabID int, labels ds.UserLabels) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.labels[gitlabID] = append(ds.UserLabels{}, labels...)
+
+	return nil
+}
+
+// Labels returns saved labels of the member, nil if they were not updated
+func (r *Repository) Labels(gitlabID int) ds.UserLabels {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.labels[gitlabID]
+}
+
+func (r *Repository) AddDecision(decision *ds.Decision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decisions = append(r.decisions, decision)
+
+	return nil
+}
+
+// Decisions returns recorded decisions of policies in dry-run mode
+func (r *Repository) Decisions() []*ds.Decision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*ds.Decision{}, r.decisions...)
+}
+
+func (r *Repository) RotationCursor(teamID, pool string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cursors[cursorKey{teamID: teamID, pool: pool}], nil
+}
+
+func (r *Repository) AdvanceRotationCursor(teamID, pool string, from, to int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := cursorKey{teamID: teamID, pool: pool}
+	if r.cursors[key] != from {
+		return false, nil
+	}
+
+	r.cursors[key] = to
+
+	return true, nil
+}
+
+func (r *Repository) AddPickReasons(mrID int, reasons []*ds.PickReason) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reasons[mrID] = append(r.reasons[mrID], reasons...)
+
+	return nil
+}
+
+// PickReasons returns saved reasons of picks of the merge request
+func (r *Repository) PickReasons(mrID int) []*ds.PickReason {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*ds.PickReason{}, r.reasons[mrID]...)
+}
+
+// Teams counts reloads of teams
+type Teams struct {
+	mu      sync.Mutex
+	reloads int
+}
+
+func (t *Teams) ReloadTeams() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reloads++
+
+	return nil
+}
+
+// Reloads returns how many times teams were reloaded
+func (t *Teams) Reloads() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.reloads
+}
+
+// Gitlab records calls of policies and actions
+type Gitlab struct {
+	mu sync.Mutex
+
+	// ReviewerCalls are reviewers lists of SetReviewers calls
+	ReviewerCalls [][]int
+	AddedLabels   []string
+	RemovedLabels []string
+	Comments      []string
+	Merges        int
+
+	calls int
+}
+
+// SetReviewers records the call and updates reviewers of the merge request like GitLab does
+func (g *Gitlab) SetReviewers(mr *ds.MergeRequest, reviewers []int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	g.ReviewerCalls = append(g.ReviewerCalls, append([]int{}, reviewers...))
+
+	mr.Reviewers = make([]*ds.BasicUser, 0, len(reviewers))
+	for _, id := range reviewers {
+		mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{GitLabID: id})
+	}
+
+	return nil
+}
+
+func (g *Gitlab) UpdateMergeRequestLabels(_ int, _ int, add []string, remove []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	g.AddedLabels = append(g.AddedLabels, add...)
+	g.RemovedLabels = append(g.RemovedLabels, remove...)
+
+	return nil
+}
+
+func (g *Gitlab) CommentMergeRequest(_ *ds.MergeRequest, comment string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	g.Comments = append(g.Comments, comment)
+
+	return nil
+}
+
+func (g *Gitlab) MergeWhenPipelineSucceeds(*ds.MergeRequest) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	g.Merges++
+
+	return nil
+}
+
+// Calls returns the count of all recorded calls
+func (g *Gitlab) Calls() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.calls
+}
@@ -0,0 +1,106 @@
+package policytest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// Scenario is a merge request changed step by step, the policy processes every change like the service does
+type Scenario struct {
+	t      testing.TB
+	Kit    *Kit
+	Policy service.Policy
+	Team   *ds.Team
+	MR     *ds.MergeRequest
+}
+
+func NewScenario(t testing.TB, team *ds.Team, mr *ds.MergeRequest, newPolicy Factory) *Scenario {
+	kit := NewKit()
+
+	return &Scenario{
+		t:      t,
+		Kit:    kit,
+		Policy: newPolicy(kit),
+		Team:   team,
+		MR:     mr,
+	}
+}
+
+// Process processes the merge request without changes
+func (s *Scenario) Process() *Scenario {
+	s.t.Helper()
+
+	require.NoError(s.t, s.Policy.ProcessChanges(s.Team, s.MR))
+
+	return s
+}
+
+// Open marks the merge request ready for review
+func (s *Scenario) Open() *Scenario {
+	s.t.Helper()
+
+	s.MR.State = ds.StateOpened
+	s.MR.Draft = false
+
+	return s.Process()
+}
+
+// ApproveBy adds approves of the users
+func (s *Scenario) ApproveBy(ids ...int) *Scenario {
+	s.t.Helper()
+
+	for _, id := range ids {
+		if !lo.ContainsBy(s.MR.Approves, func(user *ds.BasicUser) bool { return user.GitLabID == id }) {
+			s.MR.Approves = append(s.MR.Approves, &ds.BasicUser{GitLabID: id})
+		}
+	}
+
+	return s.Process()
+}
+
+// Push adds a new revision, approves are reset like GitLab does by default
+func (s *Scenario) Push(sha string) *Scenario {
+	s.t.Helper()
+
+	s.MR.SHA = sha
+	s.MR.Approves = nil
+
+	return s.Process()
+}
+
+// RemoveReviewer removes the reviewer like a person does in GitLab
+func (s *Scenario) RemoveReviewer(id int) *Scenario {
+	s.t.Helper()
+
+	s.MR.Reviewers = lo.Filter(s.MR.Reviewers, func(user *ds.BasicUser, _ int) bool { return user.GitLabID != id })
+
+	return s.Process()
+}
+
+// Close closes the merge request
+func (s *Scenario) Close() *Scenario {
+	s.t.Helper()
+
+	s.MR.State = ds.StateClosed
+
+	return s.Process()
+}
+
+// Reviewers returns sorted GitLab IDs of reviewers
+func (s *Scenario) Reviewers() []int {
+	ids := lo.Map(s.MR.Reviewers, func(user *ds.BasicUser, _ int) int { return user.GitLabID })
+	sort.Ints(ids)
+
+	return ids
+}
+
+// Approved checks if the merge request is approved by the policy
+func (s *Scenario) Approved() bool {
+	return s.Policy.ApprovedByPolicy(s.Team, s.MR)
+}
@@ -0,0 +1,18 @@
+package reinventing_democracy
+
+import (
+	"testing"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/policytest"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	team := policytest.Team("backend", PolicyName, policytest.Developer(1), policytest.Developer(2), policytest.Developer(3), policytest.Developer(4))
+
+	policytest.Conformance(t, team, func(k *policytest.Kit) service.Policy {
+		return New(k.Repository, k.Gitlab, k.Strategy, k.Runner)
+	})
+}
@@ -9,19 +9,19 @@ Final Approve:			2 approves from the team
 */
 
 import (
-	"strings"
-
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/zyedidia/generic/set"
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
 )
 
 const (
 	PolicyName ds.PolicyName = "rd"
-	// RequiredDevelopersCount number of developers to be picked (also count of dev approves)
+	// RequiredDevelopersCount default number of developers to be picked (also count of dev approves)
 	RequiredDevelopersCount = 2
 )
 
@@ -37,28 +37,49 @@ type GitlabClient interface {
 	SetReviewers(mr *ds.MergeRequest, reviewers []int) error
 }
 
+type ActionRunner interface {
+	// OnApproved does actions once per transition of the merge request to approved
+	OnApproved(team *ds.Team, mr *ds.MergeRequest, approved bool, actions []ds.Action, rec *ds.ActionsRecord) error
+}
+
 type Policy struct {
 	r Repository
 	g GitlabClient
+	s selection.Strategy
+	a ActionRunner
+
+	// name is the name metadata is stored under
+	name     ds.PolicyName
+	defaults Settings
 }
 
-func New(r Repository, g GitlabClient) *Policy {
+func New(r Repository, g GitlabClient, s selection.Strategy, a ActionRunner) *Policy {
+	return NewVariant(PolicyName, DefaultSettings(), r, g, s, a)
+}
+
+// NewVariant returns the policy under another name with other default settings (e.g. developers riot)
+func NewVariant(name ds.PolicyName, defaults Settings, r Repository, g GitlabClient, s selection.Strategy, a ActionRunner) *Policy {
 	return &Policy{
-		r: r,
-		g: g,
+		r:        r,
+		g:        g,
+		s:        s,
+		a:        a,
+		name:     name,
+		defaults: defaults,
 	}
-
 }
 
 type metadata struct {
 	ApprovedByPolicy  bool  `bson:"approved_by_policy"`
 	ReviewersSet      bool  `bson:"reviewers_set"`
 	ReviewersByPolicy []int `bson:"reviewers_by_policy"`
+	// Actions are on_approved actions done since the last transition to approved
+	Actions ds.ActionsRecord `bson:"actions"`
 }
 
-func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
-	// belongs to the team
-	if !team.Teammate(mr.Author) {
+func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team, s Settings) bool {
+	// belongs to the team or touches its paths
+	if !team.Involved(mr) {
 		return true
 	}
 
@@ -72,8 +93,8 @@ func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
 		return true
 	}
 
-	// not a release branch
-	if strings.Contains(mr.SourceBranch, "release/") {
+	// not a skipped (e.g. release) branch
+	if glob.MatchAny(s.SkipBranches, mr.SourceBranch) {
 		return true
 	}
 
@@ -81,14 +102,15 @@ func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
 }
 
 func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error) {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		return nil
 	}
 
 	// load metadata
 	md := metadata{}
 
-	raw, err := p.r.PolicyMetadata(mr, team, PolicyName)
+	raw, err := p.r.PolicyMetadata(mr, team, p.name)
 	if err != nil {
 		return errors.Wrap(err, "failed to get policy metadata")
 	}
@@ -102,14 +124,14 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 
 	// save metadata
 	defer func() {
-		raw, err = bson.Marshal(md)
-		if err != nil {
-			err = errors.Wrap(err, "failed to marshal policy metadata")
+		raw, saveErr := bson.Marshal(md)
+		if saveErr == nil {
+			saveErr = p.r.UpdatePolicyMetadata(mr, team, p.name, raw)
 		}
 
-		err = p.r.UpdatePolicyMetadata(mr, team, PolicyName, raw)
-		if err != nil {
-			err = errors.Wrap(err, "failed to update policy metadata")
+		// the error of processing is more important
+		if err == nil && saveErr != nil {
+			err = errors.Wrap(saveErr, "failed to save policy metadata")
 		}
 	}()
 
@@ -118,11 +140,16 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 		// check if approved by policy
 		md.ApprovedByPolicy = p.ApprovedByPolicy(team, mr)
 
+		err = p.a.OnApproved(team, mr, md.ApprovedByPolicy, team.OnApproved, &md.Actions)
+		if err != nil {
+			return errors.Wrap(err, "failed to do actions on approve")
+		}
+
 		return nil
 	}
 
 	// then set reviewers
-	err = p.setReviewers(team, mr, &md)
+	err = p.setReviewers(team, mr, s, &md)
 	if err != nil {
 		return errors.Wrap(err, "failed to set reviewers")
 	}
@@ -130,7 +157,7 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 	return nil
 }
 
-func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata) error {
+func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, s Settings, md *metadata) error {
 	md.ReviewersSet = true
 
 	reviewersSet := set.NewMapset[int]()
@@ -155,7 +182,7 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 	inner := reviewersSet.Intersection(developersSet)
 
 	// count of developers which needed to set as reviewers
-	needDevsCount := RequiredDevelopersCount - inner.Size()
+	needDevsCount := s.RequiredDevelopers - inner.Size()
 
 	// if we have enough reviewers from developers
 	if needDevsCount <= 0 {
@@ -164,20 +191,20 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 
 	// developers who are not reviewers
 	notPickedDevsSet := developersSet.Difference(reviewersSet)
-	notPickedDevs := lo.Shuffle(notPickedDevsSet.Keys())
 
-	for _, dev := range notPickedDevs {
-		if needDevsCount <= 0 {
-			break
-		}
+	pickedDevs, err := p.s.Pick(team, selection.PoolDevelopers, mr, notPickedDevsSet.Keys(), needDevsCount)
+	if err != nil {
+		md.ReviewersSet = false
+		return errors.Wrap(err, "failed to pick developers")
+	}
 
+	for _, dev := range pickedDevs {
 		md.ReviewersByPolicy = append(md.ReviewersByPolicy, dev)
 
 		reviewersSet.Put(dev)
-		needDevsCount--
 	}
 
-	err := p.g.SetReviewers(mr, reviewersSet.Keys())
+	err = p.g.SetReviewers(mr, reviewersSet.Keys())
 	if err != nil {
 		md.ReviewersSet = false
 		return errors.Wrap(err, "failed to set reviewers")
@@ -187,7 +214,8 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 }
 
 func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds.BasicUser) bool {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		// true means the MR meet "need approve" state yet
 		// or closed, merged, locked
 		return true
@@ -211,13 +239,14 @@ func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds
 }
 
 func (p *Policy) ApprovedByPolicy(team *ds.Team, mr *ds.MergeRequest) bool {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		// true means the MR meet "need approve" state yet
 		// or closed, merged, locked
 		return true
 	}
 
-	left := RequiredDevelopersCount
+	left := s.approves()
 
 	for _, user := range mr.Approves {
 		if user.GitLabID == mr.Author.GitLabID {
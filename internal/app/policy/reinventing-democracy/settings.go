@@ -0,0 +1,97 @@
+package reinventing_democracy
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/settings"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// Settings is the policy_settings document of a team, missing fields take default values
+type Settings struct {
+	// RequiredDevelopers number of developers to be picked (also count of dev approves)
+	RequiredDevelopers int `bson:"required_developers"`
+	// RequiredApproves number of developer approves, zero means approves of all required developers
+	RequiredApproves int `bson:"required_approves"`
+	// SkipBranches are globs of source branches the policy does nothing with
+	SkipBranches []string `bson:"skip_branches"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		RequiredDevelopers: RequiredDevelopersCount,
+		SkipBranches:       []string{"**/*release/**"},
+	}
+}
+
+// DecodeSettings decodes settings of the team over the defaults and validates them
+func DecodeSettings(team *ds.Team) (Settings, error) {
+	return decodeSettings(team, DefaultSettings())
+}
+
+func decodeSettings(team *ds.Team, defaults Settings) (Settings, error) {
+	s := defaults
+
+	err := settings.Decode(team.PolicySettings, &s)
+	if err != nil {
+		return s, err
+	}
+
+	if s.RequiredDevelopers < 1 {
+		return s, errors.Errorf("required_developers must be positive, got %d", s.RequiredDevelopers)
+	}
+
+	if s.RequiredApproves < 0 {
+		return s, errors.Errorf("required_approves must not be negative, got %d", s.RequiredApproves)
+	}
+
+	if s.RequiredApproves > s.RequiredDevelopers {
+		return s, errors.Errorf("required_approves (%d) must not exceed required_developers (%d)", s.RequiredApproves, s.RequiredDevelopers)
+	}
+
+	for _, pattern := range s.SkipBranches {
+		_, err = glob.Compile(pattern)
+		if err != nil {
+			return s, errors.Wrapf(err, "invalid skip_branches pattern %q", pattern)
+		}
+	}
+
+	return s, nil
+}
+
+// ValidateSettings checks policy settings of the team when teams are loaded.
+// A team smaller than required is only reported, fewer reviewers are picked for it as before.
+func (p *Policy) ValidateSettings(team *ds.Team) error {
+	s, err := decodeSettings(team, p.defaults)
+	if err != nil {
+		return err
+	}
+
+	if devs := len(ds.Developers(team.Members)); devs < s.RequiredDevelopers {
+		log.Warn().Str("team", team.Name).Msgf("%d developers required, but the team has only %d", s.RequiredDevelopers, devs)
+	}
+
+	return nil
+}
+
+// settings of the team, defaults are used if the settings became invalid after teams loading
+func (p *Policy) settings(team *ds.Team) Settings {
+	s, err := decodeSettings(team, p.defaults)
+	if err != nil {
+		log.Error().Err(err).Str("team", team.Name).Msg("invalid policy settings, defaults are used")
+		return p.defaults
+	}
+
+	return s
+}
+
+// approves is the number of developer approves the merge request needs
+func (s Settings) approves() int {
+	if s.RequiredApproves == 0 {
+		return s.RequiredDevelopers
+	}
+
+	return s.RequiredApproves
+}
@@ -0,0 +1,87 @@
+package reinventing_democracy
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+)
+
+func team(t *testing.T, settings bson.M) *ds.Team {
+	var raw bson.Raw
+
+	if settings != nil {
+		var err error
+		raw, err = bson.Marshal(settings)
+		require.NoError(t, err)
+	}
+
+	return &ds.Team{
+		Name: "backend",
+		Members: []*ds.User{
+			{BasicUser: &ds.BasicUser{GitLabID: 1}, Labels: ds.UserLabels{ds.DeveloperLabel}},
+			{BasicUser: &ds.BasicUser{GitLabID: 2}, Labels: ds.UserLabels{ds.DeveloperLabel}},
+			{BasicUser: &ds.BasicUser{GitLabID: 3}, Labels: ds.UserLabels{ds.DeveloperLabel}},
+		},
+		Policy:         PolicyName,
+		PolicySettings: raw,
+	}
+}
+
+func TestDecodeSettings(t *testing.T) {
+	t.Parallel()
+
+	s, err := DecodeSettings(team(t, nil))
+	require.NoError(t, err)
+	require.Equal(t, DefaultSettings(), s)
+
+	s, err = DecodeSettings(team(t, bson.M{"required_developers": 3, "skip_branches": bson.A{"hotfix/**"}}))
+	require.NoError(t, err)
+	require.Equal(t, Settings{RequiredDevelopers: 3, SkipBranches: []string{"hotfix/**"}}, s)
+
+	invalid := map[string]bson.M{
+		"unknown key":     {"required_leads": 1},
+		"zero developers": {"required_developers": 0},
+		"many approves":   {"required_approves": 3},
+	}
+
+	for name, settings := range invalid {
+		_, err = DecodeSettings(team(t, settings))
+		require.Error(t, err, name)
+	}
+}
+
+func TestPolicy_ValidateSettings(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil, nil, selection.NewRandom(rand.New(rand.NewSource(1))), nil)
+
+	// teams smaller than required were valid before the settings
+	require.NoError(t, p.ValidateSettings(team(t, bson.M{"required_developers": 4})))
+	require.Error(t, p.ValidateSettings(team(t, bson.M{"required_developers": 0})))
+}
+
+func TestPolicy_skip(t *testing.T) {
+	t.Parallel()
+
+	tm := team(t, nil)
+	p := New(nil, nil, selection.NewRandom(rand.New(rand.NewSource(1))), nil)
+
+	mr := func(branch string) *ds.MergeRequest {
+		return &ds.MergeRequest{
+			Author:       &ds.BasicUser{GitLabID: 1},
+			State:        ds.StateOpened,
+			SourceBranch: branch,
+		}
+	}
+
+	require.False(t, p.skip(mr("feature/x"), tm, DefaultSettings()))
+	require.True(t, p.skip(mr("release/1.2"), tm, DefaultSettings()))
+	require.True(t, p.skip(mr("team/release/1.2"), tm, DefaultSettings()))
+	require.True(t, p.skip(mr("hotfix-release/1.2"), tm, DefaultSettings()))
+	require.False(t, p.skip(mr("release/1.2"), tm, Settings{RequiredDevelopers: 2}), "no skipped branches")
+}
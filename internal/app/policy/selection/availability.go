@@ -0,0 +1,48 @@
+package selection
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type AbsenceRepository interface {
+	ActiveAbsences(at time.Time) ([]*ds.Absence, error)
+}
+
+// Available excludes absent candidates before picking with the next strategy
+type Available struct {
+	next Strategy
+	r    AbsenceRepository
+	now  func() time.Time
+}
+
+func NewAvailable(next Strategy, r AbsenceRepository) *Available {
+	return &Available{
+		next: next,
+		r:    r,
+		now:  time.Now,
+	}
+}
+
+func (a *Available) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	if n <= 0 || len(candidates) == 0 {
+		return a.next.Pick(team, pool, mr, candidates, n)
+	}
+
+	now := a.now()
+
+	absences, err := a.r.ActiveAbsences(now)
+	if err != nil {
+		// absences are not available, everybody is considered present
+		log.Error().Err(err).Int("mr_id", mr.ID).Msg("failed to get active absences")
+		return a.next.Pick(team, pool, mr, candidates, n)
+	}
+
+	absent := ds.AbsentUsers(absences, now)
+
+	return a.next.Pick(team, pool, mr, lo.Filter(candidates, func(id int, _ int) bool { return !absent[id] }), n)
+}
@@ -0,0 +1,55 @@
+package selection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type fakeAbsences struct {
+	absences []*ds.Absence
+	err      error
+}
+
+func (f fakeAbsences) ActiveAbsences(time.Time) ([]*ds.Absence, error) {
+	return f.absences, f.err
+}
+
+func TestAvailable_Pick(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	absences := []*ds.Absence{
+		{UserID: 1, From: now.Add(-day), To: now.Add(day)},
+		// starts tomorrow
+		{UserID: 2, From: now.Add(day), To: now.Add(2 * day)},
+		// returned an hour ago
+		{UserID: 3, From: now.Add(-day), To: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		absences fakeAbsences
+		n        int
+		want     []int
+	}{
+		{name: "absent user is skipped", absences: fakeAbsences{absences: absences}, n: 2, want: []int{2, 3}},
+		{name: "fewer present candidates", absences: fakeAbsences{absences: absences}, n: 4, want: []int{2, 3, 4}},
+		{name: "absences are not available", absences: fakeAbsences{err: errors.New("timeout")}, n: 2, want: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		a := NewAvailable(first{}, tt.absences)
+		a.now = func() time.Time { return now }
+
+		res, err := a.Pick(&ds.Team{}, PoolDevelopers, &ds.MergeRequest{}, []int{1, 2, 3, 4}, tt.n)
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.want, res, tt.name)
+	}
+}
@@ -0,0 +1,145 @@
+package selection
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type OwnersResolver interface {
+	// Owners returns GitLab IDs of code owners of the merge request
+	Owners(mr *ds.MergeRequest) ([]int, error)
+}
+
+type PickReasonRepository interface {
+	// AddPickReasons saves why reviewers of the merge request were picked, earlier reasons of the reviewers are replaced
+	AddPickReasons(mrID int, reasons []*ds.PickReason) error
+}
+
+// Constraints picks reviewers meeting constraints of the merge request with the next strategy in a single pick:
+//   - code owners by the team mode: "prefer" reserves a slot for an owner and falls back to the normal pool,
+//     "require" picks owners only and leaves slots empty if there are not enough of them;
+//   - a slot is reserved for a teammate with skills needed by changed paths.
+//
+// Constraints met by reviewers already are not applied. Reasons of picked reviewers are saved for notifications.
+// Trainees shadow required reviewers and don't fill their slots, so they are picked by the next strategy as is.
+type Constraints struct {
+	next   ConstrainedStrategy
+	owners OwnersResolver
+	r      PickReasonRepository
+}
+
+func NewConstraints(next ConstrainedStrategy, owners OwnersResolver, r PickReasonRepository) *Constraints {
+	return &Constraints{
+		next:   next,
+		owners: owners,
+		r:      r,
+	}
+}
+
+func (c *Constraints) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	if pool == PoolTrainees || n <= 0 || len(candidates) == 0 {
+		return c.next.Pick(team, pool, mr, candidates, n)
+	}
+
+	owners, err := c.codeOwners(team, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make([]Constraint, 0, 2)
+
+	if len(owners) > 0 {
+		ownerCandidates := lo.Intersect(candidates, owners)
+
+		switch {
+		case team.CodeOwners == ds.CodeOwnersRequire:
+			if len(ownerCandidates) < n {
+				log.Warn().
+					Str("team", team.Name).
+					Int("mr_id", mr.ID).
+					Int("required", n).
+					Int("owners", len(ownerCandidates)).
+					Msg("not enough code owners to pick")
+			}
+
+			candidates = ownerCandidates
+		// an owner is a reviewer already
+		case len(ownerCandidates) > 0 && !lo.ContainsBy(mr.Reviewers, func(reviewer *ds.BasicUser) bool { return lo.Contains(owners, reviewer.GitLabID) }):
+			constraints = append(constraints, Constraint{Of: ownerCandidates, Min: 1})
+		}
+	}
+
+	needed := team.SkillsOf(mr.ChangedPaths)
+	skilled := func(id int) bool {
+		member := team.Member(id)
+		return member != nil && len(member.MatchedSkills(needed)) > 0
+	}
+
+	// a skilled teammate is a reviewer already
+	if len(needed) > 0 && !lo.ContainsBy(mr.Reviewers, func(reviewer *ds.BasicUser) bool { return skilled(reviewer.GitLabID) }) {
+		skilledCandidates := lo.Filter(candidates, func(id int, _ int) bool { return skilled(id) })
+		if len(skilledCandidates) > 0 {
+			constraints = append(constraints, Constraint{Of: skilledCandidates, Min: 1})
+		}
+	}
+
+	picked, err := c.next.PickConstrained(team, pool, mr, candidates, n, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	c.savePickReasons(team, mr, picked, owners, needed)
+
+	return picked, nil
+}
+
+// codeOwners returns owners of changed files if the team picks them,
+// owners are not required if they can't be resolved in the "prefer" mode
+func (c *Constraints) codeOwners(team *ds.Team, mr *ds.MergeRequest) ([]int, error) {
+	if team.CodeOwners == "" {
+		return nil, nil
+	}
+
+	if team.CodeOwners != ds.CodeOwnersPrefer && team.CodeOwners != ds.CodeOwnersRequire {
+		return nil, errors.Errorf("unknown code owners mode %q of team %s", team.CodeOwners, team.Name)
+	}
+
+	owners, err := c.owners.Owners(mr)
+	if err != nil {
+		if team.CodeOwners == ds.CodeOwnersRequire {
+			return nil, errors.Wrap(err, "failed to resolve required code owners")
+		}
+
+		// owners are not available, the normal pool is used
+		log.Error().Err(err).Int("mr_id", mr.ID).Msg("failed to resolve code owners")
+
+		return nil, nil
+	}
+
+	return owners, nil
+}
+
+// savePickReasons saves code ownership and needed skills of picked reviewers, the pick is kept if saving fails
+func (c *Constraints) savePickReasons(team *ds.Team, mr *ds.MergeRequest, picked []int, owners []int, needed []string) {
+	reasons := lo.FilterMap(picked, func(id int, _ int) (*ds.PickReason, bool) {
+		reason := &ds.PickReason{UserID: id, CodeOwner: lo.Contains(owners, id)}
+
+		if member := team.Member(id); member != nil && len(needed) > 0 {
+			reason.Skills = member.MatchedSkills(needed)
+		}
+
+		return reason, reason.CodeOwner || len(reason.Skills) > 0
+	})
+
+	if len(reasons) == 0 {
+		return
+	}
+
+	err := c.r.AddPickReasons(mr.ID, reasons)
+	if err != nil {
+		log.Error().Err(err).Int("mr_id", mr.ID).Msg("failed to save pick reasons")
+	}
+}
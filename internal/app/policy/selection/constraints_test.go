@@ -0,0 +1,208 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// first picks candidates in ascending order
+type first struct{}
+
+func (f first) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	return f.PickConstrained(team, pool, mr, candidates, n, nil)
+}
+
+func (first) PickConstrained(_ *ds.Team, _ string, _ *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error) {
+	return satisfy(sorted(candidates), n, constraints), nil
+}
+
+type fakeOwners struct {
+	owners []int
+	err    error
+}
+
+func (f fakeOwners) Owners(*ds.MergeRequest) ([]int, error) {
+	return f.owners, f.err
+}
+
+type fakeReasons struct {
+	reasons []*ds.PickReason
+}
+
+func (f *fakeReasons) AddPickReasons(_ int, reasons []*ds.PickReason) error {
+	f.reasons = append(f.reasons, reasons...)
+	return nil
+}
+
+func TestConstraints_Pick_CodeOwners(t *testing.T) {
+	t.Parallel()
+
+	candidates := []int{1, 2, 3, 4}
+
+	tests := []struct {
+		name      string
+		mode      ds.CodeOwnersMode
+		owners    fakeOwners
+		reviewers []int
+		n         int
+		want      []int
+		wantErr   bool
+	}{
+		{name: "disabled", owners: fakeOwners{owners: []int{4}}, n: 2, want: []int{1, 2}},
+		{name: "prefer", mode: ds.CodeOwnersPrefer, owners: fakeOwners{owners: []int{3, 4}}, n: 2, want: []int{1, 3}},
+		{name: "prefer, owner is a reviewer", mode: ds.CodeOwnersPrefer, owners: fakeOwners{owners: []int{3, 10}}, reviewers: []int{10}, n: 2, want: []int{1, 2}},
+		{name: "prefer, no owners among candidates", mode: ds.CodeOwnersPrefer, owners: fakeOwners{owners: []int{10}}, n: 2, want: []int{1, 2}},
+		{name: "prefer, owners are not resolved", mode: ds.CodeOwnersPrefer, owners: fakeOwners{err: errors.New("gitlab is down")}, n: 2, want: []int{1, 2}},
+		{name: "require", mode: ds.CodeOwnersRequire, owners: fakeOwners{owners: []int{3, 4}}, n: 2, want: []int{3, 4}},
+		{name: "require, not enough owners", mode: ds.CodeOwnersRequire, owners: fakeOwners{owners: []int{4}}, n: 2, want: []int{4}},
+		{name: "require, no owners among candidates", mode: ds.CodeOwnersRequire, owners: fakeOwners{owners: []int{10}}, n: 2, want: []int{}},
+		{name: "require, files without owners", mode: ds.CodeOwnersRequire, owners: fakeOwners{owners: []int{}}, n: 2, want: []int{1, 2}},
+		{name: "require, owners are not resolved", mode: ds.CodeOwnersRequire, owners: fakeOwners{err: errors.New("gitlab is down")}, n: 2, wantErr: true},
+		{name: "unknown mode", mode: "always", owners: fakeOwners{owners: []int{4}}, n: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		team := &ds.Team{Name: "backend", CodeOwners: tt.mode}
+		mr := &ds.MergeRequest{ID: 1}
+
+		for _, id := range tt.reviewers {
+			mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{GitLabID: id})
+		}
+
+		res, err := NewConstraints(first{}, tt.owners, &fakeReasons{}).Pick(team, PoolDevelopers, mr, candidates, tt.n)
+		if tt.wantErr {
+			require.Error(t, err, tt.name)
+			continue
+		}
+
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.want, res, tt.name)
+	}
+}
+
+func TestConstraints_Pick_Skills(t *testing.T) {
+	t.Parallel()
+
+	member := func(id int, skills ...string) *ds.User {
+		return &ds.User{BasicUser: &ds.BasicUser{GitLabID: id}, Skills: skills}
+	}
+
+	team := &ds.Team{
+		Name:    "backend",
+		Members: []*ds.User{member(1), member(2, "frontend"), member(3, "db"), member(4, "db", "backend")},
+		Skills:  map[string][]string{"db": {"migrations/"}, "frontend": {"*.tsx"}},
+	}
+
+	candidates := []int{1, 2, 3, 4}
+
+	tests := []struct {
+		name      string
+		paths     []string
+		reviewers []int
+		n         int
+		want      []int
+	}{
+		{name: "no skills needed", paths: []string{"cmd/main.go"}, n: 2, want: []int{1, 2}},
+		{name: "skilled first", paths: []string{"migrations/001_users.sql"}, n: 2, want: []int{1, 3}},
+		{name: "single reviewer", paths: []string{"migrations/001_users.sql"}, n: 1, want: []int{3}},
+		{name: "any of needed skills", paths: []string{"web/app.tsx", "migrations/001_users.sql"}, n: 1, want: []int{2}},
+		{name: "skilled reviewer is assigned", paths: []string{"migrations/001_users.sql"}, reviewers: []int{4}, n: 2, want: []int{1, 2}},
+		{name: "no changed paths", n: 2, want: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		mr := &ds.MergeRequest{ID: 1, ChangedPaths: tt.paths}
+
+		for _, id := range tt.reviewers {
+			mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{GitLabID: id})
+		}
+
+		res, err := NewConstraints(first{}, fakeOwners{}, &fakeReasons{}).Pick(team, PoolDevelopers, mr, candidates, tt.n)
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.want, res, tt.name)
+	}
+}
+
+func TestConstraints_Pick_OwnersAndSkills(t *testing.T) {
+	t.Parallel()
+
+	member := func(id int, skills ...string) *ds.User {
+		return &ds.User{BasicUser: &ds.BasicUser{GitLabID: id}, Skills: skills}
+	}
+
+	team := &ds.Team{
+		Name:       "backend",
+		Members:    []*ds.User{member(1), member(2), member(3, "db"), member(4, "db"), member(5)},
+		Skills:     map[string][]string{"db": {"migrations/"}},
+		CodeOwners: ds.CodeOwnersPrefer,
+	}
+
+	mr := &ds.MergeRequest{ID: 1, ChangedPaths: []string{"migrations/001_users.sql"}}
+	reasons := &fakeReasons{}
+
+	res, err := NewConstraints(first{}, fakeOwners{owners: []int{4, 5}}, reasons).Pick(team, PoolDevelopers, mr, []int{1, 2, 3, 4, 5}, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 4}, res, "the skilled owner meets both constraints in a single slot")
+	require.Equal(t, []*ds.PickReason{{UserID: 4, Skills: []string{"db"}, CodeOwner: true}}, reasons.reasons,
+		"reasons are saved for picked owners and skilled reviewers")
+
+	team.CodeOwners = ds.CodeOwnersRequire
+	reasons.reasons = nil
+
+	res, err = NewConstraints(first{}, fakeOwners{owners: []int{4, 5}}, reasons).Pick(team, PoolDevelopers, mr, []int{1, 2, 3, 4, 5}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{4}, res, "skills are matched among required owners")
+}
+
+func TestConstraints_Pick_Trainees(t *testing.T) {
+	t.Parallel()
+
+	team := &ds.Team{
+		Name:       "backend",
+		Members:    []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 6}, Skills: []string{"db"}}},
+		Skills:     map[string][]string{"db": {"migrations/"}},
+		CodeOwners: ds.CodeOwnersRequire,
+	}
+	mr := &ds.MergeRequest{ID: 1, ChangedPaths: []string{"migrations/001_users.sql"}}
+	reasons := &fakeReasons{}
+
+	res, err := NewConstraints(first{}, fakeOwners{owners: []int{4}}, reasons).Pick(team, PoolTrainees, mr, []int{5, 6}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{5}, res, "trainees who are not owners shadow reviews of teams requiring owners")
+	require.Empty(t, reasons.reasons, "shadows are not picked for reasons of required reviewers")
+
+	res, err = NewConstraints(first{}, fakeOwners{err: errors.New("gitlab is down")}, reasons).Pick(team, PoolTrainees, mr, []int{5, 6}, 1)
+	require.NoError(t, err, "owners are not resolved for trainees")
+	require.Equal(t, []int{5}, res)
+}
+
+func TestConstraints_Pick_RoundRobin(t *testing.T) {
+	t.Parallel()
+
+	// Alice, Bob, Carol, Dave, Carol owns changed files
+	team := rotationTeam()
+	team.CodeOwners = ds.CodeOwnersPrefer
+
+	cursors := &fakeCursors{cursors: map[string]int{}}
+	strategy := NewConstraints(NewRoundRobin(cursors), fakeOwners{owners: []int{40}}, &fakeReasons{})
+
+	pick := func(n int) []int {
+		res, err := strategy.Pick(team, PoolDevelopers, &ds.MergeRequest{ID: 1}, []int{10, 20, 30, 40}, n)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	require.Equal(t, []int{30, 40}, pick(2), "Alice in turn and the owner")
+	require.Equal(t, 30, cursors.cursors["team1/"+PoolDevelopers], "the owner out of turn doesn't move the rotation")
+	require.Equal(t, []int{10, 40}, pick(2), "Bob isn't skipped, the owner is in turn")
+	require.Equal(t, 40, cursors.cursors["team1/"+PoolDevelopers])
+	require.Equal(t, []int{40}, pick(1), "only the owner")
+	require.Equal(t, 40, cursors.cursors["team1/"+PoolDevelopers], "Dave isn't skipped")
+	require.Equal(t, []int{20, 40}, pick(2))
+	require.Equal(t, 20, cursors.cursors["team1/"+PoolDevelopers])
+}
@@ -0,0 +1,102 @@
+package selection
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// maxAdvanceAttempts limits retries when the cursor is moved by concurrent picks
+const maxAdvanceAttempts = 5
+
+type CursorRepository interface {
+	// RotationCursor returns GitLab ID of the last picked reviewer of the team pool, 0 if nobody was picked yet
+	RotationCursor(teamID, pool string) (int, error)
+	// AdvanceRotationCursor moves the cursor if it still points to the from reviewer
+	AdvanceRotationCursor(teamID, pool string, from, to int) (bool, error)
+}
+
+// RoundRobin picks candidates in the order of team members, starting after the last picked one.
+// The author and assigned reviewers aren't candidates, so they are skipped without moving the rotation back.
+// Reviewers reserved for constraints (e.g. code owners) don't move the rotation, so nobody is skipped for them.
+type RoundRobin struct {
+	r CursorRepository
+}
+
+func NewRoundRobin(r CursorRepository) *RoundRobin {
+	return &RoundRobin{r: r}
+}
+
+func (rr *RoundRobin) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	return rr.PickConstrained(team, pool, mr, candidates, n, nil)
+}
+
+func (rr *RoundRobin) PickConstrained(team *ds.Team, pool string, _ *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error) {
+	if n <= 0 || len(candidates) == 0 {
+		return []int{}, nil
+	}
+
+	positions := make(map[int]int, len(team.Members))
+	for i, member := range team.Members {
+		positions[member.GitLabID] = i
+	}
+
+	// users out of the team are the last
+	rank := func(id int) int {
+		position, ok := positions[id]
+		if !ok {
+			return len(positions)
+		}
+
+		return position
+	}
+
+	ordered := sorted(candidates)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+
+	for attempt := 0; attempt < maxAdvanceAttempts; attempt++ {
+		last, err := rr.r.RotationCursor(team.ID, pool)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rotation cursor")
+		}
+
+		start := 0
+
+		// nobody or a former member was picked last, the rotation starts over
+		if position, ok := positions[last]; ok {
+			start = sort.Search(len(ordered), func(i int) bool {
+				return rank(ordered[i]) > position
+			})
+		}
+
+		rotation := make([]int, 0, len(ordered))
+		for i := range ordered {
+			rotation = append(rotation, ordered[(start+i)%len(ordered)])
+		}
+
+		picked := satisfy(rotation, n, constraints)
+
+		// the rotation moves to the last reviewer picked in turn, reviewers picked out of turn don't move it
+		inTurn := lo.Intersect(picked, rotation[:lo.Min([]int{n, len(rotation)})])
+		if len(inTurn) == 0 {
+			return picked, nil
+		}
+
+		ok, err := rr.r.AdvanceRotationCursor(team.ID, pool, last, inTurn[len(inTurn)-1])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to advance rotation cursor")
+		}
+
+		if ok {
+			return picked, nil
+		}
+	}
+
+	return nil, errors.Errorf("rotation cursor of team %s pool %s is moved concurrently", team.Name, pool)
+}
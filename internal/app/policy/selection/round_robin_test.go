@@ -0,0 +1,169 @@
+package selection
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type fakeCursors struct {
+	mu      sync.Mutex
+	cursors map[string]int
+	// moved is called before every advance to simulate concurrent picks
+	moved func(key string)
+}
+
+func (f *fakeCursors) RotationCursor(teamID, pool string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.cursors[teamID+"/"+pool], nil
+}
+
+func (f *fakeCursors) AdvanceRotationCursor(teamID, pool string, from, to int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := teamID + "/" + pool
+
+	if f.moved != nil {
+		f.moved(key)
+	}
+
+	if f.cursors[key] != from {
+		return false, nil
+	}
+
+	f.cursors[key] = to
+
+	return true, nil
+}
+
+func rotationTeam() *ds.Team {
+	team := &ds.Team{ID: "team1", Name: "backend"}
+
+	// Alice, Bob, Carol, Dave
+	for _, id := range []int{30, 10, 40, 20} {
+		team.Members = append(team.Members, &ds.User{BasicUser: &ds.BasicUser{GitLabID: id}})
+	}
+
+	return team
+}
+
+func TestRoundRobin_Pick(t *testing.T) {
+	t.Parallel()
+
+	team := rotationTeam()
+	rr := NewRoundRobin(&fakeCursors{cursors: map[string]int{}})
+
+	pick := func(candidates []int, n int) []int {
+		res, err := rr.Pick(team, PoolDevelopers, nil, candidates, n)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	all := []int{10, 20, 30, 40}
+
+	// the order of team members
+	require.Equal(t, []int{30}, pick(all, 1))
+	require.Equal(t, []int{10}, pick(all, 1))
+	require.Equal(t, []int{40, 20}, pick(all, 2))
+	require.Equal(t, []int{30, 10}, pick(all, 2), "wraps around")
+
+	// Carol is the author, so Dave is the next
+	require.Equal(t, []int{20}, pick([]int{10, 20, 30}, 1))
+	require.Equal(t, []int{30, 10, 40, 20}, pick(all, 5))
+
+	other, err := rr.Pick(team, PoolLeads, nil, all, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{30}, other, "pools have own cursors")
+
+	none, err := rr.Pick(team, PoolDevelopers, nil, all, 0)
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestRoundRobin_PickConcurrently(t *testing.T) {
+	t.Parallel()
+
+	team := rotationTeam()
+	cursors := &fakeCursors{cursors: map[string]int{}}
+
+	// another pick moves the cursor to Alice before the first advance
+	moved := false
+	cursors.moved = func(key string) {
+		if !moved {
+			moved = true
+			cursors.cursors[key] = 30
+		}
+	}
+
+	res, err := NewRoundRobin(cursors).Pick(team, PoolDevelopers, nil, []int{10, 20, 30, 40}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{10}, res, "Bob is the next after the concurrent pick of Alice")
+
+	// the cursor is always moved by someone else
+	cursors.moved = func(key string) {
+		cursors.cursors[key]++
+	}
+
+	_, err = NewRoundRobin(cursors).Pick(team, PoolDevelopers, nil, []int{10, 20}, 1)
+	require.Error(t, err)
+}
+
+func TestRoundRobin_PickParallel(t *testing.T) {
+	t.Parallel()
+
+	team := rotationTeam()
+	rr := NewRoundRobin(&fakeCursors{cursors: map[string]int{}})
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		picked = map[int]int{}
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := rr.Pick(team, PoolDevelopers, nil, []int{10, 20, 30, 40}, 1)
+			require.NoError(t, err)
+
+			mu.Lock()
+			picked[res[0]]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, map[int]int{10: 1, 20: 1, 30: 1, 40: 1}, picked, "nobody is picked twice")
+}
+
+func TestPerTeam_Pick(t *testing.T) {
+	t.Parallel()
+
+	team := rotationTeam()
+	rr := NewRoundRobin(&fakeCursors{cursors: map[string]int{}})
+	p := NewPerTeam(rr, map[string]ConstrainedStrategy{NameRoundRobin: rr})
+
+	res, err := p.Pick(team, PoolDevelopers, nil, []int{10, 20}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{10}, res)
+
+	team.Selection = NameRoundRobin
+	res, err = p.Pick(team, PoolDevelopers, nil, []int{10, 20}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{20}, res)
+
+	team.Selection = "lottery"
+	_, err = p.Pick(team, PoolDevelopers, nil, []int{10, 20}, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,332 @@
+package selection
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// pools of built-in policies, the declarative policy uses names of its pools
+const (
+	PoolDevelopers = "developers"
+	PoolLeads      = "leads"
+	// PoolTrainees are shadow reviewers of the mentorship
+	PoolTrainees = "trainees"
+)
+
+// Strategy picks reviewers of the merge request among candidates (GitLab IDs)
+type Strategy interface {
+	// Pick returns up to n candidates of the team pool (e.g. "developers") in the order of picking
+	Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error)
+}
+
+// Constraint requires at least Min picked reviewers among Of (e.g. code owners of changed files)
+type Constraint struct {
+	Of  []int
+	Min int
+}
+
+// ConstrainedStrategy picks reviewers meeting constraints in a single pick,
+// so stateful strategies (e.g. round_robin) move their state once per pick
+type ConstrainedStrategy interface {
+	Strategy
+	// PickConstrained is Pick with slots reserved for constraints, constraints which can't be met are met partially
+	PickConstrained(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error)
+}
+
+// satisfy picks n candidates of the order of preference, slots are reserved for constraints first.
+// Picked are returned in the order of preference.
+func satisfy(order []int, n int, constraints []Constraint) []int {
+	unmet := make([]Constraint, len(constraints))
+	copy(unmet, constraints)
+
+	chosen := make(map[int]bool, n)
+
+	for len(chosen) < n {
+		// the first candidate meeting most of unmet constraints
+		best, bestScore := 0, 0
+
+		for _, id := range order {
+			if chosen[id] {
+				continue
+			}
+
+			score := lo.CountBy(unmet, func(c Constraint) bool { return c.Min > 0 && lo.Contains(c.Of, id) })
+			if score > bestScore {
+				best, bestScore = id, score
+			}
+		}
+
+		if bestScore == 0 {
+			break
+		}
+
+		chosen[best] = true
+
+		for i := range unmet {
+			if lo.Contains(unmet[i].Of, best) {
+				unmet[i].Min--
+			}
+		}
+	}
+
+	for _, id := range order {
+		if len(chosen) >= n {
+			break
+		}
+
+		chosen[id] = true
+	}
+
+	return lo.Filter(order, func(id int, _ int) bool { return chosen[id] })
+}
+
+// names of strategies in the config and team settings
+const (
+	NameRandom     = "random"
+	NameLoadAware  = "load_aware"
+	NameRoundRobin = "round_robin"
+)
+
+// PerTeam picks with the strategy selected by the team, or with the default one
+type PerTeam struct {
+	def    ConstrainedStrategy
+	byName map[string]ConstrainedStrategy
+}
+
+func NewPerTeam(def ConstrainedStrategy, byName map[string]ConstrainedStrategy) *PerTeam {
+	return &PerTeam{
+		def:    def,
+		byName: byName,
+	}
+}
+
+func (p *PerTeam) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	return p.PickConstrained(team, pool, mr, candidates, n, nil)
+}
+
+func (p *PerTeam) PickConstrained(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error) {
+	if team.Selection == "" {
+		return p.def.PickConstrained(team, pool, mr, candidates, n, constraints)
+	}
+
+	s, ok := p.byName[team.Selection]
+	if !ok {
+		return nil, errors.Errorf("unknown reviewer selection %q of team %s", team.Selection, team.Name)
+	}
+
+	return s.PickConstrained(team, pool, mr, candidates, n, constraints)
+}
+
+// lockedRand makes *rand.Rand safe for concurrent policies
+type lockedRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rnd.Float64()
+}
+
+func (l *lockedRand) Shuffle(n int, swap func(i, j int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rnd.Shuffle(n, swap)
+}
+
+// sorted copies candidates in ascending order, so the same seed gives the same picks
+func sorted(candidates []int) []int {
+	res := make([]int, len(candidates))
+	copy(res, candidates)
+	sort.Ints(res)
+
+	return res
+}
+
+// Random picks candidates uniformly
+type Random struct {
+	rnd *lockedRand
+}
+
+func NewRandom(rnd *rand.Rand) *Random {
+	return &Random{rnd: &lockedRand{rnd: rnd}}
+}
+
+func (r *Random) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	return r.PickConstrained(team, pool, mr, candidates, n, nil)
+}
+
+func (r *Random) PickConstrained(_ *ds.Team, _ string, _ *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error) {
+	order := sorted(candidates)
+
+	r.rnd.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	return satisfy(order, n, constraints), nil
+}
+
+type Repository interface {
+	// MergeRequestsByReviewer returns merge requests where one of the users is a reviewer
+	MergeRequestsByReviewer(reviewerID []int) ([]*ds.MergeRequest, error)
+}
+
+type Weights struct {
+	// Open is the penalty for every open merge request under review
+	Open float64
+	// Recent is the penalty for every merge request assigned within Window
+	Recent float64
+	Window time.Duration
+}
+
+var DefaultWeights = Weights{
+	Open:   1,
+	Recent: 0.5,
+	Window: 7 * 24 * time.Hour,
+}
+
+// LoadAware picks candidates randomly, the chance of a candidate is
+// 1 / (1 + Open*open reviews + Recent*recently assigned reviews)
+type LoadAware struct {
+	r   Repository
+	rnd *lockedRand
+	w   Weights
+	now func() time.Time
+}
+
+func NewLoadAware(r Repository, rnd *rand.Rand, w Weights) *LoadAware {
+	return &LoadAware{
+		r:   r,
+		rnd: &lockedRand{rnd: rnd},
+		w:   w,
+		now: time.Now,
+	}
+}
+
+// Load of a reviewer
+type Load struct {
+	// Open merge requests under review
+	Open int
+	// Recent merge requests the reviewer was assigned to within the window
+	Recent int
+}
+
+// Loads returns review load of candidates, the merge request itself is not counted
+func (l *LoadAware) Loads(mr *ds.MergeRequest, candidates []int) (map[int]Load, error) {
+	loads := make(map[int]Load, len(candidates))
+	for _, id := range candidates {
+		loads[id] = Load{}
+	}
+
+	if len(candidates) == 0 {
+		return loads, nil
+	}
+
+	mrs, err := l.r.MergeRequestsByReviewer(candidates)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merge requests by reviewers")
+	}
+
+	since := l.now().Add(-l.w.Window)
+
+	for _, reviewed := range mrs {
+		if mr != nil && reviewed.ID == mr.ID {
+			continue
+		}
+
+		open := reviewed.State.Is(ds.StateOpened) && !reviewed.Draft
+
+		for _, reviewer := range reviewed.Reviewers {
+			load, ok := loads[reviewer.GitLabID]
+			if !ok {
+				continue
+			}
+
+			if open {
+				load.Open++
+			}
+
+			if assigned := reviewed.AssignedAt(reviewer.GitLabID); assigned != nil && assigned.After(since) {
+				load.Recent++
+			}
+
+			loads[reviewer.GitLabID] = load
+		}
+	}
+
+	return loads, nil
+}
+
+func (l *LoadAware) weight(load Load) float64 {
+	return 1 / (1 + l.w.Open*float64(load.Open) + l.w.Recent*float64(load.Recent))
+}
+
+func (l *LoadAware) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	return l.PickConstrained(team, pool, mr, candidates, n, nil)
+}
+
+func (l *LoadAware) PickConstrained(_ *ds.Team, _ string, mr *ds.MergeRequest, candidates []int, n int, constraints []Constraint) ([]int, error) {
+	if n <= 0 || len(candidates) == 0 {
+		return []int{}, nil
+	}
+
+	order, err := l.order(mr, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return satisfy(order, n, constraints), nil
+}
+
+// order samples all candidates by their weights, so the less loaded ones tend to be first
+func (l *LoadAware) order(mr *ds.MergeRequest, candidates []int) ([]int, error) {
+	left := sorted(candidates)
+	res := make([]int, 0, len(left))
+
+	loads, err := l.Loads(mr, left)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]float64, len(left))
+	for i, id := range left {
+		weights[i] = l.weight(loads[id])
+	}
+
+	// weighted sampling without replacement
+	for len(left) > 0 {
+		total := 0.0
+		for _, w := range weights {
+			total += w
+		}
+
+		point := l.rnd.Float64() * total
+		picked := len(left) - 1
+
+		for i, w := range weights {
+			if point < w {
+				picked = i
+				break
+			}
+
+			point -= w
+		}
+
+		res = append(res, left[picked])
+
+		left = append(left[:picked], left[picked+1:]...)
+		weights = append(weights[:picked], weights[picked+1:]...)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,128 @@
+package selection
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type fakeRepository struct {
+	mrs []*ds.MergeRequest
+}
+
+func (f *fakeRepository) MergeRequestsByReviewer([]int) ([]*ds.MergeRequest, error) {
+	return f.mrs, nil
+}
+
+var now = time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+
+// reviewed returns the merge request created long ago, reviewers were assigned age ago
+func reviewed(id int, state ds.State, age time.Duration, reviewers ...int) *ds.MergeRequest {
+	created := now.Add(-60 * 24 * time.Hour)
+
+	mr := &ds.MergeRequest{ID: id, State: state, CreatedAt: &created}
+	for _, reviewer := range reviewers {
+		mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{GitLabID: reviewer})
+		mr.Assignments = append(mr.Assignments, &ds.Assignment{UserID: reviewer, AssignedAt: now.Add(-age)})
+	}
+
+	return mr
+}
+
+func TestRandom_Pick(t *testing.T) {
+	t.Parallel()
+
+	a, err := NewRandom(rand.New(rand.NewSource(1))).Pick(nil, "", nil, []int{4, 3, 2, 1}, 2)
+	require.NoError(t, err)
+	require.Len(t, a, 2)
+
+	// the order of candidates doesn't matter under the same seed
+	b, err := NewRandom(rand.New(rand.NewSource(1))).Pick(nil, "", nil, []int{1, 2, 3, 4}, 2)
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	all, err := NewRandom(rand.New(rand.NewSource(1))).Pick(nil, "", nil, []int{1, 2}, 5)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []int{1, 2}, all)
+
+	none, err := NewRandom(rand.New(rand.NewSource(1))).Pick(nil, "", nil, []int{1, 2}, 0)
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestLoadAware_Loads(t *testing.T) {
+	t.Parallel()
+
+	legacy := reviewed(4, ds.StateMerged, 0, 3)
+	legacy.Assignments = nil
+
+	r := &fakeRepository{mrs: []*ds.MergeRequest{
+		reviewed(1, ds.StateOpened, time.Hour, 1, 2),
+		reviewed(2, ds.StateOpened, 30*24*time.Hour, 1),
+		reviewed(3, ds.StateMerged, 24*time.Hour, 1, 100),
+		// the merge request itself is not counted
+		reviewed(10, ds.StateOpened, time.Hour, 2),
+		// assignments of merge requests stored before they were tracked date back to creation
+		legacy,
+	}}
+
+	l := NewLoadAware(r, rand.New(rand.NewSource(1)), DefaultWeights)
+	l.now = func() time.Time { return now }
+
+	loads, err := l.Loads(&ds.MergeRequest{ID: 10}, []int{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, map[int]Load{
+		1: {Open: 2, Recent: 2},
+		2: {Open: 1, Recent: 1},
+		3: {},
+	}, loads)
+}
+
+func TestLoadAware_Pick(t *testing.T) {
+	t.Parallel()
+
+	// the 1st is busy with 5 open reviews, the 2nd and the 3rd are free
+	mrs := make([]*ds.MergeRequest, 0, 5)
+	for i := 0; i < 5; i++ {
+		mrs = append(mrs, reviewed(i+1, ds.StateOpened, time.Hour, 1))
+	}
+
+	newStrategy := func(seed int64) *LoadAware {
+		l := NewLoadAware(&fakeRepository{mrs: mrs}, rand.New(rand.NewSource(seed)), DefaultWeights)
+		l.now = func() time.Time { return now }
+
+		return l
+	}
+
+	// deterministic under the same seed
+	a, err := newStrategy(42).Pick(nil, "", &ds.MergeRequest{ID: 100}, []int{3, 2, 1}, 2)
+	require.NoError(t, err)
+	b, err := newStrategy(42).Pick(nil, "", &ds.MergeRequest{ID: 100}, []int{1, 2, 3}, 2)
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+	require.Len(t, a, 2)
+
+	picked := map[int]int{}
+	l := newStrategy(1)
+
+	for i := 0; i < 1000; i++ {
+		res, err := l.Pick(nil, "", &ds.MergeRequest{ID: 100}, []int{1, 2, 3}, 1)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		picked[res[0]]++
+	}
+
+	// weights are 1/8.5, 1 and 1
+	require.Less(t, picked[1], 100)
+	require.Greater(t, picked[2], 400)
+	require.Greater(t, picked[3], 400)
+
+	all, err := l.Pick(nil, "", &ds.MergeRequest{ID: 100}, []int{1, 2, 3}, 5)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []int{1, 2, 3}, all)
+}
@@ -0,0 +1,121 @@
+// Package settings decodes policy settings of teams strictly, so typos in a team document
+// are reported when teams are loaded instead of being silently ignored.
+package settings
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Decode unmarshals the raw settings into v (a pointer to a struct), unknown keys are errors.
+// Empty settings leave v untouched, so v may be filled with defaults before.
+func Decode(raw bson.Raw, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	err := bson.Unmarshal(raw, v)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode policy settings")
+	}
+
+	return checkKeys(raw, reflect.TypeOf(v), "")
+}
+
+func checkKeys(raw bson.Raw, t reflect.Type, path string) error {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := fieldsByKey(t)
+
+	elements, err := raw.Elements()
+	if err != nil {
+		return errors.Wrap(err, "invalid policy settings document")
+	}
+
+	for _, element := range elements {
+		key := element.Key()
+
+		field, ok := fields[key]
+		if !ok {
+			return errors.Errorf("unknown setting %s%s", path, key)
+		}
+
+		value := element.Value()
+		fieldType := indirect(field.Type)
+
+		switch {
+		case value.Type == bsontype.EmbeddedDocument && fieldType.Kind() == reflect.Struct:
+			err = checkKeys(value.Document(), fieldType, path+key+".")
+		case value.Type == bsontype.Array && fieldType.Kind() == reflect.Slice:
+			err = checkArray(value.Array(), fieldType.Elem(), path+key)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkArray(raw bson.Raw, elem reflect.Type, path string) error {
+	values, err := raw.Values()
+	if err != nil {
+		return errors.Wrap(err, "invalid policy settings array")
+	}
+
+	for i, value := range values {
+		if value.Type != bsontype.EmbeddedDocument {
+			continue
+		}
+
+		err = checkKeys(value.Document(), elem, fmt.Sprintf("%s[%d].", path, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldsByKey maps bson keys to struct fields, keys follow the default bson naming (lower-cased field name)
+func fieldsByKey(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("bson")
+		if tag == "-" {
+			continue
+		}
+
+		key := strings.Split(tag, ",")[0]
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		fields[key] = field
+	}
+
+	return fields
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type pool struct {
+	Name string `bson:"name"`
+	Pick int    `bson:"pick"`
+}
+
+type testSettings struct {
+	Pools []pool `bson:"pools"`
+	Skip  struct {
+		Branches []string `bson:"branches"`
+	} `bson:"skip"`
+	Enabled *bool `bson:"enabled,omitempty"`
+}
+
+func raw(t *testing.T, v interface{}) bson.Raw {
+	b, err := bson.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid settings", func(t *testing.T) {
+		s := testSettings{}
+		err := Decode(raw(t, bson.M{
+			"pools":   bson.A{bson.M{"name": "devs", "pick": 2}},
+			"skip":    bson.M{"branches": bson.A{"release/*"}},
+			"enabled": true,
+		}), &s)
+		require.NoError(t, err)
+		require.Equal(t, []pool{{Name: "devs", Pick: 2}}, s.Pools)
+		require.Equal(t, []string{"release/*"}, s.Skip.Branches)
+		require.True(t, *s.Enabled)
+	})
+
+	t.Run("empty settings keep defaults", func(t *testing.T) {
+		s := testSettings{Pools: []pool{{Name: "default"}}}
+		require.NoError(t, Decode(nil, &s))
+		require.Equal(t, "default", s.Pools[0].Name)
+	})
+
+	tests := map[string]bson.M{
+		"unknown top level key": {"pool": bson.A{}},
+		"unknown nested key":    {"skip": bson.M{"branch": bson.A{}}},
+		"unknown key in array":  {"pools": bson.A{bson.M{"name": "devs", "count": 2}}},
+	}
+
+	for name, doc := range tests {
+		s := testSettings{}
+		require.Error(t, Decode(raw(t, doc), &s), name)
+	}
+
+	t.Run("path in the error", func(t *testing.T) {
+		s := testSettings{}
+		err := Decode(raw(t, bson.M{"pools": bson.A{bson.M{"name": "devs", "count": 2}}}), &s)
+		require.EqualError(t, err, "unknown setting pools[0].count")
+	})
+}
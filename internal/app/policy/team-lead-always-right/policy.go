@@ -9,21 +9,21 @@ Final Approve:			1 lead approve
 */
 
 import (
-	"strings"
-
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/zyedidia/generic/set"
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
 )
 
 const (
 	PolicyName ds.PolicyName = "tlar"
-	// RequiredLeadsCount number of lead to be picked as reviewers (also count of lead approves)
+	// RequiredLeadsCount default number of leads to be picked as reviewers (also count of lead approves)
 	RequiredLeadsCount = 1
-	// RequiredDevelopersCount number of developers to be picked as reviewers
+	// RequiredDevelopersCount default number of developers to be picked as reviewers
 	RequiredDevelopersCount = 1
 )
 
@@ -39,28 +39,38 @@ type GitlabClient interface {
 	SetReviewers(mr *ds.MergeRequest, reviewers []int) error
 }
 
+type ActionRunner interface {
+	// OnApproved does actions once per transition of the merge request to approved
+	OnApproved(team *ds.Team, mr *ds.MergeRequest, approved bool, actions []ds.Action, rec *ds.ActionsRecord) error
+}
+
 type Policy struct {
 	r Repository
 	g GitlabClient
+	s selection.Strategy
+	a ActionRunner
 }
 
-func New(r Repository, g GitlabClient) *Policy {
+func New(r Repository, g GitlabClient, s selection.Strategy, a ActionRunner) *Policy {
 	return &Policy{
 		r: r,
 		g: g,
+		s: s,
+		a: a,
 	}
-
 }
 
 type metadata struct {
 	ApprovedByPolicy  bool  `bson:"approved_by_policy"`
 	ReviewersSet      bool  `bson:"reviewers_set"`
 	ReviewersByPolicy []int `bson:"reviewers_by_policy"`
+	// Actions are on_approved actions done since the last transition to approved
+	Actions ds.ActionsRecord `bson:"actions"`
 }
 
-func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
-	// belongs to the team
-	if !team.Teammate(mr.Author) {
+func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team, s Settings) bool {
+	// belongs to the team or touches its paths
+	if !team.Involved(mr) {
 		return true
 	}
 
@@ -74,8 +84,8 @@ func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
 		return true
 	}
 
-	// not a release branch
-	if strings.Contains(mr.SourceBranch, "release/") {
+	// not a skipped (e.g. release) branch
+	if glob.MatchAny(s.SkipBranches, mr.SourceBranch) {
 		return true
 	}
 
@@ -83,7 +93,8 @@ func (p *Policy) skip(mr *ds.MergeRequest, team *ds.Team) bool {
 }
 
 func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error) {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		return nil
 	}
 
@@ -104,14 +115,14 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 
 	// save metadata
 	defer func() {
-		raw, err = bson.Marshal(md)
-		if err != nil {
-			err = errors.Wrap(err, "failed to marshal policy metadata")
+		raw, saveErr := bson.Marshal(md)
+		if saveErr == nil {
+			saveErr = p.r.UpdatePolicyMetadata(mr, team, PolicyName, raw)
 		}
 
-		err = p.r.UpdatePolicyMetadata(mr, team, PolicyName, raw)
-		if err != nil {
-			err = errors.Wrap(err, "failed to update policy metadata")
+		// the error of processing is more important
+		if err == nil && saveErr != nil {
+			err = errors.Wrap(saveErr, "failed to save policy metadata")
 		}
 	}()
 
@@ -120,11 +131,16 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 		// check if approved by policy
 		md.ApprovedByPolicy = p.ApprovedByPolicy(team, mr)
 
+		err = p.a.OnApproved(team, mr, md.ApprovedByPolicy, team.OnApproved, &md.Actions)
+		if err != nil {
+			return errors.Wrap(err, "failed to do actions on approve")
+		}
+
 		return nil
 	}
 
 	// then set reviewers
-	err = p.setReviewers(team, mr, &md)
+	err = p.setReviewers(team, mr, s, &md)
 	if err != nil {
 		return errors.Wrap(err, "failed to set reviewers")
 	}
@@ -132,7 +148,7 @@ func (p *Policy) ProcessChanges(team *ds.Team, mr *ds.MergeRequest) (err error)
 	return nil
 }
 
-func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata) error {
+func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, s Settings, md *metadata) error {
 	md.ReviewersSet = true
 
 	reviewersSet := set.NewMapset[int]()
@@ -158,21 +174,21 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 	inner := reviewersSet.Intersection(developersSet)
 
 	// count of developers which needed to set as reviewers
-	needDevsCount := RequiredDevelopersCount - inner.Size()
+	needDevsCount := s.RequiredDevelopers - inner.Size()
 
 	// developers who are not reviewers
 	notPickedDevsSet := developersSet.Difference(reviewersSet)
-	notPickedDevs := lo.Shuffle(notPickedDevsSet.Keys())
 
-	for _, dev := range notPickedDevs {
-		if needDevsCount <= 0 {
-			break
-		}
+	pickedDevs, err := p.s.Pick(team, selection.PoolDevelopers, mr, notPickedDevsSet.Keys(), needDevsCount)
+	if err != nil {
+		md.ReviewersSet = false
+		return errors.Wrap(err, "failed to pick developers")
+	}
 
+	for _, dev := range pickedDevs {
 		md.ReviewersByPolicy = append(md.ReviewersByPolicy, dev)
 
 		reviewersSet.Put(dev)
-		needDevsCount--
 	}
 
 	// leads pick
@@ -193,24 +209,24 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 	inner = reviewersSet.Intersection(leadsSet)
 
 	// count of leads which needed to set as reviewers
-	needLeadsCount := RequiredLeadsCount - inner.Size()
+	needLeadsCount := s.RequiredLeads - inner.Size()
 
 	// leads who are not reviewers
 	notPickedLeadsSet := leadsSet.Difference(reviewersSet)
-	notPickedLeads := lo.Shuffle(notPickedLeadsSet.Keys())
 
-	for _, lead := range notPickedLeads {
-		if needLeadsCount <= 0 {
-			break
-		}
+	pickedLeads, err := p.s.Pick(team, selection.PoolLeads, mr, notPickedLeadsSet.Keys(), needLeadsCount)
+	if err != nil {
+		md.ReviewersSet = false
+		return errors.Wrap(err, "failed to pick leads")
+	}
 
+	for _, lead := range pickedLeads {
 		md.ReviewersByPolicy = append(md.ReviewersByPolicy, lead)
 
 		reviewersSet.Put(lead)
-		needLeadsCount--
 	}
 
-	err := p.g.SetReviewers(mr, reviewersSet.Keys())
+	err = p.g.SetReviewers(mr, reviewersSet.Keys())
 	if err != nil {
 		md.ReviewersSet = false
 		return errors.Wrap(err, "failed to set reviewers")
@@ -220,7 +236,8 @@ func (p *Policy) setReviewers(team *ds.Team, mr *ds.MergeRequest, md *metadata)
 }
 
 func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds.BasicUser) bool {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		// true means the MR meet "need approve" state yet
 		// or closed, merged, locked
 		return true
@@ -244,13 +261,14 @@ func (p *Policy) ApprovedByUser(team *ds.Team, mr *ds.MergeRequest, byAll ...*ds
 }
 
 func (p *Policy) ApprovedByPolicy(team *ds.Team, mr *ds.MergeRequest) bool {
-	if p.skip(mr, team) {
+	s := p.settings(team)
+	if p.skip(mr, team, s) {
 		// true means the MR meet "need approve" state yet
 		// or closed, merged, locked
 		return true
 	}
 
-	left := RequiredLeadsCount
+	left := s.RequiredLeads
 
 	for _, user := range mr.Approves {
 		if user.GitLabID == mr.Author.GitLabID {
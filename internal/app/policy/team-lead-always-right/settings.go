@@ -0,0 +1,85 @@
+package reinventing_democracy
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/settings"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// Settings is the policy_settings document of a team, missing fields take default values
+type Settings struct {
+	// RequiredLeads number of leads to be picked as reviewers (also count of lead approves)
+	RequiredLeads int `bson:"required_leads"`
+	// RequiredDevelopers number of developers to be picked as reviewers
+	RequiredDevelopers int `bson:"required_developers"`
+	// SkipBranches are globs of source branches the policy does nothing with
+	SkipBranches []string `bson:"skip_branches"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		RequiredLeads:      RequiredLeadsCount,
+		RequiredDevelopers: RequiredDevelopersCount,
+		SkipBranches:       []string{"**/*release/**"},
+	}
+}
+
+// DecodeSettings decodes settings of the team over the defaults and validates them
+func DecodeSettings(team *ds.Team) (Settings, error) {
+	s := DefaultSettings()
+
+	err := settings.Decode(team.PolicySettings, &s)
+	if err != nil {
+		return s, err
+	}
+
+	if s.RequiredLeads < 1 {
+		return s, errors.Errorf("required_leads must be positive, got %d", s.RequiredLeads)
+	}
+
+	if s.RequiredDevelopers < 0 {
+		return s, errors.Errorf("required_developers must not be negative, got %d", s.RequiredDevelopers)
+	}
+
+	for _, pattern := range s.SkipBranches {
+		_, err = glob.Compile(pattern)
+		if err != nil {
+			return s, errors.Wrapf(err, "invalid skip_branches pattern %q", pattern)
+		}
+	}
+
+	return s, nil
+}
+
+// ValidateSettings checks policy settings of the team when teams are loaded.
+// A team smaller than required is only reported, fewer reviewers are picked for it as before.
+func (p *Policy) ValidateSettings(team *ds.Team) error {
+	s, err := DecodeSettings(team)
+	if err != nil {
+		return err
+	}
+
+	if leads := len(ds.Leads(team.Members)); leads < s.RequiredLeads {
+		log.Warn().Str("team", team.Name).Msgf("%d leads required, but the team has only %d", s.RequiredLeads, leads)
+	}
+
+	if devs := len(ds.Developers(team.Members)); devs < s.RequiredDevelopers {
+		log.Warn().Str("team", team.Name).Msgf("%d developers required, but the team has only %d", s.RequiredDevelopers, devs)
+	}
+
+	return nil
+}
+
+// settings of the team, defaults are used if the settings became invalid after teams loading
+func (p *Policy) settings(team *ds.Team) Settings {
+	s, err := DecodeSettings(team)
+	if err != nil {
+		log.Error().Err(err).Str("team", team.Name).Msg("invalid policy settings, defaults are used")
+		return DefaultSettings()
+	}
+
+	return s
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// AddAbsence stores a new absence, ID is generated if empty
+func (r *Repository) AddAbsence(absence *ds.Absence) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	if absence.ID == "" {
+		absence.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := r.absences.InsertOne(ctx, absence)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert absence")
+	}
+
+	return nil
+}
+
+// AbsencesByUser returns absences of the user ending after the time, the earliest first
+func (r *Repository) AbsencesByUser(userID int, after time.Time) ([]*ds.Absence, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.absences.Find(ctx,
+		bson.D{{"user_id", userID}, {"to", bson.M{"$gt": after}}},
+		options.Find().SetSort(bson.D{{"from", 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find absences")
+	}
+
+	absences := make([]*ds.Absence, 0)
+
+	err = cursor.All(ctx, &absences)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode absences")
+	}
+
+	return absences, nil
+}
+
+// ActiveAbsences returns absences of all users at the moment
+func (r *Repository) ActiveAbsences(at time.Time) ([]*ds.Absence, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.absences.Find(ctx,
+		bson.D{{"from", bson.M{"$lte": at}}, {"to", bson.M{"$gt": at}}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find active absences")
+	}
+
+	absences := make([]*ds.Absence, 0)
+
+	err = cursor.All(ctx, &absences)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode absences")
+	}
+
+	return absences, nil
+}
+
+// DeleteAbsence removes the absence of the user, false is returned if there is no such absence
+func (r *Repository) DeleteAbsence(userID int, id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	res, err := r.absences.DeleteOne(ctx, bson.D{{"_id", id}, {"user_id", userID}})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete absence")
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+// ReplaceAbsences replaces all absences of the user from the source (e.g. a re-imported calendar).
+// Absences are matched by ExternalID, kept ones are updated and keep merge requests handled because of them.
+func (r *Repository) ReplaceAbsences(userID int, source ds.AbsenceSource, absences []*ds.Absence) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	externalIDs := make([]string, 0, len(absences))
+
+	for _, absence := range absences {
+		externalIDs = append(externalIDs, absence.ExternalID)
+
+		if absence.ID == "" {
+			absence.ID = primitive.NewObjectID().Hex()
+		}
+
+		handled := absence.HandledMRs
+		if handled == nil {
+			handled = []int{}
+		}
+
+		_, err := r.absences.UpdateOne(ctx,
+			bson.D{{"user_id", userID}, {"source", source}, {"external_id", absence.ExternalID}},
+			bson.D{
+				{"$set", bson.D{{"from", absence.From}, {"to", absence.To}, {"reason", absence.Reason}}},
+				{"$setOnInsert", bson.D{{"_id", absence.ID}, {"handled_mrs", handled}, {"created_at", absence.CreatedAt}}},
+			},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return errors.Wrap(err, "failed to upsert absence")
+		}
+	}
+
+	_, err := r.absences.DeleteMany(ctx,
+		bson.D{{"user_id", userID}, {"source", source}, {"external_id", bson.M{"$nin": externalIDs}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete absences")
+	}
+
+	return nil
+}
+
+// MarkAbsenceHandled remembers the merge request flagged or reassigned because of the absence
+func (r *Repository) MarkAbsenceHandled(id string, mrID int) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.absences.UpdateOne(ctx,
+		bson.D{{"_id", id}},
+		bson.D{{"$addToSet", bson.D{{"handled_mrs", mrID}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to mark absence handled")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_Absences(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	vacation := &ds.Absence{
+		UserID:     1,
+		From:       now.Add(-24 * time.Hour),
+		To:         now.Add(24 * time.Hour),
+		Reason:     "vacation",
+		Source:     ds.AbsenceSourceSlack,
+		HandledMRs: []int{},
+		CreatedAt:  now,
+	}
+
+	t.Run("add an absence", func(t *testing.T) {
+		require.NoError(t, rep.AddAbsence(vacation))
+		require.NotEmpty(t, vacation.ID)
+	})
+
+	t.Run("import absences", func(t *testing.T) {
+		imported := []*ds.Absence{
+			{UserID: 1, From: now.Add(48 * time.Hour), To: now.Add(72 * time.Hour), Source: ds.AbsenceSourceICS, ExternalID: "a", HandledMRs: []int{}},
+			{UserID: 1, From: now.Add(96 * time.Hour), To: now.Add(120 * time.Hour), Source: ds.AbsenceSourceICS, ExternalID: "b", HandledMRs: []int{}},
+		}
+		require.NoError(t, rep.ReplaceAbsences(1, ds.AbsenceSourceICS, imported))
+
+		absences, err := rep.AbsencesByUser(1, now)
+		require.NoError(t, err)
+		require.Len(t, absences, 3)
+		require.NoError(t, rep.MarkAbsenceHandled(absences[1].ID, 10))
+
+		// re-import with a moved event
+		moved := &ds.Absence{UserID: 1, From: now.Add(50 * time.Hour), To: now.Add(72 * time.Hour), Source: ds.AbsenceSourceICS, ExternalID: "a"}
+		require.NoError(t, rep.ReplaceAbsences(1, ds.AbsenceSourceICS, []*ds.Absence{moved}))
+
+		absences, err = rep.AbsencesByUser(1, now)
+		require.NoError(t, err)
+		require.Len(t, absences, 2)
+		require.Equal(t, vacation.ID, absences[0].ID, "the earliest first")
+		require.Equal(t, "a", absences[1].ExternalID)
+		require.Equal(t, moved.From, absences[1].From)
+		require.Equal(t, []int{10}, absences[1].HandledMRs, "handled merge requests are kept")
+	})
+
+	t.Run("active absences", func(t *testing.T) {
+		absences, err := rep.ActiveAbsences(now)
+		require.NoError(t, err)
+		require.Len(t, absences, 1)
+		require.Equal(t, vacation.ID, absences[0].ID)
+	})
+
+	t.Run("mark handled", func(t *testing.T) {
+		require.NoError(t, rep.MarkAbsenceHandled(vacation.ID, 10))
+		require.NoError(t, rep.MarkAbsenceHandled(vacation.ID, 10))
+
+		absences, err := rep.ActiveAbsences(now)
+		require.NoError(t, err)
+		require.Equal(t, []int{10}, absences[0].HandledMRs)
+	})
+
+	t.Run("delete an absence", func(t *testing.T) {
+		deleted, err := rep.DeleteAbsence(2, vacation.ID)
+		require.NoError(t, err)
+		require.False(t, deleted, "absence of another user")
+
+		deleted, err = rep.DeleteAbsence(1, vacation.ID)
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		absences, err := rep.ActiveAbsences(now)
+		require.NoError(t, err)
+		require.Empty(t, absences)
+	})
+}
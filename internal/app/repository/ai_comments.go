@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func (r *Repository) UpsertAIComment(comment *ds.AIComment) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	opts := &options.UpdateOptions{}
+	opts.SetUpsert(true)
+
+	_, err := r.aiComments.UpdateOne(ctx,
+		bson.D{{"note_id", comment.NoteID}},
+		bson.D{{"$set", comment}},
+		opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert ai comment")
+	}
+
+	return nil
+}
+
+// AICommentsCreatedAfter returns comments created after the time, the oldest first
+func (r *Repository) AICommentsCreatedAfter(after time.Time) ([]*ds.AIComment, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.aiComments.Find(ctx,
+		bson.D{{"created_at", bson.M{"$gt": after}}},
+		options.Find().SetSort(bson.D{{"created_at", 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find ai comments")
+	}
+
+	comments := make([]*ds.AIComment, 0)
+
+	err = cursor.All(ctx, &comments)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ai comments")
+	}
+
+	return comments, nil
+}
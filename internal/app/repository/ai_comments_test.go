@@ -0,0 +1,51 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_AIComments(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	ts := time.Now().UTC().Truncate(time.Millisecond)
+	comment1 := &ds.AIComment{
+		DiscussionID:    "abc",
+		NoteID:          10,
+		ProjectID:       1,
+		MergeRequestIID: 2,
+		AuthorID:        3,
+		Category:        "review",
+		PromptVersion:   "2",
+		Model:           "gpt-4",
+		CreatedAt:       ts,
+	}
+
+	t.Run("create an ai comment", func(t *testing.T) {
+		err := rep.UpsertAIComment(comment1)
+		require.NoError(t, err, "failed to create ai comment")
+	})
+
+	t.Run("update feedback", func(t *testing.T) {
+		comment1.Feedback = ds.AIFeedback{Up: 2, Down: 1, Replies: 1, CheckedAt: ts}
+		err := rep.UpsertAIComment(comment1)
+		require.NoError(t, err, "failed to update ai comment")
+	})
+
+	t.Run("should return comments created after", func(t *testing.T) {
+		comments, err := rep.AICommentsCreatedAfter(ts.Add(-time.Minute))
+		require.NoError(t, err, "failed to get ai comments")
+		require.Len(t, comments, 1)
+		require.EqualValues(t, comment1, comments[0], "ai comments should be equal")
+
+		comments, err = rep.AICommentsCreatedAfter(ts)
+		require.NoError(t, err, "failed to get ai comments")
+		require.Empty(t, comments)
+	})
+}
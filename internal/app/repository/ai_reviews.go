@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// AIReviewByHash returns not expired cached AI review, nil if not found
+func (r *Repository) AIReviewByHash(hash string) (*ds.AIReview, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	review := &ds.AIReview{}
+
+	// TTL monitor removes documents with a delay, so expiration is checked explicitly
+	err := r.aiReviews.FindOne(ctx, bson.D{
+		{"hash", hash},
+		{"expires_at", bson.M{"$gt": time.Now()}},
+	}).Decode(review)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to find ai review")
+	}
+
+	return review, nil
+}
+
+func (r *Repository) UpsertAIReview(review *ds.AIReview) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	opts := &options.UpdateOptions{}
+	opts.SetUpsert(true)
+
+	_, err := r.aiReviews.UpdateOne(ctx,
+		bson.D{{"hash", review.Hash}},
+		bson.D{{"$set", review}},
+		opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert ai review")
+	}
+
+	return nil
+}
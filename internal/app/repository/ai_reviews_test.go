@@ -0,0 +1,52 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_AIReviews(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	t.Run("empty collection", func(t *testing.T) {
+		review, err := rep.AIReviewByHash("hash1")
+		require.NoError(t, err, "failed to get ai review")
+		require.Nil(t, review, "ai review should be not found")
+	})
+
+	ts := time.Now().UTC().Truncate(time.Millisecond)
+	review1 := &ds.AIReview{
+		Hash:          "hash1",
+		PromptVersion: "1",
+		Model:         "gpt-4",
+		Comment:       "LGTM",
+		CreatedAt:     ts,
+		ExpiresAt:     ts.Add(time.Hour),
+	}
+
+	t.Run("create an ai review", func(t *testing.T) {
+		err := rep.UpsertAIReview(review1)
+		require.NoError(t, err, "failed to create ai review")
+	})
+
+	t.Run("should return created ai review", func(t *testing.T) {
+		review, err := rep.AIReviewByHash("hash1")
+		require.NoError(t, err, "failed to get ai review")
+		require.EqualValues(t, review1, review, "ai reviews should be equal")
+	})
+
+	t.Run("expired ai review", func(t *testing.T) {
+		review1.ExpiresAt = ts.Add(-time.Minute)
+		require.NoError(t, rep.UpsertAIReview(review1), "failed to update ai review")
+
+		review, err := rep.AIReviewByHash("hash1")
+		require.NoError(t, err, "failed to get ai review")
+		require.Nil(t, review, "expired ai review should be not found")
+	})
+}
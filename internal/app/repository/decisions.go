@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func (r *Repository) AddDecision(decision *ds.Decision) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.decisions.InsertOne(ctx, decision)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert decision")
+	}
+
+	return nil
+}
+
+// DecisionsCreatedAfter returns decisions of dry-run policies made after the time, the oldest first
+func (r *Repository) DecisionsCreatedAfter(after time.Time) ([]*ds.Decision, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.decisions.Find(ctx,
+		bson.D{{"created_at", bson.M{"$gt": after}}},
+		options.Find().SetSort(bson.D{{"created_at", 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find decisions")
+	}
+
+	decisions := make([]*ds.Decision, 0)
+
+	err = cursor.All(ctx, &decisions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode decisions")
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,43 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_Decisions(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	ts := time.Now().UTC().Truncate(time.Millisecond)
+	decision := &ds.Decision{
+		Policy:         "rd",
+		MergeRequestID: 1,
+		ProjectID:      2,
+		IID:            3,
+		Kind:           ds.DecisionReviewers,
+		Reviewers:      []int{4, 5},
+		CreatedAt:      ts,
+	}
+
+	t.Run("add a decision", func(t *testing.T) {
+		err := rep.AddDecision(decision)
+		require.NoError(t, err, "failed to add decision")
+	})
+
+	t.Run("should return decisions created after", func(t *testing.T) {
+		decisions, err := rep.DecisionsCreatedAfter(ts.Add(-time.Minute))
+		require.NoError(t, err, "failed to get decisions")
+		require.Len(t, decisions, 1)
+		require.EqualValues(t, decision, decisions[0], "decisions should be equal")
+
+		decisions, err = rep.DecisionsCreatedAfter(ts)
+		require.NoError(t, err, "failed to get decisions")
+		require.Empty(t, decisions)
+	})
+}
@@ -104,3 +104,60 @@ func (r *Repository) UpsertMergeRequest(mr *ds.MergeRequest) error {
 
 	return nil
 }
+
+// AddPickReasons saves why reviewers of the merge request were picked, earlier reasons of the reviewers are replaced
+func (r *Repository) AddPickReasons(mrID int, reasons []*ds.PickReason) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	ids := make([]int, 0, len(reasons))
+	for _, reason := range reasons {
+		ids = append(ids, reason.UserID)
+	}
+
+	_, err := r.mergeRequests.UpdateOne(ctx,
+		bson.D{{"id", mrID}},
+		bson.D{{"$pull", bson.D{{"pick_reasons", bson.D{{"user_id", bson.D{{"$in", ids}}}}}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to remove pick reasons")
+	}
+
+	_, err = r.mergeRequests.UpdateOne(ctx,
+		bson.D{{"id", mrID}},
+		bson.D{{"$push", bson.D{{"pick_reasons", bson.D{{"$each", reasons}}}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to add pick reasons")
+	}
+
+	return nil
+}
+
+// MarkMergeRequestReviewed saves the revision of the merge request reviewed successfully
+func (r *Repository) MarkMergeRequestReviewed(mrID int, sha string) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.mergeRequests.UpdateOne(ctx,
+		bson.D{{"id", mrID}},
+		bson.D{{"$set", bson.D{{"reviewed_sha", sha}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to mark merge request reviewed")
+	}
+
+	return nil
+}
+
+// AddPostedFindings saves keys of rule findings commented on the merge request
+func (r *Repository) AddPostedFindings(mrID int, keys []string) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.mergeRequests.UpdateOne(ctx,
+		bson.D{{"id", mrID}},
+		bson.D{{"$addToSet", bson.D{{"posted_findings", bson.D{{"$each", keys}}}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to add posted findings")
+	}
+
+	return nil
+}
@@ -64,3 +64,40 @@ func TestRepository_MergeRequests(t *testing.T) {
 		require.EqualValues(t, mr1, mr, "merge requests should be equal")
 	})
 }
+
+func TestRepository_AddPickReasons(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	mr := &ds.MergeRequest{ID: 1, IID: 2, ProjectID: 3, Author: &ds.BasicUser{GitLabID: 9}}
+	require.NoError(t, rep.UpsertMergeRequest(mr))
+
+	require.NoError(t, rep.AddPickReasons(1, []*ds.PickReason{{UserID: 5, Skills: []string{"db"}}, {UserID: 6, CodeOwner: true}}))
+	require.NoError(t, rep.AddPickReasons(1, []*ds.PickReason{{UserID: 5, CodeOwner: true}}))
+
+	mr.Title = "renamed"
+	require.NoError(t, rep.UpsertMergeRequest(mr), "reasons are kept on updates of the merge request")
+
+	res, err := rep.MergeRequestByID(1)
+	require.NoError(t, err)
+	require.Equal(t, []*ds.PickReason{{UserID: 6, CodeOwner: true}, {UserID: 5, CodeOwner: true}}, res.PickReasons,
+		"the reason of the picked again reviewer is replaced")
+}
+
+func TestRepository_MarkMergeRequestReviewed(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	mr := &ds.MergeRequest{ID: 1, IID: 2, ProjectID: 3, SHA: "a", Author: &ds.BasicUser{GitLabID: 9}}
+	require.NoError(t, rep.UpsertMergeRequest(mr))
+
+	require.NoError(t, rep.MarkMergeRequestReviewed(1, "a"))
+	require.NoError(t, rep.AddPostedFindings(1, []string{"todo:main.go:3"}))
+	require.NoError(t, rep.AddPostedFindings(1, []string{"todo:main.go:3", "todo:main.go:7"}))
+
+	mr.SHA = "b"
+	require.NoError(t, rep.UpsertMergeRequest(mr), "the review is kept on updates of the merge request")
+
+	res, err := rep.MergeRequestByID(1)
+	require.NoError(t, err)
+	require.Equal(t, "a", res.ReviewedSHA)
+	require.Equal(t, []string{"todo:main.go:3", "todo:main.go:7"}, res.PostedFindings)
+}
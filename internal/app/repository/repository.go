@@ -22,19 +22,37 @@ type Repository struct {
 	mergeRequests  *mongo.Collection
 	commits        *mongo.Collection
 	policyMetadata *mongo.Collection
+	aiReviews      *mongo.Collection
+	aiComments     *mongo.Collection
+	// rotationCursors of round-robin reviewer selection
+	rotationCursors *mongo.Collection
+	absences        *mongo.Collection
+	// decisions of policies in dry-run mode
+	decisions *mongo.Collection
+	// shadowReviews of trainees
+	shadowReviews *mongo.Collection
+	// reviewSLAs are states of review deadlines of team reviewers
+	reviewSLAs *mongo.Collection
 }
 
 func New(rootCtx context.Context, conn *mongo.Client, databaseName string) (*Repository, error) {
 	database := conn.Database(databaseName)
 
 	r := &Repository{
-		ctx:            rootCtx,
-		conn:           conn,
-		teams:          database.Collection("teams"),
-		projects:       database.Collection("projects"),
-		mergeRequests:  database.Collection("merge_requests"),
-		commits:        database.Collection("commits"),
-		policyMetadata: database.Collection("policy_metadata"),
+		ctx:             rootCtx,
+		conn:            conn,
+		teams:           database.Collection("teams"),
+		projects:        database.Collection("projects"),
+		mergeRequests:   database.Collection("merge_requests"),
+		commits:         database.Collection("commits"),
+		policyMetadata:  database.Collection("policy_metadata"),
+		aiReviews:       database.Collection("ai_reviews"),
+		aiComments:      database.Collection("ai_comments"),
+		rotationCursors: database.Collection("rotation_cursors"),
+		absences:        database.Collection("absences"),
+		decisions:       database.Collection("decisions"),
+		shadowReviews:   database.Collection("shadow_reviews"),
+		reviewSLAs:      database.Collection("review_slas"),
 	}
 
 	err := r.createIndexes()
@@ -90,5 +108,103 @@ func (r *Repository) createIndexes() error {
 		return errors.Wrap(err, "failed to create policy_metadata indexes")
 	}
 
+	_, err = r.aiReviews.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"hash", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				// documents are removed right after expires_at
+				Keys:    bson.D{{"expires_at", 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create ai_reviews indexes")
+	}
+
+	_, err = r.aiComments.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"note_id", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{"created_at", 1}},
+				Options: options.Index(),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create ai_comments indexes")
+	}
+
+	_, err = r.rotationCursors.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"team_id", 1}, {"pool", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create rotation_cursors indexes")
+	}
+
+	_, err = r.absences.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"user_id", 1}, {"to", 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{"to", 1}},
+				Options: options.Index(),
+			},
+			{
+				Keys:    bson.D{{"user_id", 1}, {"source", 1}, {"external_id", 1}},
+				Options: options.Index(),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create absences indexes")
+	}
+
+	_, err = r.decisions.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"created_at", 1}},
+				Options: options.Index(),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create decisions indexes")
+	}
+
+	_, err = r.shadowReviews.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"mr_id", 1}, {"trainee_id", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{"team_id", 1}, {"trainee_id", 1}},
+				Options: options.Index(),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create shadow_reviews indexes")
+	}
+
+	_, err = r.reviewSLAs.Indexes().CreateMany(ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{"mr_id", 1}, {"team_id", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create review_slas indexes")
+	}
+
 	return nil
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// ReviewSLA returns the SLA state of the team reviewers of the merge request, nil if not tracked yet
+func (r *Repository) ReviewSLA(mrID int, teamID string) (*ds.ReviewSLA, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	result := &ds.ReviewSLA{}
+
+	err := r.reviewSLAs.FindOne(ctx, bson.D{{"mr_id", mrID}, {"team_id", teamID}}).Decode(result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to find review sla")
+	}
+
+	return result, nil
+}
+
+func (r *Repository) UpdateReviewSLA(sla *ds.ReviewSLA) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.reviewSLAs.UpdateOne(ctx,
+		bson.D{{"mr_id", sla.MergeRequestID}, {"team_id", sla.TeamID}},
+		bson.D{{"$set", sla}},
+		&options.UpdateOptions{Upsert: lo.ToPtr(true)})
+	if err != nil {
+		return errors.Wrap(err, "failed to update review sla")
+	}
+
+	return nil
+}
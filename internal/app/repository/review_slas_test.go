@@ -0,0 +1,39 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_ReviewSLA(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	sla, err := rep.ReviewSLA(1, "backend")
+	require.NoError(t, err)
+	require.Nil(t, sla)
+
+	ts := time.Now().UTC().Truncate(time.Millisecond)
+	sla = &ds.ReviewSLA{
+		MergeRequestID: 1,
+		TeamID:         "backend",
+		Reviewers:      []*ds.SLAReviewer{{ID: 5, RequestedAt: ts, Fired: []string{}}},
+	}
+	require.NoError(t, rep.UpdateReviewSLA(sla))
+
+	sla.Reviewers[0].Fired = append(sla.Reviewers[0].Fired, "first_response:nudge")
+	require.NoError(t, rep.UpdateReviewSLA(sla))
+
+	res, err := rep.ReviewSLA(1, "backend")
+	require.NoError(t, err)
+	require.Equal(t, sla, res)
+
+	res, err = rep.ReviewSLA(1, "frontend")
+	require.NoError(t, err)
+	require.Nil(t, res, "state is per team")
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// RotationCursor returns GitLab ID of the last picked reviewer of the team pool, 0 if nobody was picked yet
+func (r *Repository) RotationCursor(teamID, pool string) (int, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	rc := &ds.RotationCursor{}
+
+	err := r.rotationCursors.FindOne(ctx, bson.D{{"team_id", teamID}, {"pool", pool}}).Decode(rc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+
+		return 0, errors.Wrap(err, "failed to find rotation cursor")
+	}
+
+	return rc.Last, nil
+}
+
+// AdvanceRotationCursor moves the cursor of the team pool if it still points to the from reviewer,
+// false is returned if the cursor was moved concurrently
+func (r *Repository) AdvanceRotationCursor(teamID, pool string, from, to int) (bool, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	opts := &options.UpdateOptions{}
+	opts.SetUpsert(true)
+
+	// a missing cursor is created only from 0,
+	// otherwise the upsert violates the unique index
+	_, err := r.rotationCursors.UpdateOne(ctx,
+		bson.D{{"team_id", teamID}, {"pool", pool}, {"last", from}},
+		bson.D{{"$set", bson.D{{"last", to}, {"updated_at", time.Now().UTC()}}}},
+		opts)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		return false, errors.Wrap(err, "failed to advance rotation cursor")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,49 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_RotationCursors(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	t.Run("no cursor yet", func(t *testing.T) {
+		last, err := rep.RotationCursor("team1", "developers")
+		require.NoError(t, err)
+		require.Zero(t, last)
+	})
+
+	t.Run("create a cursor", func(t *testing.T) {
+		ok, err := rep.AdvanceRotationCursor("team1", "developers", 0, 10)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		last, err := rep.RotationCursor("team1", "developers")
+		require.NoError(t, err)
+		require.Equal(t, 10, last)
+	})
+
+	t.Run("advance from a stale position", func(t *testing.T) {
+		ok, err := rep.AdvanceRotationCursor("team1", "developers", 0, 20)
+		require.NoError(t, err)
+		require.False(t, ok, "the cursor was moved")
+
+		last, err := rep.RotationCursor("team1", "developers")
+		require.NoError(t, err)
+		require.Equal(t, 10, last)
+	})
+
+	t.Run("advance", func(t *testing.T) {
+		ok, err := rep.AdvanceRotationCursor("team1", "developers", 10, 20)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		last, err := rep.RotationCursor("team1", "leads")
+		require.NoError(t, err)
+		require.Zero(t, last, "pools have own cursors")
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// AddShadowReview stores the shadow review of the trainee once, the first one is kept
+func (r *Repository) AddShadowReview(review *ds.ShadowReview) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.shadowReviews.UpdateOne(ctx,
+		bson.D{{"mr_id", review.MergeRequestID}, {"trainee_id", review.TraineeID}},
+		bson.D{{"$setOnInsert", review}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return errors.Wrap(err, "failed to add shadow review")
+	}
+
+	return nil
+}
+
+// ApproveShadowReview marks the shadow review of the trainee approved, the first approve is kept
+func (r *Repository) ApproveShadowReview(mrID int, traineeID int, at time.Time) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.shadowReviews.UpdateOne(ctx,
+		bson.D{{"mr_id", mrID}, {"trainee_id", traineeID}, {"approved_at", nil}},
+		bson.D{{"$set", bson.D{{"approved_at", at}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to approve shadow review")
+	}
+
+	return nil
+}
+
+// ShadowReviews returns shadow reviews of trainees of the team, the oldest first
+func (r *Repository) ShadowReviews(teamID string) ([]*ds.ShadowReview, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	cursor, err := r.shadowReviews.Find(ctx,
+		bson.D{{"team_id", teamID}},
+		options.Find().SetSort(bson.D{{"assigned_at", 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find shadow reviews")
+	}
+
+	reviews := make([]*ds.ShadowReview, 0)
+
+	err = cursor.All(ctx, &reviews)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode shadow reviews")
+	}
+
+	return reviews, nil
+}
+
+// ShadowedReviewsCount returns how many shadow reviews of the team the trainee approved
+func (r *Repository) ShadowedReviewsCount(teamID string, traineeID int) (int, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	count, err := r.shadowReviews.CountDocuments(ctx, bson.D{
+		{"team_id", teamID},
+		{"trainee_id", traineeID},
+		{"approved_at", bson.M{"$ne": nil}},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count shadowed reviews")
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,84 @@
+//go:build mongodb
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestRepository_ShadowReviews(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	ts := time.Now().UTC().Truncate(time.Millisecond)
+	review := &ds.ShadowReview{
+		TeamID:         "backend",
+		TraineeID:      5,
+		MergeRequestID: 1,
+		ProjectID:      2,
+		IID:            3,
+		AssignedAt:     ts,
+	}
+
+	t.Run("add a shadow review", func(t *testing.T) {
+		require.NoError(t, rep.AddShadowReview(review))
+
+		count, err := rep.ShadowedReviewsCount("backend", 5)
+		require.NoError(t, err)
+		require.Zero(t, count, "not approved reviews are not shadowed")
+	})
+
+	t.Run("add again keeps the first review", func(t *testing.T) {
+		again := *review
+		again.AssignedAt = ts.Add(time.Minute)
+
+		require.NoError(t, rep.AddShadowReview(&again))
+
+		reviews, err := rep.ShadowReviews("backend")
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		require.Equal(t, ts, reviews[0].AssignedAt)
+	})
+
+	t.Run("approve keeps the first time", func(t *testing.T) {
+		require.NoError(t, rep.ApproveShadowReview(1, 5, ts.Add(time.Hour)))
+		require.NoError(t, rep.ApproveShadowReview(1, 5, ts.Add(2*time.Hour)))
+
+		reviews, err := rep.ShadowReviews("backend")
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		require.Equal(t, ts.Add(time.Hour), *reviews[0].ApprovedAt)
+
+		count, err := rep.ShadowedReviewsCount("backend", 5)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+}
+
+func TestRepository_UpdateMemberLabels(t *testing.T) {
+	rep := repositoryHelper(t)
+
+	team := &ds.Team{
+		ID:   "backend",
+		Name: "backend",
+		Members: []*ds.User{
+			{BasicUser: &ds.BasicUser{Name: "junior", GitLabID: 5}, Labels: ds.UserLabels{ds.TraineeLabel}},
+			{BasicUser: &ds.BasicUser{Name: "senior", GitLabID: 6}, Labels: ds.UserLabels{ds.DeveloperLabel}},
+		},
+	}
+
+	_, err := rep.teams.InsertOne(rep.ctx, team)
+	require.NoError(t, err, "failed to insert team")
+
+	require.NoError(t, rep.UpdateMemberLabels("backend", 5, ds.UserLabels{ds.DeveloperLabel}))
+
+	teams, err := rep.Teams()
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	require.Equal(t, ds.UserLabels{ds.DeveloperLabel}, teams[0].Members[0].Labels)
+	require.Equal(t, ds.UserLabels{ds.DeveloperLabel}, teams[0].Members[1].Labels)
+}
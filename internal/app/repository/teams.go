@@ -50,3 +50,18 @@ func (r *Repository) UserBySlackID(slackID string) (*ds.User, *ds.Team, error) {
 
 	return user, team, nil
 }
+
+// UpdateMemberLabels replaces labels of the team member
+func (r *Repository) UpdateMemberLabels(teamID string, gitlabID int, labels ds.UserLabels) error {
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	defer cancel()
+
+	_, err := r.teams.UpdateOne(ctx,
+		bson.D{{"_id", teamID}, {"members.basic_user.gitlab_id", gitlabID}},
+		bson.D{{"$set", bson.D{{"members.$.labels", labels}}}})
+	if err != nil {
+		return errors.Wrap(err, "failed to update member labels")
+	}
+
+	return nil
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+	"github.com/jokerlee/gitlab-review-bot/pkg/ics"
+)
+
+// maxAbsence limits the length of a declared absence
+const maxAbsence = 366 * 24 * time.Hour
+
+const absenceDateLayout = "2006-01-02"
+
+// ErrInvalidAbsence is returned for absences rejected by validation
+var ErrInvalidAbsence = errors.New("invalid absence")
+
+type AbsenceConfig struct {
+	// CheckPeriod is how often pending reviews of absent users are handled, 0 disables it
+	CheckPeriod time.Duration
+	// Reassign replaces absent reviewers by teammates, otherwise merge requests are flagged with a comment
+	Reassign bool
+	// Location of dates of Slack commands and calendar events without a time zone
+	Location *time.Location
+	// MinEvent is the shortest imported calendar event taken as an absence, all-day events are always absences
+	MinEvent time.Duration
+}
+
+func (s *Service) initAbsences() {
+	if s.cfg.Absence.Location == nil {
+		s.cfg.Absence.Location = time.Local
+	}
+
+	if s.cfg.Absence.CheckPeriod <= 0 {
+		return
+	}
+
+	s.cron.Schedule(cron.Every(s.cfg.Absence.CheckPeriod), cron.FuncJob(s.handleAbsentReviewers))
+}
+
+// AbsentUsers returns GitLab IDs of users absent at the moment
+func (s *Service) AbsentUsers(at time.Time) (map[int]bool, error) {
+	absences, err := s.r.ActiveAbsences(at)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get active absences")
+	}
+
+	return ds.AbsentUsers(absences, at), nil
+}
+
+// teammate returns the user from any team
+func (s *Service) teammate(userID int) *ds.User {
+	for _, team := range s.currentTeams() {
+		for _, member := range team.Members {
+			if member.GitLabID == userID {
+				return member
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeclareAbsence validates and stores the absence of a teammate
+func (s *Service) DeclareAbsence(absence *ds.Absence) error {
+	if s.teammate(absence.UserID) == nil {
+		return errors.Wrapf(ErrInvalidAbsence, "user %d is not a member of any team", absence.UserID)
+	}
+
+	if !absence.To.After(absence.From) {
+		return errors.Wrap(ErrInvalidAbsence, "absence must end after it starts")
+	}
+
+	if absence.To.Sub(absence.From) > maxAbsence {
+		return errors.Wrapf(ErrInvalidAbsence, "absence must not be longer than %d days", int(maxAbsence.Hours()/24))
+	}
+
+	absence.CreatedAt = time.Now()
+	if absence.HandledMRs == nil {
+		absence.HandledMRs = []int{}
+	}
+
+	return s.r.AddAbsence(absence)
+}
+
+// UserAbsences returns current and upcoming absences of the user
+func (s *Service) UserAbsences(userID int) ([]*ds.Absence, error) {
+	return s.r.AbsencesByUser(userID, time.Now())
+}
+
+// CancelAbsence removes the absence of the user, false is returned if there is no such absence
+func (s *Service) CancelAbsence(userID int, id string) (bool, error) {
+	return s.r.DeleteAbsence(userID, id)
+}
+
+// ImportAbsences replaces imported absences of the user by events of the calendar, returns count of absences
+func (s *Service) ImportAbsences(userID int, calendar []byte) (int, error) {
+	if s.teammate(userID) == nil {
+		return 0, errors.Wrapf(ErrInvalidAbsence, "user %d is not a member of any team", userID)
+	}
+
+	events, err := ics.Parse(calendar, s.cfg.Absence.Location)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidAbsence, "failed to parse calendar: %s", err)
+	}
+
+	now := time.Now()
+	absences := make([]*ds.Absence, 0, len(events))
+
+	for _, event := range events {
+		// ended, cancelled and zero-length events
+		if event.Cancelled || !event.End.After(now) || !event.End.After(event.Start) {
+			continue
+		}
+
+		// meetings and other short events
+		if !event.AllDay && event.End.Sub(event.Start) < s.cfg.Absence.MinEvent {
+			continue
+		}
+
+		// events are matched by UIDs on re-import
+		uid := event.UID
+		if uid == "" {
+			uid = event.Start.UTC().Format(time.RFC3339) + "/" + event.End.UTC().Format(time.RFC3339)
+		}
+
+		absences = append(absences, &ds.Absence{
+			UserID:     userID,
+			From:       event.Start,
+			To:         event.End,
+			Reason:     event.Summary,
+			Source:     ds.AbsenceSourceICS,
+			ExternalID: uid,
+			HandledMRs: []int{},
+			CreatedAt:  now,
+		})
+	}
+
+	err = s.r.ReplaceAbsences(userID, ds.AbsenceSourceICS, absences)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to save imported absences")
+	}
+
+	return len(absences), nil
+}
+
+const absenceUsage = "Usage:\n" +
+	"`/away 2024-05-01 2024-05-10 vacation` - away from the first till the last day inclusive\n" +
+	"`/away today sick` or `/away tomorrow` - a day off\n" +
+	"`/away list` - your current and upcoming absences\n" +
+	"`/away cancel <id>` - cancel the absence"
+
+// HandleAbsenceCommand declares, lists or cancels absences of the user by the Slack command text
+func (s *Service) HandleAbsenceCommand(user *ds.User, text string) (string, error) {
+	args := strings.Fields(text)
+
+	if len(args) == 0 || args[0] == "help" {
+		return absenceUsage, nil
+	}
+
+	switch args[0] {
+	case "list":
+		absences, err := s.UserAbsences(user.GitLabID)
+		if err != nil {
+			return "", err
+		}
+
+		if len(absences) == 0 {
+			return "You have no upcoming absences.", nil
+		}
+
+		lines := make([]string, 0, len(absences))
+		for _, absence := range absences {
+			lines = append(lines, fmt.Sprintf("`%s` %s", absence.ID, formatAbsence(absence, s.cfg.Absence.Location)))
+		}
+
+		return strings.Join(lines, "\n"), nil
+	case "cancel":
+		if len(args) != 2 {
+			return absenceUsage, nil
+		}
+
+		deleted, err := s.CancelAbsence(user.GitLabID, args[1])
+		if err != nil {
+			return "", err
+		}
+
+		if !deleted {
+			return fmt.Sprintf("Absence `%s` is not found.", args[1]), nil
+		}
+
+		return "Absence is cancelled.", nil
+	}
+
+	absence, err := ParseAbsenceCommand(args, time.Now().In(s.cfg.Absence.Location))
+	if err != nil {
+		return err.Error() + "\n" + absenceUsage, nil
+	}
+
+	absence.UserID = user.GitLabID
+	absence.Source = ds.AbsenceSourceSlack
+
+	err = s.DeclareAbsence(absence)
+	if errors.Is(err, ErrInvalidAbsence) {
+		return err.Error(), nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return "Got it, you are away " + formatAbsence(absence, s.cfg.Absence.Location) + ".", nil
+}
+
+// ParseAbsenceCommand parses "<from> [<to>] [reason]", dates are YYYY-MM-DD, "today" or "tomorrow" in the location of now
+func ParseAbsenceCommand(args []string, now time.Time) (*ds.Absence, error) {
+	parse := func(arg string) (time.Time, bool) {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		switch arg {
+		case "today":
+			return today, true
+		case "tomorrow":
+			return today.AddDate(0, 0, 1), true
+		}
+
+		t, err := time.ParseInLocation(absenceDateLayout, arg, now.Location())
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		return t, true
+	}
+
+	if len(args) == 0 {
+		return nil, errors.New("the first day is required")
+	}
+
+	from, ok := parse(args[0])
+	if !ok {
+		return nil, errors.Errorf("invalid date %q", args[0])
+	}
+
+	last, rest := from, args[1:]
+	if len(rest) > 0 {
+		if t, ok := parse(rest[0]); ok {
+			last, rest = t, rest[1:]
+		}
+	}
+
+	if last.Before(from) {
+		return nil, errors.New("the last day is before the first one")
+	}
+
+	return &ds.Absence{
+		From:   from,
+		To:     last.AddDate(0, 0, 1),
+		Reason: strings.Join(rest, " "),
+	}, nil
+}
+
+func formatAbsence(absence *ds.Absence, loc *time.Location) string {
+	from := absence.From.In(loc)
+	last := absence.To.In(loc).Add(-time.Nanosecond)
+
+	res := "on " + from.Format(absenceDateLayout)
+	if from.Format(absenceDateLayout) != last.Format(absenceDateLayout) {
+		res = "from " + from.Format(absenceDateLayout) + " till " + last.Format(absenceDateLayout)
+	}
+
+	if absence.Reason != "" {
+		res += " (" + absence.Reason + ")"
+	}
+
+	return res
+}
+
+// handleAbsentReviewers flags or reassigns open merge requests waiting for a review of absent users
+func (s *Service) handleAbsentReviewers() {
+	now := time.Now()
+
+	absences, err := s.r.ActiveAbsences(now)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get active absences")
+		return
+	}
+
+	absent := ds.AbsentUsers(absences, now)
+
+	for _, absence := range absences {
+		mrs, err := s.r.MergeRequestsByReviewer([]int{absence.UserID})
+		if err != nil {
+			log.Error().Err(err).Int("user_id", absence.UserID).Msg("failed to get merge requests of absent reviewer")
+			continue
+		}
+
+		for _, mr := range mrs {
+			if !mr.State.Is(ds.StateOpened) || lo.Contains(absence.HandledMRs, mr.ID) {
+				continue
+			}
+
+			// reviewed already
+			if lo.ContainsBy(mr.Approves, func(user *ds.BasicUser) bool { return user.GitLabID == absence.UserID }) {
+				continue
+			}
+
+			err = s.handleAbsentReviewer(mr, absence, absent)
+			if err != nil {
+				log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to handle absent reviewer")
+				continue
+			}
+
+			err = s.r.MarkAbsenceHandled(absence.ID, mr.ID)
+			if err != nil {
+				log.Error().Err(err).Str("absence_id", absence.ID).Msg("failed to mark absence handled")
+			}
+		}
+	}
+}
+
+func (s *Service) handleAbsentReviewer(mr *ds.MergeRequest, absence *ds.Absence, absent map[int]bool) error {
+	name := fmt.Sprintf("user %d", absence.UserID)
+	if user := s.teammate(absence.UserID); user != nil && user.Name != "" {
+		name = user.Name
+	}
+
+	away := fmt.Sprintf(":palm_tree: **%s** is away till %s", name,
+		absence.To.In(s.cfg.Absence.Location).Add(-time.Nanosecond).Format(absenceDateLayout))
+
+	if s.cfg.Absence.Reassign {
+		replacement, err := s.replacementReviewer(mr, absence.UserID, absent)
+		if err != nil {
+			return err
+		}
+
+		if replacement != nil {
+			reviewers := make([]int, 0, len(mr.Reviewers))
+			for _, reviewer := range mr.Reviewers {
+				if reviewer.GitLabID != absence.UserID {
+					reviewers = append(reviewers, reviewer.GitLabID)
+				}
+			}
+
+			err := s.gitlab.SetReviewers(mr, append(reviewers, replacement.GitLabID))
+			if err != nil {
+				return errors.Wrap(err, "failed to reassign reviewer")
+			}
+
+			_, err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID,
+				away+", the review is reassigned to **"+replacement.Name+"**.")
+			if err != nil {
+				return errors.Wrap(err, "failed to comment reassigned review")
+			}
+
+			return nil
+		}
+	}
+
+	_, err := s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID, away+", the review may be delayed.")
+	if err != nil {
+		return errors.Wrap(err, "failed to flag merge request")
+	}
+
+	return nil
+}
+
+// replacementReviewer picks a present teammate of the absent reviewer with the same label by the selection strategy
+// of the team, the team must be involved in the merge request
+func (s *Service) replacementReviewer(mr *ds.MergeRequest, absentID int, absent map[int]bool) (*ds.User, error) {
+	for _, team := range s.currentTeams() {
+		if mr.Author == nil || !team.Involved(mr) {
+			continue
+		}
+
+		reviewer := team.Member(absentID)
+		if reviewer == nil {
+			continue
+		}
+
+		candidates := lo.FilterMap(team.Members, func(member *ds.User, _ int) (int, bool) {
+			if member.GitLabID == mr.Author.GitLabID || absent[member.GitLabID] {
+				return 0, false
+			}
+
+			if lo.ContainsBy(mr.Reviewers, func(user *ds.BasicUser) bool { return user.GitLabID == member.GitLabID }) {
+				return 0, false
+			}
+
+			return member.GitLabID, lo.SomeBy(reviewer.Labels, func(label ds.UserLabel) bool { return member.Labels.Has(label) })
+		})
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		picked, err := s.picker.Pick(team, replacementPool(reviewer), mr, candidates, 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to pick replacement in team %s", team.Name)
+		}
+
+		if len(picked) > 0 {
+			return team.Member(picked[0]), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// replacementPool is the selection pool of the replaced reviewer
+func replacementPool(reviewer *ds.User) string {
+	switch {
+	case reviewer.Labels.Has(ds.LeadLabel):
+		return selection.PoolLeads
+	case reviewer.Labels.Has(ds.TraineeLabel):
+		return selection.PoolTrainees
+	}
+
+	return selection.PoolDevelopers
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service/mocks"
+)
+
+func TestParseAbsenceCommand(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2024, 5, 2, 23, 30, 0, 0, loc)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    *ds.Absence
+		wantErr bool
+	}{
+		{name: "today", args: []string{"today"}, want: &ds.Absence{From: day(2), To: day(3)}},
+		{name: "tomorrow with reason", args: []string{"tomorrow", "dentist"}, want: &ds.Absence{From: day(3), To: day(4), Reason: "dentist"}},
+		{
+			name: "period",
+			args: []string{"2024-05-06", "2024-05-10", "family", "vacation"},
+			want: &ds.Absence{From: day(6), To: day(11), Reason: "family vacation"},
+		},
+		{name: "no dates", args: []string{}, wantErr: true},
+		{name: "invalid date", args: []string{"monday"}, wantErr: true},
+		{name: "last day before first", args: []string{"2024-05-10", "2024-05-06"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		absence, err := service.ParseAbsenceCommand(tt.args, now)
+		if tt.wantErr {
+			require.Error(t, err, tt.name)
+			continue
+		}
+
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.want, absence, tt.name)
+	}
+}
+
+func absenceService(t *testing.T, repository *mocks.Repository) *service.Service {
+	repository.EXPECT().
+		Teams().
+		Return([]*ds.Team{{
+			Name:    "backend",
+			Members: []*ds.User{{BasicUser: John, Labels: ds.UserLabels{ds.DeveloperLabel}}},
+		}}, nil).
+		Times(1)
+
+	svc, err := service.New(service.Config{Absence: service.AbsenceConfig{Location: time.UTC, MinEvent: 4 * time.Hour}}, repository, nil, nil, nil, nil, nil)
+	require.NoError(t, err, "service.New() failed")
+
+	return svc
+}
+
+func TestService_HandleAbsenceCommand(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewRepository(ctrl)
+	svc := absenceService(t, repository)
+	user := &ds.User{BasicUser: John}
+
+	repository.EXPECT().
+		AddAbsence(gomock.Any()).
+		DoAndReturn(func(absence *ds.Absence) error {
+			require.Equal(t, John.GitLabID, absence.UserID)
+			require.Equal(t, ds.AbsenceSourceSlack, absence.Source)
+			require.Equal(t, 5*24*time.Hour, absence.To.Sub(absence.From))
+
+			return nil
+		}).
+		Times(1)
+
+	reply, err := svc.HandleAbsenceCommand(user, "2030-05-06 2030-05-10 vacation")
+	require.NoError(t, err)
+	require.Equal(t, "Got it, you are away from 2030-05-06 till 2030-05-10 (vacation).", reply)
+
+	reply, err = svc.HandleAbsenceCommand(user, "2030-05-06 2031-05-10")
+	require.NoError(t, err)
+	require.Contains(t, reply, "must not be longer", "invalid absences are replied, not stored")
+
+	repository.EXPECT().
+		DeleteAbsence(John.GitLabID, "abc").
+		Return(false, nil).
+		Times(1)
+
+	reply, err = svc.HandleAbsenceCommand(user, "cancel abc")
+	require.NoError(t, err)
+	require.Equal(t, "Absence `abc` is not found.", reply)
+}
+
+func TestService_ImportAbsences(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewRepository(ctrl)
+	svc := absenceService(t, repository)
+
+	calendar := []byte("BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:past\r\nSUMMARY:Old trip\r\nDTSTART;VALUE=DATE:20200101\r\nDTEND;VALUE=DATE:20200105\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:trip\r\nSUMMARY:Trip\r\nDTSTART;VALUE=DATE:20300101\r\nDTEND;VALUE=DATE:20300105\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:meeting\r\nSUMMARY:Planning\r\nDTSTART:20300110T100000Z\r\nDTEND:20300110T110000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:afternoon\r\nSUMMARY:Doctor\r\nDTSTART:20300111T120000Z\r\nDTEND:20300111T180000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:cancelled\r\nSTATUS:CANCELLED\r\nDTSTART;VALUE=DATE:20300201\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n")
+
+	repository.EXPECT().
+		ReplaceAbsences(John.GitLabID, ds.AbsenceSourceICS, gomock.Any()).
+		DoAndReturn(func(_ int, _ ds.AbsenceSource, absences []*ds.Absence) error {
+			require.Len(t, absences, 2, "short events are not absences")
+			require.Equal(t, "trip", absences[0].ExternalID)
+			require.Equal(t, time.Date(2030, 1, 5, 0, 0, 0, 0, time.UTC), absences[0].To)
+			require.Equal(t, "afternoon", absences[1].ExternalID)
+
+			return nil
+		}).
+		Times(1)
+
+	imported, err := svc.ImportAbsences(John.GitLabID, calendar)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	_, err = svc.ImportAbsences(Jane.GitLabID, calendar)
+	require.ErrorIs(t, err, service.ErrInvalidAbsence, "only teammates")
+}
@@ -1,18 +1,94 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 )
 
-func ComposeMessageForAI(title string, description string, diffs []*Diff) string {
+// ComposeMessageForAI builds the prompt of the code review: title, description, diffs and optional code context
+func ComposeMessageForAI(title string, description string, diffs []*Diff, contexts ...*FileContext) string {
 	var message strings.Builder
 	message.WriteString(fmt.Sprintf("%s\n%s\n", title, description))
 	for _, diff := range diffs {
-		// ignore go.sum/go.mod
-		if !strings.Contains(diff.OldPath, "go.sum") && !strings.Contains(diff.OldPath, "go.mod") {
+		if !ignoredByAI(diff) {
 			message.WriteString(diff.Content)
 		}
 	}
+
+	// the context goes last, so it is truncated first if the prompt is too long
+	if len(contexts) > 0 {
+		message.WriteString("\n\nCode context of the changes (not changed lines are for reference only):\n")
+	}
+
+	for _, fc := range contexts {
+		kind := "changed file"
+		if fc.Related {
+			kind = "related file"
+		}
+
+		message.WriteString(fmt.Sprintf("\n%s %s, lines %d-%d:\n", kind, fc.Path, fc.FromLine, fc.ToLine))
+
+		for i, line := range strings.Split(fc.Content, "\n") {
+			message.WriteString(fmt.Sprintf("%d: %s\n", fc.FromLine+i, line))
+		}
+	}
+
 	return message.String()
 }
+
+// ignoredByAI filters out diffs which are not worth reviewing (go.sum/go.mod)
+func ignoredByAI(diff *Diff) bool {
+	return strings.Contains(diff.OldPath, "go.sum") || strings.Contains(diff.OldPath, "go.mod")
+}
+
+// hunkHeader matches positions of unified diff hunks, e.g. "@@ -10,7 +10,8 @@"
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -\d+(,\d+)? \+\d+(,\d+)? @@`)
+
+// AIReviewCacheKey returns content address of the AI review input.
+// Only the reviewed diffs and the code context are hashed (title and description are not), so the same change
+// pushed as a commit and included in a merge request, or rebased without content changes, has the same key.
+func AIReviewCacheKey(diffs []*Diff, contexts []*FileContext, promptVersion, model string) string {
+	normalized := make([]string, 0, len(diffs))
+
+	for _, diff := range diffs {
+		if ignoredByAI(diff) {
+			continue
+		}
+
+		normalized = append(normalized, diff.OldPath+"\n"+diff.NewPath+"\n"+normalizeDiffContent(diff.Content))
+	}
+
+	for _, fc := range contexts {
+		normalized = append(normalized, fc.Path+"\n"+normalizeDiffContent(fc.Content))
+	}
+
+	// the order of diffs returned by GitLab is not guaranteed
+	sort.Strings(normalized)
+
+	h := sha256.New()
+	h.Write([]byte(promptVersion + "\n" + model + "\n"))
+
+	for _, diff := range normalized {
+		h.Write([]byte(diff))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeDiffContent drops hunk positions, line endings and trailing whitespaces
+func normalizeDiffContent(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = hunkHeader.ReplaceAllString(content, "@@")
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
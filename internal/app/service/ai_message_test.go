@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIReviewCacheKey(t *testing.T) {
+	t.Parallel()
+
+	diffA := &Diff{
+		OldPath: "main.go",
+		NewPath: "main.go",
+		Content: "@@ -1,3 +1,4 @@\n package main\n+\n+import \"fmt\"\n",
+	}
+	diffB := &Diff{
+		OldPath: "util.go",
+		NewPath: "util.go",
+		Content: "@@ -10,2 +10,3 @@\n func a() {}\n+func b() {}\n",
+	}
+
+	base := AIReviewCacheKey([]*Diff{diffA, diffB}, nil, "1", "gpt-4")
+
+	tests := []struct {
+		name          string
+		diffs         []*Diff
+		contexts      []*FileContext
+		promptVersion string
+		model         string
+		equal         bool
+	}{
+		{
+			name:          "same input",
+			diffs:         []*Diff{diffA, diffB},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         true,
+		},
+		{
+			name:          "different order of diffs",
+			diffs:         []*Diff{diffB, diffA},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         true,
+		},
+		{
+			name: "rebased, hunks are moved",
+			diffs: []*Diff{diffA, {
+				OldPath: "util.go",
+				NewPath: "util.go",
+				Content: "@@ -42,2 +42,3 @@\n func a() {}\n+func b() {}\n",
+			}},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         true,
+		},
+		{
+			name: "line endings and trailing spaces",
+			diffs: []*Diff{diffA, {
+				OldPath: "util.go",
+				NewPath: "util.go",
+				Content: "@@ -10,2 +10,3 @@\r\n func a() {}  \r\n+func b() {}\r\n",
+			}},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         true,
+		},
+		{
+			name: "ignored files",
+			diffs: []*Diff{diffA, diffB, {
+				OldPath: "go.sum",
+				NewPath: "go.sum",
+				Content: "+github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=",
+			}},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         true,
+		},
+		{
+			name: "content changed",
+			diffs: []*Diff{diffA, {
+				OldPath: "util.go",
+				NewPath: "util.go",
+				Content: "@@ -10,2 +10,3 @@\n func a() {}\n+func c() {}\n",
+			}},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         false,
+		},
+		{
+			name:          "with code context",
+			diffs:         []*Diff{diffA, diffB},
+			contexts:      []*FileContext{{Path: "util.go", FromLine: 1, ToLine: 1, Content: "package util"}},
+			promptVersion: "1",
+			model:         "gpt-4",
+			equal:         false,
+		},
+		{
+			name:          "another prompt version",
+			diffs:         []*Diff{diffA, diffB},
+			promptVersion: "2",
+			model:         "gpt-4",
+			equal:         false,
+		},
+		{
+			name:          "another model",
+			diffs:         []*Diff{diffA, diffB},
+			promptVersion: "1",
+			model:         "gpt-3.5-turbo",
+			equal:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := AIReviewCacheKey(tt.diffs, tt.contexts, tt.promptVersion, tt.model)
+			require.Equal(t, tt.equal, actual == base)
+		})
+	}
+}
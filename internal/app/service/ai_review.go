@@ -0,0 +1,64 @@
+package service
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// generateAIReview returns AI review comment for the diffs, reusing cached result if the same diffs
+// were already reviewed with the same prompt version and model
+func (s *Service) generateAIReview(title, description string, diffs []*Diff, contexts []*FileContext) (string, error) {
+	// zero TTL disables the cache
+	if s.cfg.AIReviewCacheTTL <= 0 {
+		return s.callAIReview(title, description, diffs, contexts)
+	}
+
+	key := AIReviewCacheKey(diffs, contexts, s.openai.PromptVersion(), s.openai.Model())
+
+	cached, err := s.r.AIReviewByHash(key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fetch cached ai review")
+	}
+
+	if cached != nil {
+		log.Info().Str("hash", key).Msg("cached ai review reused")
+		return cached.Comment, nil
+	}
+
+	comment, err := s.callAIReview(title, description, diffs, contexts)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	err = s.r.UpsertAIReview(&ds.AIReview{
+		Hash:          key,
+		PromptVersion: s.openai.PromptVersion(),
+		Model:         s.openai.Model(),
+		Comment:       comment,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.cfg.AIReviewCacheTTL),
+	})
+	if err != nil {
+		// the review is already paid, so it is still worth to post it
+		log.Error().Err(err).Str("hash", key).Msg("failed to cache ai review")
+	}
+
+	return comment, nil
+}
+
+func (s *Service) callAIReview(title, description string, diffs []*Diff, contexts []*FileContext) (string, error) {
+	message := ComposeMessageForAI(title, description, diffs, contexts...)
+
+	comment, err := s.openai.GenerateAICodeReviewComment(message)
+	if err != nil {
+		return "", errors.Wrap(err, "call openai failed")
+	}
+
+	return comment, nil
+}
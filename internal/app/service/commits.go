@@ -28,28 +28,69 @@ func (s *Service) commitsHandler(commit *ds.Commit) error {
 		return errors.Wrapf(err, "failed to update commit in repository, id:%s", commit.ID)
 	}
 
+	err = s.reviewCommit(commit)
+	if err != nil {
+		return errors.Wrapf(err, "failed to review commit, id:%s", commit.ID)
+	}
+
+	log.Info().
+		Int("project_id", commit.ProjectID).
+		Str("iid", commit.ID).
+		Str("url", commit.WebURL).
+		Msg("commit reviewed")
+
+	return nil
+}
+
+// reviewCommit runs diff rules and the AI review (if OpenAI is configured) and comments the results
+func (s *Service) reviewCommit(commit *ds.Commit) error {
 	diffs, err := s.gitlab.GetCommitDiff(commit.ProjectID, commit.ID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get diff of commit, project:%d, commit:%s", commit.ProjectID, commit.ID)
 	}
 
-	message := ComposeMessageForAI(commit.Title, commit.Message, diffs)
-	reviewComment, err := s.openai.GenerateAICodeReviewComment(message)
+	findings := s.checkDiffRules(commit.ProjectID, diffs)
+	if len(findings) > 0 {
+		err = s.gitlab.AddCommentToCommit(commit.ProjectID, commit.ID, RuleFindingsComment(findings))
+		if err != nil {
+			log.Error().Err(err).Int("project_id", commit.ProjectID).Str("id", commit.ID).Msg("failed to comment rule findings")
+		}
+	}
+
+	diffs, secrets := s.redactDiffs(commit.ProjectID, diffs)
+	if s.secretsWarningRequired(commit.ProjectID, secrets) {
+		err = s.gitlab.AddCommentToCommit(commit.ProjectID, commit.ID, SecretsWarning(secrets))
+		if err != nil {
+			log.Error().Err(err).Int("project_id", commit.ProjectID).Str("id", commit.ID).Msg("failed to warn about secrets")
+		}
+	}
+
+	if s.openai == nil {
+		return nil
+	}
+
+	contexts := s.reviewContext(commit.ProjectID, commit.ID, diffs)
+
+	reviewComment, err := s.generateAIReview(commit.Title, commit.Message, diffs, contexts)
 	if err != nil {
-		return errors.Wrap(err, "call openai failed")
+		return errors.Wrap(err, "failed to generate ai review")
 	}
 	log.Info().Msg(reviewComment)
 
+	// commits have no suggestions support, valid ones are shown as diffs
+	suggestions, reviewComment := ExtractSuggestions(reviewComment)
+	for _, sg := range s.validSuggestions(commit.ProjectID, commit.ID, diffs, suggestions) {
+		reviewComment += "\n\n" + sg.RenderAsDiff()
+	}
+
+	if reviewComment == "" {
+		return nil
+	}
+
 	err = s.gitlab.AddCommentToCommit(commit.ProjectID, commit.ID, reviewComment)
 	if err != nil {
-		return errors.Wrap(err, "failed to add comment to merge request in repository")
+		return errors.Wrap(err, "failed to add comment to commit")
 	}
 
-	log.Info().
-		Int("project_id", commit.ProjectID).
-		Str("iid", commit.ID).
-		Str("url", commit.WebURL).
-		Msg("AI review comment is added")
-
 	return nil
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Hunk is a position of a changed block in the unified diff
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+}
+
+var hunkPosition = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks returns positions of all hunks in the unified diff content
+func ParseHunks(content string) []Hunk {
+	hunks := make([]Hunk, 0, 1)
+
+	for _, line := range strings.Split(content, "\n") {
+		m := hunkPosition.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		hunks = append(hunks, Hunk{
+			OldStart: atoiOr(m[1], 0),
+			OldLines: atoiOr(m[2], 1),
+			NewStart: atoiOr(m[3], 0),
+			NewLines: atoiOr(m[4], 1),
+		})
+	}
+
+	return hunks
+}
+
+func atoiOr(s string, def int) int {
+	if s == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
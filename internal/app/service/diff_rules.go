@@ -0,0 +1,392 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// RuleFinding is a violation of a diff rule, Line is 0 for findings about the whole change
+type RuleFinding struct {
+	Rule    string
+	Message string
+	Path    string
+	Line    int
+}
+
+// Key identifies the finding among findings of other revisions of the merge request
+func (f RuleFinding) Key() string {
+	return fmt.Sprintf("%s:%s:%d", f.Rule, f.Path, f.Line)
+}
+
+// DiffRules are compiled diff rules of a project
+type DiffRules struct {
+	rules []*diffRule
+}
+
+type diffRule struct {
+	name             string
+	message          string
+	paths            []*glob.Pattern
+	excludePaths     []*glob.Pattern
+	addedLine        *regexp.Regexp
+	addedLineExclude *regexp.Regexp
+	requireChanged   []*glob.Pattern
+}
+
+// NewDiffRules compiles globs and regular expressions of the rules
+func NewDiffRules(rules []ds.DiffRule) (*DiffRules, error) {
+	compiled := make([]*diffRule, 0, len(rules))
+
+	for _, rule := range rules {
+		dr, err := compileDiffRule(rule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rule %q", rule.Name)
+		}
+
+		compiled = append(compiled, dr)
+	}
+
+	return &DiffRules{rules: compiled}, nil
+}
+
+func compileDiffRule(rule ds.DiffRule) (*diffRule, error) {
+	var err error
+
+	if rule.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if rule.AddedLine == "" && len(rule.RequireChanged) == 0 {
+		return nil, errors.New("either added_line or require_changed is required")
+	}
+
+	dr := &diffRule{
+		name:    rule.Name,
+		message: rule.Message,
+	}
+
+	if dr.message == "" {
+		dr.message = fmt.Sprintf("rule `%s` is violated", rule.Name)
+	}
+
+	dr.paths, err = compileGlobs(rule.Paths)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid paths")
+	}
+
+	dr.excludePaths, err = compileGlobs(rule.ExcludePaths)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exclude_paths")
+	}
+
+	dr.requireChanged, err = compileGlobs(rule.RequireChanged)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid require_changed")
+	}
+
+	if rule.AddedLine != "" {
+		dr.addedLine, err = regexp.Compile(rule.AddedLine)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid added_line")
+		}
+	}
+
+	if rule.AddedLineExclude != "" {
+		dr.addedLineExclude, err = regexp.Compile(rule.AddedLineExclude)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid added_line_exclude")
+		}
+	}
+
+	return dr, nil
+}
+
+func compileGlobs(patterns []string) ([]*glob.Pattern, error) {
+	compiled := make([]*glob.Pattern, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		p, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, p)
+	}
+
+	return compiled, nil
+}
+
+func matchAnyGlob(patterns []*glob.Pattern, path string) bool {
+	for _, p := range patterns {
+		if p.Match(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check runs all rules against the diffs, findings are sorted by path and line
+func (r *DiffRules) Check(diffs []*Diff) []RuleFinding {
+	findings := make([]RuleFinding, 0)
+
+	if r == nil {
+		return findings
+	}
+
+	for _, rule := range r.rules {
+		findings = append(findings, rule.check(diffs)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings
+}
+
+func (r *diffRule) applies(path string) bool {
+	if len(r.paths) > 0 && !matchAnyGlob(r.paths, path) {
+		return false
+	}
+
+	return !matchAnyGlob(r.excludePaths, path)
+}
+
+func (r *diffRule) check(diffs []*Diff) []RuleFinding {
+	findings := make([]RuleFinding, 0)
+	matched := make([]string, 0)
+
+	for _, diff := range diffs {
+		path := diffPath(diff)
+		if !r.applies(path) {
+			continue
+		}
+
+		matched = append(matched, path)
+
+		if r.addedLine == nil || diff.DeletedFile {
+			continue
+		}
+
+		for _, line := range addedLines(diff.Content) {
+			if !r.addedLine.MatchString(line.text) {
+				continue
+			}
+
+			if r.addedLineExclude != nil && r.addedLineExclude.MatchString(line.text) {
+				continue
+			}
+
+			findings = append(findings, RuleFinding{
+				Rule:    r.name,
+				Message: r.message,
+				Path:    path,
+				Line:    line.number,
+			})
+		}
+	}
+
+	if len(r.requireChanged) == 0 || len(matched) == 0 {
+		return findings
+	}
+
+	for _, diff := range diffs {
+		if matchAnyGlob(r.requireChanged, diff.NewPath) || matchAnyGlob(r.requireChanged, diff.OldPath) {
+			return findings
+		}
+	}
+
+	sort.Strings(matched)
+
+	return append(findings, RuleFinding{
+		Rule:    r.name,
+		Message: r.message,
+		Path:    matched[0],
+	})
+}
+
+// diffPath is the path of the file in the new revision or the removed path for deleted files
+func diffPath(diff *Diff) string {
+	if diff.DeletedFile || diff.NewPath == "" {
+		return diff.OldPath
+	}
+
+	return diff.NewPath
+}
+
+// oldPathsOf maps paths of the files to their paths before the change
+func oldPathsOf(diffs []*Diff) map[string]string {
+	oldPaths := make(map[string]string, len(diffs))
+	for _, diff := range diffs {
+		oldPaths[diffPath(diff)] = diff.OldPath
+	}
+
+	return oldPaths
+}
+
+type addedLine struct {
+	number int
+	text   string
+}
+
+// addedLines returns added lines of the unified diff with their numbers in the new file
+func addedLines(content string) []addedLine {
+	lines := make([]addedLine, 0)
+	number := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		if hunks := ParseHunks(line); len(hunks) > 0 {
+			number = hunks[0].NewStart
+			continue
+		}
+
+		if number == 0 {
+			// file headers before the first hunk
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, addedLine{number: number, text: line[1:]})
+			number++
+		case strings.HasPrefix(line, " "):
+			number++
+		}
+	}
+
+	return lines
+}
+
+// RuleFindingsComment composes a single note with all findings
+func RuleFindingsComment(findings []RuleFinding) string {
+	var msg strings.Builder
+
+	msg.WriteString(":mag: **Rule checks**\n\n")
+
+	for _, finding := range findings {
+		location := finding.Path
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", finding.Path, finding.Line)
+		}
+
+		msg.WriteString(fmt.Sprintf("- `%s` %s (rule `%s`)\n", location, finding.Message, finding.Rule))
+	}
+
+	return msg.String()
+}
+
+// RuleFindingComment is a comment of a single finding posted on the line
+func RuleFindingComment(finding RuleFinding) string {
+	return fmt.Sprintf(":mag: %s (rule `%s`)", finding.Message, finding.Rule)
+}
+
+// initDiffRules compiles diff rules of the projects
+func (s *Service) initDiffRules(projects []*ds.Project) error {
+	s.diffRules = make(map[int]*DiffRules, len(projects))
+
+	for _, project := range projects {
+		rules, err := NewDiffRules(project.DiffRules.Rules)
+		if err != nil {
+			return errors.Wrapf(err, "invalid diff rules of project %s", project.Name)
+		}
+
+		s.diffRules[project.ID] = rules
+	}
+
+	return nil
+}
+
+// checkDiffRules runs diff rules of the project, nil if the project has no rules
+func (s *Service) checkDiffRules(projectID int, diffs []*Diff) []RuleFinding {
+	rules, ok := s.diffRules[projectID]
+	if !ok || len(rules.rules) == 0 {
+		return nil
+	}
+
+	return rules.Check(diffs)
+}
+
+// newRuleFindings returns findings which are not commented on the merge request yet
+func newRuleFindings(mr *ds.MergeRequest, findings []RuleFinding) []RuleFinding {
+	return lo.Filter(findings, func(finding RuleFinding, _ int) bool {
+		return !lo.Contains(mr.PostedFindings, finding.Key())
+	})
+}
+
+// commentRuleFindingsOnMergeRequest posts line findings on the lines if the project wants it, the rest in a single note.
+// Keys of posted findings are saved, so they are not posted again.
+func (s *Service) commentRuleFindingsOnMergeRequest(mr *ds.MergeRequest, diffs []*Diff, findings []RuleFinding) error {
+	posted := make([]string, 0, len(findings))
+	rest := findings
+
+	project, ok := s.projects[mr.ProjectID]
+	if ok && project.DiffRules.Positioned {
+		oldPaths := oldPathsOf(diffs)
+		rest = make([]RuleFinding, 0)
+
+		for _, finding := range findings {
+			if finding.Line == 0 {
+				rest = append(rest, finding)
+				continue
+			}
+
+			_, err := s.gitlab.AddPositionedCommentToMergeRequest(mr.ProjectID, mr.IID, CommentPosition{
+				Path:    finding.Path,
+				OldPath: oldPaths[finding.Path],
+				Line:    finding.Line,
+			}, RuleFindingComment(finding))
+			if err != nil {
+				// the finding goes to the summary comment instead
+				log.Error().
+					Err(err).
+					Int("project_id", mr.ProjectID).
+					Int("iid", mr.IID).
+					Str("path", finding.Path).
+					Int("line", finding.Line).
+					Msg("failed to comment rule finding")
+
+				rest = append(rest, finding)
+
+				continue
+			}
+
+			posted = append(posted, finding.Key())
+		}
+	}
+
+	var err error
+
+	if len(rest) > 0 {
+		_, err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID, RuleFindingsComment(rest))
+		if err == nil {
+			posted = append(posted, lo.Map(rest, func(finding RuleFinding, _ int) string { return finding.Key() })...)
+		}
+	}
+
+	if len(posted) == 0 {
+		return err
+	}
+
+	saveErr := s.r.AddPostedFindings(mr.ID, posted)
+	if saveErr != nil && err == nil {
+		err = errors.Wrap(saveErr, "failed to save posted findings")
+	}
+
+	mr.PostedFindings = append(mr.PostedFindings, posted...)
+
+	return err
+}
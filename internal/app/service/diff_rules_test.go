@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestDiffRules_Check(t *testing.T) {
+	t.Parallel()
+
+	rules, err := NewDiffRules([]ds.DiffRule{
+		{
+			Name:             "todo",
+			Message:          "TODO without a ticket",
+			Paths:            []string{"*.go"},
+			AddedLine:        `TODO`,
+			AddedLineExclude: `TODO\([A-Z]+-\d+\)`,
+		},
+		{
+			Name:         "println",
+			Message:      "fmt.Println in production code",
+			Paths:        []string{"*.go"},
+			ExcludePaths: []string{"*_test.go", "cmd/**"},
+			AddedLine:    `fmt\.Println\(`,
+		},
+		{
+			Name:           "rollback",
+			Message:        "migration without a rollback",
+			Paths:          []string{"migrations/*.up.sql"},
+			RequireChanged: []string{"migrations/*.down.sql"},
+		},
+		{
+			Name:           "config-docs",
+			Message:        "config changed without README",
+			Paths:          []string{"config/config.dist.yml"},
+			RequireChanged: []string{"README.md"},
+		},
+	})
+	require.NoError(t, err)
+
+	diffs := []*Diff{
+		{
+			NewPath: "internal/app/service/service.go",
+			OldPath: "internal/app/service/service.go",
+			Content: "@@ -10,3 +10,5 @@ func New() {\n" +
+				" \ta := 1\n" +
+				"-\tb := 2\n" +
+				"+\t// TODO: remove\n" +
+				"+\t// TODO(BOT-12): fine\n" +
+				" \tc := 3\n" +
+				"+\tfmt.Println(a)\n" +
+				"@@ -40,2 +42,3 @@\n" +
+				" \treturn\n" +
+				"+\t// TODO later\n",
+		},
+		{
+			NewPath: "internal/app/service/service_test.go",
+			OldPath: "internal/app/service/service_test.go",
+			Content: "@@ -1,1 +1,2 @@\n package service\n+fmt.Println(1)\n",
+		},
+		{
+			NewPath: "migrations/0002_users.up.sql",
+			NewFile: true,
+			Content: "@@ -0,0 +1 @@\n+CREATE TABLE users();\n",
+		},
+		{
+			NewPath: "config/config.dist.yml",
+			OldPath: "config/config.dist.yml",
+			Content: "@@ -1 +1 @@\n-a: 1\n+a: 2\n",
+		},
+		{
+			NewPath: "README.md",
+			OldPath: "README.md",
+			Content: "@@ -1 +1 @@\n-a\n+b\n",
+		},
+	}
+
+	require.Equal(t, []RuleFinding{
+		{Rule: "todo", Message: "TODO without a ticket", Path: "internal/app/service/service.go", Line: 11},
+		{Rule: "println", Message: "fmt.Println in production code", Path: "internal/app/service/service.go", Line: 14},
+		{Rule: "todo", Message: "TODO without a ticket", Path: "internal/app/service/service.go", Line: 43},
+		{Rule: "rollback", Message: "migration without a rollback", Path: "migrations/0002_users.up.sql"},
+	}, rules.Check(diffs))
+}
+
+func TestNewDiffRules_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, rule := range map[string]ds.DiffRule{
+		"no name":        {AddedLine: "TODO"},
+		"no condition":   {Name: "empty", Paths: []string{"*.go"}},
+		"invalid regexp": {Name: "regexp", AddedLine: "TODO("},
+	} {
+		_, err := NewDiffRules([]ds.DiffRule{rule})
+		require.Error(t, err, name)
+	}
+}
+
+func TestRuleFindingsComment(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, ":mag: **Rule checks**\n\n"+
+		"- `main.go:3` TODO without a ticket (rule `todo`)\n"+
+		"- `migrations/1.up.sql` migration without a rollback (rule `rollback`)\n",
+		RuleFindingsComment([]RuleFinding{
+			{Rule: "todo", Message: "TODO without a ticket", Path: "main.go", Line: 3},
+			{Rule: "rollback", Message: "migration without a rollback", Path: "migrations/1.up.sql"},
+		}))
+}
+
+func TestCommentRuleFindingsOnMergeRequest(t *testing.T) {
+	t.Parallel()
+
+	g := &gitlabStub{failLines: []int{3}}
+	r := &repositoryStub{mrs: map[int]*ds.MergeRequest{5: {ID: 5}}}
+	s := &Service{
+		r:        r,
+		gitlab:   g,
+		projects: map[int]*ds.Project{1: {ID: 1, DiffRules: ds.DiffRulesSettings{Positioned: true}}},
+	}
+
+	findings := []RuleFinding{
+		{Rule: "todo", Message: "TODO without a ticket", Path: "main.go", Line: 3},
+		{Rule: "todo", Message: "TODO without a ticket", Path: "main.go", Line: 7},
+	}
+
+	mr := &ds.MergeRequest{ID: 5, ProjectID: 1, IID: 2}
+
+	err := s.commentRuleFindingsOnMergeRequest(mr, nil, findings)
+	require.NoError(t, err)
+
+	require.Len(t, g.positioned, 1, "the rest of findings are commented after a failure")
+	require.Equal(t, 7, g.positioned[0].Line)
+	require.Equal(t, []string{RuleFindingsComment(findings[:1])}, g.comments, "the failed finding is in the summary")
+	require.ElementsMatch(t, []string{"todo:main.go:3", "todo:main.go:7"}, r.mrs[5].PostedFindings)
+	require.Empty(t, newRuleFindings(mr, findings), "posted findings are not posted again")
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// DryRunReport compares decisions of a dry-run policy on the merge request with what happened
+type DryRunReport struct {
+	Policy    ds.PolicyName
+	ProjectID int
+	IID       int
+	// State is the actual state of the merge request, empty if it is not stored
+	State ds.State
+	// WouldReview are reviewers the policy would set last time, Reviewers are actual ones
+	WouldReview []int
+	Reviewers   []int
+	// WouldApproveAt is the last transition to approved, nil if the policy wouldn't approve
+	WouldApproveAt *time.Time
+	WouldDo        []ds.ActionType
+	Approves       []int
+}
+
+// Matched returns how many reviewers the policy would set are actual reviewers
+func (r *DryRunReport) Matched() int {
+	return len(lo.Intersect(r.WouldReview, r.Reviewers))
+}
+
+// BuildDryRunReport groups decisions by policies and merge requests, mrs are actual merge requests by ID
+func BuildDryRunReport(decisions []*ds.Decision, mrs map[int]*ds.MergeRequest) []*DryRunReport {
+	type key struct {
+		policy ds.PolicyName
+		mrID   int
+	}
+
+	reports := make(map[key]*DryRunReport)
+
+	// the oldest first, the last decision wins
+	for _, decision := range decisions {
+		k := key{policy: decision.Policy, mrID: decision.MergeRequestID}
+
+		report, ok := reports[k]
+		if !ok {
+			report = &DryRunReport{
+				Policy:    decision.Policy,
+				ProjectID: decision.ProjectID,
+				IID:       decision.IID,
+			}
+
+			if mr, ok := mrs[decision.MergeRequestID]; ok {
+				report.State = mr.State
+				report.Reviewers = userIDs(mr.Reviewers)
+				report.Approves = userIDs(mr.Approves)
+			}
+
+			reports[k] = report
+		}
+
+		switch decision.Kind {
+		case ds.DecisionReviewers:
+			report.WouldReview = decision.Reviewers
+		case ds.DecisionApproved:
+			report.WouldApproveAt = lo.ToPtr(decision.CreatedAt)
+			report.WouldDo = lo.Map(decision.Actions, func(action ds.Action, _ int) ds.ActionType { return action.Type })
+		}
+	}
+
+	res := lo.Values(reports)
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Policy != res[j].Policy {
+			return res[i].Policy < res[j].Policy
+		}
+
+		if res[i].ProjectID != res[j].ProjectID {
+			return res[i].ProjectID < res[j].ProjectID
+		}
+
+		return res[i].IID < res[j].IID
+	})
+
+	return res
+}
+
+func userIDs(users []*ds.BasicUser) []int {
+	return lo.Map(users, func(user *ds.BasicUser, _ int) int { return user.GitLabID })
+}
+
+// FormatDryRunReport renders reports as a plain text list
+func FormatDryRunReport(reports []*DryRunReport) string {
+	if len(reports) == 0 {
+		return "no decisions\n"
+	}
+
+	ints := func(ids []int) string {
+		if len(ids) == 0 {
+			return "-"
+		}
+
+		return strings.Join(lo.Map(ids, func(id int, _ int) string { return fmt.Sprint(id) }), ", ")
+	}
+
+	var out strings.Builder
+
+	for _, report := range reports {
+		state := string(report.State)
+		if state == "" {
+			state = "unknown"
+		}
+
+		out.WriteString(fmt.Sprintf("%s project %d !%d %s\n", report.Policy, report.ProjectID, report.IID, state))
+
+		if report.WouldReview != nil {
+			out.WriteString(fmt.Sprintf("  reviewers: would %s, actual %s, matched %d/%d\n",
+				ints(report.WouldReview), ints(report.Reviewers), report.Matched(), len(report.WouldReview)))
+		}
+
+		approved := "no"
+		if report.WouldApproveAt != nil {
+			approved = report.WouldApproveAt.Format(time.RFC3339)
+
+			if len(report.WouldDo) > 0 {
+				types := lo.Map(report.WouldDo, func(t ds.ActionType, _ int) string { return string(t) })
+				approved += " (" + strings.Join(types, ", ") + ")"
+			}
+		}
+
+		out.WriteString(fmt.Sprintf("  approved: would %s, actual approves %s\n", approved, ints(report.Approves)))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestBuildDryRunReport(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	decisions := []*ds.Decision{
+		{Policy: "rd", MergeRequestID: 1, ProjectID: 5, IID: 12, Kind: ds.DecisionReviewers, Reviewers: []int{2, 3}, CreatedAt: ts},
+		{Policy: "rd", MergeRequestID: 1, ProjectID: 5, IID: 12, Kind: ds.DecisionApproved, CreatedAt: ts.Add(time.Hour),
+			Actions: []ds.Action{{Type: ds.ActionAddLabels, Labels: []string{"approved"}}}},
+		{Policy: "rd", MergeRequestID: 2, ProjectID: 5, IID: 13, Kind: ds.DecisionReviewers, Reviewers: []int{4}, CreatedAt: ts},
+	}
+
+	mrs := map[int]*ds.MergeRequest{
+		1: {ID: 1, State: ds.StateMerged, Reviewers: []*ds.BasicUser{{GitLabID: 2}, {GitLabID: 7}}, Approves: []*ds.BasicUser{{GitLabID: 2}}},
+	}
+
+	reports := BuildDryRunReport(decisions, mrs)
+	require.Len(t, reports, 2)
+	require.Equal(t, 1, reports[0].Matched())
+	require.Equal(t, []ds.ActionType{ds.ActionAddLabels}, reports[0].WouldDo)
+
+	require.Equal(t, `rd project 5 !12 merged
+  reviewers: would 2, 3, actual 2, 7, matched 1/2
+  approved: would 2024-05-06T11:00:00Z (add_labels), actual approves 2
+rd project 5 !13 unknown
+  reviewers: would 4, actual -, matched 0/1
+  approved: would no, actual approves -
+`, FormatDryRunReport(reports))
+}
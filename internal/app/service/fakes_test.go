@@ -0,0 +1,141 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// gitlabStub records comments, methods which are not overridden panic
+type gitlabStub struct {
+	GitlabClient
+
+	diffs     []*Diff
+	diffErr   error
+	diffCalls int
+
+	// failLines are lines positioned comments fail on
+	failLines  []int
+	positioned []CommentPosition
+	comments   []string
+
+	notes        []*ds.Note
+	versions     []*ds.MergeRequestVersion
+	versionCalls int
+	versionDiffs map[int][]*Diff
+	// reviewers are lists of reviewers set
+	reviewers [][]int
+}
+
+func (g *gitlabStub) GetMergeRequestDiff(int, int) ([]*Diff, error) {
+	g.diffCalls++
+	return g.diffs, g.diffErr
+}
+
+func (g *gitlabStub) MergeRequestApproves(int, int) ([]*ds.BasicUser, error) {
+	return nil, nil
+}
+
+func (g *gitlabStub) AddCommentToMergeRequests(_ int, _ int, comment string) (*Discussion, error) {
+	g.comments = append(g.comments, comment)
+	return &Discussion{}, nil
+}
+
+func (g *gitlabStub) MergeRequestNotes(int, int) ([]*ds.Note, error) {
+	return g.notes, nil
+}
+
+func (g *gitlabStub) MergeRequestVersions(int, int) ([]*ds.MergeRequestVersion, error) {
+	g.versionCalls++
+	return g.versions, nil
+}
+
+func (g *gitlabStub) MergeRequestVersionDiff(_ int, _ int, versionID int) ([]*Diff, error) {
+	return g.versionDiffs[versionID], nil
+}
+
+func (g *gitlabStub) SetReviewers(_ *ds.MergeRequest, reviewers []int) error {
+	g.reviewers = append(g.reviewers, reviewers)
+	return nil
+}
+
+func (g *gitlabStub) AddPositionedCommentToMergeRequest(_ int, _ int, position CommentPosition, _ string) (*Discussion, error) {
+	for _, line := range g.failLines {
+		if line == position.Line {
+			return nil, errors.New("line is not in the diff")
+		}
+	}
+
+	g.positioned = append(g.positioned, position)
+
+	return &Discussion{}, nil
+}
+
+// repositoryStub keeps merge requests in memory, methods which are not overridden panic
+type repositoryStub struct {
+	Repository
+
+	mrs map[int]*ds.MergeRequest
+}
+
+func (r *repositoryStub) MergeRequestByID(id int) (*ds.MergeRequest, error) {
+	return r.mrs[id], nil
+}
+
+func (r *repositoryStub) UpsertMergeRequest(mr *ds.MergeRequest) error {
+	saved := *mr
+	r.mrs[mr.ID] = &saved
+
+	return nil
+}
+
+func (r *repositoryStub) MarkMergeRequestReviewed(mrID int, sha string) error {
+	if mr, ok := r.mrs[mrID]; ok {
+		mr.ReviewedSHA = sha
+	}
+
+	return nil
+}
+
+func (r *repositoryStub) AddPostedFindings(mrID int, keys []string) error {
+	if mr, ok := r.mrs[mrID]; ok {
+		mr.PostedFindings = append(append([]string{}, mr.PostedFindings...), keys...)
+	}
+
+	return nil
+}
+
+// policyStub counts processed merge requests
+type policyStub struct {
+	processed int
+}
+
+func (p *policyStub) ProcessChanges(*ds.Team, *ds.MergeRequest) error {
+	p.processed++
+	return nil
+}
+
+func (p *policyStub) ApprovedByUser(*ds.Team, *ds.MergeRequest, ...*ds.BasicUser) bool {
+	return false
+}
+
+func (p *policyStub) ApprovedByPolicy(*ds.Team, *ds.MergeRequest) bool {
+	return false
+}
+
+// pickerStub picks the first candidates and records pools of picks
+type pickerStub struct {
+	pools      []string
+	candidates [][]int
+}
+
+func (p *pickerStub) Pick(_ *ds.Team, pool string, _ *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	p.pools = append(p.pools, pool)
+	p.candidates = append(p.candidates, candidates)
+
+	if n < len(candidates) {
+		candidates = candidates[:n]
+	}
+
+	return candidates, nil
+}
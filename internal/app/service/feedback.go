@@ -0,0 +1,270 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// FeedbackConfig controls polling of award emoji and replies on AI comments
+type FeedbackConfig struct {
+	// PollPeriod how often feedback is polled, 0 disables collection
+	PollPeriod time.Duration
+	// Window is the age of comments whose feedback is polled and used for suppression
+	Window time.Duration
+	// SuppressMinVotes is the minimal votes of a category to be suppressed, 0 disables suppression
+	SuppressMinVotes int
+	// SuppressDownRatio is the share of 👎 in votes to suppress the category
+	SuppressDownRatio float64
+}
+
+// CategoryReview is the category of the general AI review note
+const CategoryReview = "review"
+
+// findingCategories are checked in order, the first matched category wins
+var findingCategories = []struct {
+	name    string
+	keyword *regexp.Regexp
+}{
+	{name: "concurrency", keyword: regexp.MustCompile(`(?i)\b(race|mutex|goroutine|concurren\w*|deadlock|lock|atomic)\b`)},
+	{name: "error_handling", keyword: regexp.MustCompile(`(?i)\b(errors?|err|panic|wrap\w*)\b`)},
+	{name: "naming", keyword: regexp.MustCompile(`(?i)\b(nam(e|es|ing)|renam\w*|identifier)\b`)},
+	{name: "duplication", keyword: regexp.MustCompile(`(?i)\b(duplicat\w*|repeated|reuse)\b`)},
+	{name: "complexity", keyword: regexp.MustCompile(`(?i)\b(complex\w*|nested|simplif\w*|extract)\b`)},
+	{name: "logic", keyword: regexp.MustCompile(`(?i)\b(bug|incorrect|wrong|off-by-one|nil|logic|condition)\b`)},
+	{name: "style", keyword: regexp.MustCompile(`(?i)\b(style|format\w*|comment|lint|indent\w*|unused)\b`)},
+}
+
+// FindingCategory guesses the category of the AI finding by its text
+func FindingCategory(text string) string {
+	for _, category := range findingCategories {
+		if category.keyword.MatchString(text) {
+			return category.name
+		}
+	}
+
+	return "other"
+}
+
+func (s *Service) initFeedback() {
+	if s.cfg.AIFeedback.PollPeriod <= 0 {
+		return
+	}
+
+	s.cron.Schedule(cron.Every(s.cfg.AIFeedback.PollPeriod), cron.FuncJob(s.collectAIFeedback))
+}
+
+// categorySuppressed checks if AI comments of the category are not posted anymore
+func (s *Service) categorySuppressed(category string) bool {
+	s.suppressedMu.RLock()
+	defer s.suppressedMu.RUnlock()
+
+	return s.suppressed[category]
+}
+
+// saveAIComment remembers the discussion to poll its feedback later
+func (s *Service) saveAIComment(mr *ds.MergeRequest, discussion *Discussion, category string) {
+	if discussion == nil || s.cfg.AIFeedback.PollPeriod <= 0 {
+		return
+	}
+
+	comment := &ds.AIComment{
+		DiscussionID:    discussion.ID,
+		NoteID:          discussion.NoteID,
+		ProjectID:       mr.ProjectID,
+		MergeRequestIID: mr.IID,
+		Category:        category,
+		PromptVersion:   s.openai.PromptVersion(),
+		Model:           s.openai.Model(),
+		CreatedAt:       time.Now(),
+	}
+
+	if mr.Author != nil {
+		comment.AuthorID = mr.Author.GitLabID
+	}
+
+	err := s.r.UpsertAIComment(comment)
+	if err != nil {
+		log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to save ai comment")
+	}
+}
+
+// collectAIFeedback polls feedback of recent AI comments and refreshes suppressed categories
+func (s *Service) collectAIFeedback() {
+	comments, err := s.r.AICommentsCreatedAfter(time.Now().Add(-s.cfg.AIFeedback.Window))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch ai comments")
+		return
+	}
+
+	for _, comment := range comments {
+		feedback, err := s.gitlab.MergeRequestNoteFeedback(comment.ProjectID, comment.MergeRequestIID, comment.DiscussionID, comment.NoteID)
+		if err != nil {
+			log.Warn().Err(err).Int("project_id", comment.ProjectID).Int("note_id", comment.NoteID).Msg("failed to fetch ai comment feedback")
+			continue
+		}
+
+		feedback.CheckedAt = time.Now()
+		comment.Feedback = *feedback
+
+		err = s.r.UpsertAIComment(comment)
+		if err != nil {
+			log.Error().Err(err).Int("note_id", comment.NoteID).Msg("failed to save ai comment feedback")
+		}
+	}
+
+	if s.cfg.AIFeedback.SuppressMinVotes <= 0 || s.openai == nil {
+		return
+	}
+
+	suppressed := SuppressedCategories(comments, s.openai.PromptVersion(), s.openai.Model(),
+		s.cfg.AIFeedback.SuppressMinVotes, s.cfg.AIFeedback.SuppressDownRatio)
+
+	if len(suppressed) > 0 {
+		log.Info().Strs("categories", lo.Keys(suppressed)).Msg("ai comment categories suppressed")
+	}
+
+	s.suppressedMu.Lock()
+	s.suppressed = suppressed
+	s.suppressedMu.Unlock()
+}
+
+// SuppressedCategories returns categories consistently downvoted with the current prompt version and model,
+// a new prompt version gives every category a fresh chance
+func SuppressedCategories(comments []*ds.AIComment, promptVersion, model string, minVotes int, downRatio float64) map[string]bool {
+	stats := make(map[string]*FeedbackStats)
+
+	for _, comment := range comments {
+		if comment.PromptVersion != promptVersion || comment.Model != model {
+			continue
+		}
+
+		st, ok := stats[comment.Category]
+		if !ok {
+			st = &FeedbackStats{Category: comment.Category}
+			stats[comment.Category] = st
+		}
+
+		st.add(comment)
+	}
+
+	suppressed := make(map[string]bool)
+
+	for category, st := range stats {
+		votes := st.Up + st.Down
+		if votes >= minVotes && float64(st.Down)/float64(votes) >= downRatio {
+			suppressed[category] = true
+		}
+	}
+
+	return suppressed
+}
+
+// FeedbackStats is the feedback of AI comments of one category
+type FeedbackStats struct {
+	Category string
+	Comments int
+	Up       int
+	Down     int
+	Replies  int
+}
+
+func (st *FeedbackStats) add(comment *ds.AIComment) {
+	st.Comments++
+	st.Up += comment.Feedback.Up
+	st.Down += comment.Feedback.Down
+	st.Replies += comment.Feedback.Replies
+}
+
+// Usefulness is the share of 👍 in votes, -1 if there are no votes
+func (st *FeedbackStats) Usefulness() float64 {
+	votes := st.Up + st.Down
+	if votes == 0 {
+		return -1
+	}
+
+	return float64(st.Up) / float64(votes)
+}
+
+// TeamFeedbackReport is the feedback of AI comments on merge requests authored by the team members
+type TeamFeedbackReport struct {
+	Team       string
+	Categories []*FeedbackStats
+}
+
+// BuildFeedbackReport groups comments by teams of the merge request authors and by categories
+func BuildFeedbackReport(teams []*ds.Team, comments []*ds.AIComment) []*TeamFeedbackReport {
+	reports := make([]*TeamFeedbackReport, 0, len(teams))
+
+	for _, team := range teams {
+		members := make(map[int]bool, len(team.Members))
+		for _, member := range team.Members {
+			if member.BasicUser != nil {
+				members[member.GitLabID] = true
+			}
+		}
+
+		stats := make(map[string]*FeedbackStats)
+
+		for _, comment := range comments {
+			if !members[comment.AuthorID] {
+				continue
+			}
+
+			st, ok := stats[comment.Category]
+			if !ok {
+				st = &FeedbackStats{Category: comment.Category}
+				stats[comment.Category] = st
+			}
+
+			st.add(comment)
+		}
+
+		categories := lo.Values(stats)
+		sort.Slice(categories, func(i, j int) bool {
+			return categories[i].Category < categories[j].Category
+		})
+
+		reports = append(reports, &TeamFeedbackReport{
+			Team:       team.Name,
+			Categories: categories,
+		})
+	}
+
+	return reports
+}
+
+// FormatFeedbackReport renders reports as a plain text table
+func FormatFeedbackReport(reports []*TeamFeedbackReport) string {
+	var out strings.Builder
+
+	for _, report := range reports {
+		out.WriteString(report.Team)
+		out.WriteString("\n")
+
+		if len(report.Categories) == 0 {
+			out.WriteString("  no ai comments\n")
+			continue
+		}
+
+		for _, st := range report.Categories {
+			usefulness := "n/a"
+			if u := st.Usefulness(); u >= 0 {
+				usefulness = fmt.Sprintf("%.0f%%", u*100)
+			}
+
+			out.WriteString(fmt.Sprintf("  %-16s comments: %3d  👍 %3d  👎 %3d  replies: %3d  useful: %s\n",
+				st.Category, st.Comments, st.Up, st.Down, st.Replies, usefulness))
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestFindingCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Wrap the error to keep the context":        "error_handling",
+		"Possible data race on the map":             "concurrency",
+		"Rename the variable to follow conventions": "naming",
+		"This block is duplicated in two handlers":  "duplication",
+		"Looks fine": "other",
+	}
+
+	for text, want := range tests {
+		require.Equal(t, want, FindingCategory(text), text)
+	}
+}
+
+func TestSuppressedCategories(t *testing.T) {
+	t.Parallel()
+
+	comment := func(category, version string, up, down int) *ds.AIComment {
+		return &ds.AIComment{
+			Category:      category,
+			PromptVersion: version,
+			Model:         "gpt-4",
+			Feedback:      ds.AIFeedback{Up: up, Down: down},
+		}
+	}
+
+	comments := []*ds.AIComment{
+		comment("naming", "2", 0, 3),
+		comment("naming", "2", 1, 2),
+		comment("review", "2", 4, 1),
+		comment("style", "2", 0, 1),
+		// downvotes of the previous prompt are not counted
+		comment("review", "1", 0, 10),
+	}
+
+	require.Equal(t, map[string]bool{"naming": true}, SuppressedCategories(comments, "2", "gpt-4", 3, 0.7))
+	require.Empty(t, SuppressedCategories(comments, "3", "gpt-4", 3, 0.7))
+}
+
+func TestBuildFeedbackReport(t *testing.T) {
+	t.Parallel()
+
+	teams := []*ds.Team{
+		{Name: "backend", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 1}}, {BasicUser: &ds.BasicUser{GitLabID: 2}}}},
+		{Name: "frontend", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 3}}}},
+	}
+
+	comments := []*ds.AIComment{
+		{AuthorID: 1, Category: "review", Feedback: ds.AIFeedback{Up: 2, Down: 1, Replies: 1}},
+		{AuthorID: 2, Category: "review", Feedback: ds.AIFeedback{Up: 1}},
+		{AuthorID: 2, Category: "naming"},
+		{AuthorID: 4, Category: "review", Feedback: ds.AIFeedback{Down: 5}},
+	}
+
+	reports := BuildFeedbackReport(teams, comments)
+
+	require.Equal(t, []*TeamFeedbackReport{
+		{Team: "backend", Categories: []*FeedbackStats{
+			{Category: "naming", Comments: 1},
+			{Category: "review", Comments: 2, Up: 3, Down: 1, Replies: 1},
+		}},
+		{Team: "frontend", Categories: []*FeedbackStats{}},
+	}, reports)
+
+	require.Equal(t, 0.75, reports[0].Categories[1].Usefulness())
+	require.Equal(t, float64(-1), reports[0].Categories[0].Usefulness())
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"time"
+
 	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
 func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
@@ -13,8 +16,14 @@ func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
 		return errors.Wrap(err, "failed to fetch merge request from repository")
 	}
 
-	// if no changes, do nothing
-	if old != nil && old.IsEqual(mr) {
+	// reviewed revisions and posted findings are saved by the review
+	if old != nil {
+		mr.ReviewedSHA = old.ReviewedSHA
+		mr.PostedFindings = old.PostedFindings
+	}
+
+	// if no changes, do nothing, unless the review of the revision failed
+	if old != nil && old.IsEqual(mr) && !reviewNeeded(mr) {
 		log.Debug().
 			Int("project_id", mr.ProjectID).
 			Int("iid", mr.IID).
@@ -28,7 +37,21 @@ func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
 		return errors.Wrap(err, "failed to fetch merge request approves")
 	}
 
-	mr.Approves = approves
+	now := time.Now()
+
+	// load of reviewers counts reviews by the time they were assigned
+	mr.Assignments = syncAssignments(old, mr, now)
+
+	// approves given before big changes are not counted
+	mr.Approves = s.trackApprovals(old, mr, approves, now)
+
+	// keep the risk of the stored revision, it is reclassified on new changes
+	if old != nil && mr.Risk == nil {
+		mr.Risk = old.Risk
+	}
+
+	// teams owning touched paths review the merge request as well
+	s.routeMergeRequest(old, mr)
 
 	// update (or create) it
 	err = s.r.UpsertMergeRequest(mr)
@@ -36,22 +59,20 @@ func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
 		return errors.Wrap(err, "failed to update merge request in repository")
 	}
 
-	diff, err := s.gitlab.GetMergeRequestDiff(mr.ProjectID, mr.IID)
-	if err != nil {
-		return errors.Wrap(err, "failed to get diff of merge request")
-	}
-	log.Info().Msgf("generating review comment for: %s", mr.Title)
-
-	message := ComposeMessageForAI(mr.Title, mr.Description, diff)
-	reviewComment, err := s.openai.GenerateAICodeReviewComment(message)
-	if err != nil {
-		return errors.Wrap(err, "call openai failed")
-	}
-	log.Debug().Msg(reviewComment)
+	// review only new revisions, a failed review must not block reviewers assignment
+	if reviewNeeded(mr) {
+		err = s.reviewMergeRequest(mr)
+		if err == nil {
+			err = s.r.MarkMergeRequestReviewed(mr.ID, mr.SHA)
+		}
 
-	err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID, reviewComment)
-	if err != nil {
-		return errors.Wrap(err, "failed to add comment to merge request in repository")
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int("project_id", mr.ProjectID).
+				Int("iid", mr.IID).
+				Msg("failed to review merge request")
+		}
 	}
 
 	log.Info().
@@ -60,28 +81,39 @@ func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
 		Str("url", mr.URL).
 		Msg("mr updated or created")
 
+	// actions of teams wait for approves of other involved teams
+	if len(mr.InvolvedTeams) > 0 {
+		mr.PendingTeams = s.pendingTeams(mr)
+	}
+
 	// process MR
-	for _, team := range s.teams {
+	for _, team := range s.currentTeams() {
 		if mr.CreatedAt != nil && mr.CreatedAt.Before(team.CreatedAt) {
 			log.Info().Str("team_id", team.ID).Msg("skip team, mr created before team")
 			continue
 		}
 
-		policy, ok := s.policies[team.Policy]
+		// branches of the merge request may have another policy or settings
+		routed := team.ForMergeRequest(mr)
+		if routed.Policy == ds.PolicyNone {
+			continue
+		}
+
+		policy, ok := s.policies[s.processingPolicy(routed)]
 		if !ok {
 			log.Error().
 				Str("team", team.Name).
-				Str("policy", string(team.Policy)).
+				Str("policy", string(routed.Policy)).
 				Msg("failed to process updates unknown policy")
 			continue
 		}
 
-		err = policy.ProcessChanges(team, mr)
+		err = policy.ProcessChanges(routed, mr)
 		if err != nil {
 			log.Error().
 				Err(err).
 				Str("team", team.Name).
-				Str("policy", string(team.Policy)).
+				Str("policy", string(routed.Policy)).
 				Msg("failed to process merge request")
 		}
 
@@ -89,3 +121,130 @@ func (s *Service) mergeRequestsHandler(mr *ds.MergeRequest) error {
 
 	return nil
 }
+
+// dryRun checks if the policy of the team only records its decisions
+func (s *Service) dryRun(team *ds.Team) bool {
+	return team.DryRun || lo.Contains(s.cfg.DryRunPolicies, team.Policy)
+}
+
+// processingPolicy returns the name of the policy processing changes of the team,
+// in dry-run mode the policy doesn't call GitLab
+func (s *Service) processingPolicy(team *ds.Team) ds.PolicyName {
+	if s.dryRun(team) {
+		return team.Policy.DryRun()
+	}
+
+	return team.Policy
+}
+
+// teamPolicy returns the team routed by branches of the merge request with the policy processing it,
+// false if no policy reviews the merge request
+func (s *Service) teamPolicy(team *ds.Team, mr *ds.MergeRequest) (*ds.Team, Policy, bool) {
+	routed := team.ForMergeRequest(mr)
+
+	policy, ok := s.policies[s.processingPolicy(routed)]
+
+	return routed, policy, ok
+}
+
+// syncAssignments keeps assignment times of still assigned reviewers, new reviewers are assigned now
+func syncAssignments(old, mr *ds.MergeRequest, now time.Time) []*ds.Assignment {
+	res := make([]*ds.Assignment, 0, len(mr.Reviewers))
+
+	for _, reviewer := range mr.Reviewers {
+		at := now
+
+		if old != nil {
+			if assigned := old.AssignedAt(reviewer.GitLabID); assigned != nil {
+				at = *assigned
+			}
+		}
+
+		res = append(res, &ds.Assignment{UserID: reviewer.GitLabID, AssignedAt: at})
+	}
+
+	return res
+}
+
+// reviewNeeded checks if the revision of the merge request is not reviewed successfully yet,
+// changes of the title, labels or reviewers are not reviewed again
+func reviewNeeded(mr *ds.MergeRequest) bool {
+	return mr.ReviewedSHA != mr.SHA
+}
+
+// reviewMergeRequest runs diff rules and the AI review (if OpenAI is configured) and comments the results
+func (s *Service) reviewMergeRequest(mr *ds.MergeRequest) error {
+	diff, err := s.gitlab.GetMergeRequestDiff(mr.ProjectID, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get diff of merge request")
+	}
+
+	findings := newRuleFindings(mr, s.checkDiffRules(mr.ProjectID, diff))
+	if len(findings) > 0 {
+		err = s.commentRuleFindingsOnMergeRequest(mr, diff, findings)
+		if err != nil {
+			return errors.Wrap(err, "failed to comment rule findings")
+		}
+	}
+
+	diff, secrets := s.redactDiffs(mr.ProjectID, diff)
+	if s.secretsWarningRequired(mr.ProjectID, secrets) {
+		_, err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID, SecretsWarning(secrets))
+		if err != nil {
+			log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to warn about secrets")
+		}
+	}
+
+	err = s.classifyRisk(mr, diff)
+	if err != nil {
+		log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to classify risk")
+	}
+
+	if s.openai == nil {
+		return nil
+	}
+
+	log.Info().Msgf("generating review comment for: %s", mr.Title)
+
+	contexts := s.reviewContext(mr.ProjectID, mr.SHA, diff)
+
+	reviewComment, err := s.generateAIReview(mr.Title, mr.Description, diff, contexts)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate ai review")
+	}
+	log.Debug().Msg(reviewComment)
+
+	suggestions, reviewComment := ExtractSuggestions(reviewComment)
+	suggestions = s.validSuggestions(mr.ProjectID, mr.SHA, diff, suggestions)
+
+	if reviewComment != "" && !s.categorySuppressed(CategoryReview) {
+		discussion, err := s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID, reviewComment)
+		if err != nil {
+			return errors.Wrap(err, "failed to add comment to merge request in repository")
+		}
+
+		s.saveAIComment(mr, discussion, CategoryReview)
+	}
+
+	oldPaths := oldPathsOf(diff)
+	for _, sg := range suggestions {
+		category := FindingCategory(sg.Comment)
+		if s.categorySuppressed(category) {
+			continue
+		}
+
+		discussion, err := s.gitlab.AddPositionedCommentToMergeRequest(mr.ProjectID, mr.IID, CommentPosition{
+			Path:    sg.Path,
+			OldPath: oldPaths[sg.Path],
+			Line:    sg.FromLine,
+		}, sg.Render())
+		if err != nil {
+			log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Str("path", sg.Path).Msg("failed to add suggestion")
+			continue
+		}
+
+		s.saveAIComment(mr, discussion, category)
+	}
+
+	return nil
+}
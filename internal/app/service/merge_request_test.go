@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestMergeRequestsHandler_Review(t *testing.T) {
+	t.Parallel()
+
+	newService := func(g *gitlabStub) (*Service, *policyStub) {
+		policy := &policyStub{}
+
+		return &Service{
+			r:        &repositoryStub{mrs: make(map[int]*ds.MergeRequest)},
+			gitlab:   g,
+			teams:    []*ds.Team{{ID: "backend", Policy: "rd"}},
+			policies: map[ds.PolicyName]Policy{"rd": policy},
+		}, policy
+	}
+
+	mr := func(sha, title string) *ds.MergeRequest {
+		return &ds.MergeRequest{ID: 1, ProjectID: 2, IID: 3, SHA: sha, Title: title, State: ds.StateOpened}
+	}
+
+	t.Run("reviews only new revisions", func(t *testing.T) {
+		t.Parallel()
+
+		g := &gitlabStub{}
+		s, _ := newService(g)
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "renamed")))
+		require.Equal(t, 1, g.diffCalls, "the title change is not reviewed")
+
+		require.NoError(t, s.mergeRequestsHandler(mr("b", "renamed")))
+		require.Equal(t, 2, g.diffCalls, "the new revision is reviewed")
+	})
+
+	t.Run("failed review doesn't block policies", func(t *testing.T) {
+		t.Parallel()
+
+		s, policy := newService(&gitlabStub{diffErr: errors.New("gitlab is down")})
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+		require.Equal(t, 1, policy.processed)
+	})
+
+	t.Run("retries a failed review of the revision", func(t *testing.T) {
+		t.Parallel()
+
+		g := &gitlabStub{diffErr: errors.New("gitlab is down")}
+		s, _ := newService(g)
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+
+		g.diffErr = nil
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+		require.Equal(t, 2, g.diffCalls, "the same revision is reviewed again")
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+		require.Equal(t, 2, g.diffCalls, "the reviewed revision is skipped")
+	})
+
+	t.Run("posts rule findings once", func(t *testing.T) {
+		t.Parallel()
+
+		rules, err := NewDiffRules([]ds.DiffRule{{Name: "todo", Message: "TODO without a ticket", AddedLine: `TODO`}})
+		require.NoError(t, err)
+
+		g := &gitlabStub{diffs: []*Diff{{NewPath: "main.go", OldPath: "main.go", Content: "@@ -1 +1,2 @@\n a\n+// TODO\n"}}}
+		s, _ := newService(g)
+		s.diffRules = map[int]*DiffRules{2: rules}
+
+		require.NoError(t, s.mergeRequestsHandler(mr("a", "first")))
+		require.Len(t, g.comments, 1)
+
+		g.diffs = append(g.diffs, &Diff{NewPath: "util.go", OldPath: "util.go", Content: "@@ -1 +1,2 @@\n a\n+// TODO\n"})
+
+		require.NoError(t, s.mergeRequestsHandler(mr("b", "first")))
+		require.Len(t, g.comments, 2)
+		require.NotContains(t, g.comments[1], "main.go", "the finding of the unchanged line is posted already")
+		require.Contains(t, g.comments[1], "util.go")
+	})
+}
+
+func TestSyncAssignments(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	assigned := created.Add(time.Hour)
+	now := created.Add(24 * time.Hour)
+
+	reviewers := func(ids ...int) []*ds.BasicUser {
+		return lo.Map(ids, func(id int, _ int) *ds.BasicUser { return &ds.BasicUser{GitLabID: id} })
+	}
+
+	mr := &ds.MergeRequest{Reviewers: reviewers(1, 3)}
+
+	require.Equal(t, []*ds.Assignment{
+		{UserID: 1, AssignedAt: now},
+		{UserID: 3, AssignedAt: now},
+	}, syncAssignments(nil, mr, now), "reviewers of new merge requests are assigned now")
+
+	old := &ds.MergeRequest{
+		CreatedAt:   &created,
+		Reviewers:   reviewers(1, 2),
+		Assignments: []*ds.Assignment{{UserID: 1, AssignedAt: assigned}, {UserID: 2, AssignedAt: assigned}},
+	}
+
+	require.Equal(t, []*ds.Assignment{
+		{UserID: 1, AssignedAt: assigned},
+		{UserID: 3, AssignedAt: now},
+	}, syncAssignments(old, mr, now), "assignments are kept while reviewers are assigned")
+
+	old.Assignments = nil
+
+	require.Equal(t, []*ds.Assignment{
+		{UserID: 1, AssignedAt: created},
+		{UserID: 3, AssignedAt: now},
+	}, syncAssignments(old, mr, now), "merge requests stored before assignments were tracked")
+}
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	ds "github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	service "github.com/jokerlee/gitlab-review-bot/internal/app/service"
 )
 
 // Repository is a mock of Repository interface.
@@ -35,6 +36,152 @@ func (m *Repository) EXPECT() *RepositoryMockRecorder {
 	return m.recorder
 }
 
+// AICommentsCreatedAfter mocks base method.
+func (m *Repository) AICommentsCreatedAfter(after time.Time) ([]*ds.AIComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AICommentsCreatedAfter", after)
+	ret0, _ := ret[0].([]*ds.AIComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AICommentsCreatedAfter indicates an expected call of AICommentsCreatedAfter.
+func (mr *RepositoryMockRecorder) AICommentsCreatedAfter(after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AICommentsCreatedAfter", reflect.TypeOf((*Repository)(nil).AICommentsCreatedAfter), after)
+}
+
+// AIReviewByHash mocks base method.
+func (m *Repository) AIReviewByHash(hash string) (*ds.AIReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AIReviewByHash", hash)
+	ret0, _ := ret[0].(*ds.AIReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AIReviewByHash indicates an expected call of AIReviewByHash.
+func (mr *RepositoryMockRecorder) AIReviewByHash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AIReviewByHash", reflect.TypeOf((*Repository)(nil).AIReviewByHash), hash)
+}
+
+// AbsencesByUser mocks base method.
+func (m *Repository) AbsencesByUser(userID int, after time.Time) ([]*ds.Absence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbsencesByUser", userID, after)
+	ret0, _ := ret[0].([]*ds.Absence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AbsencesByUser indicates an expected call of AbsencesByUser.
+func (mr *RepositoryMockRecorder) AbsencesByUser(userID, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbsencesByUser", reflect.TypeOf((*Repository)(nil).AbsencesByUser), userID, after)
+}
+
+// ActiveAbsences mocks base method.
+func (m *Repository) ActiveAbsences(at time.Time) ([]*ds.Absence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveAbsences", at)
+	ret0, _ := ret[0].([]*ds.Absence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveAbsences indicates an expected call of ActiveAbsences.
+func (mr *RepositoryMockRecorder) ActiveAbsences(at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveAbsences", reflect.TypeOf((*Repository)(nil).ActiveAbsences), at)
+}
+
+// AddAbsence mocks base method.
+func (m *Repository) AddAbsence(absence *ds.Absence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAbsence", absence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAbsence indicates an expected call of AddAbsence.
+func (mr *RepositoryMockRecorder) AddAbsence(absence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAbsence", reflect.TypeOf((*Repository)(nil).AddAbsence), absence)
+}
+
+// AddPostedFindings mocks base method.
+func (m *Repository) AddPostedFindings(mrID int, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPostedFindings", mrID, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPostedFindings indicates an expected call of AddPostedFindings.
+func (mr *RepositoryMockRecorder) AddPostedFindings(mrID, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPostedFindings", reflect.TypeOf((*Repository)(nil).AddPostedFindings), mrID, keys)
+}
+
+// CommitByID mocks base method.
+func (m *Repository) CommitByID(id string) (*ds.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitByID", id)
+	ret0, _ := ret[0].(*ds.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitByID indicates an expected call of CommitByID.
+func (mr *RepositoryMockRecorder) CommitByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitByID", reflect.TypeOf((*Repository)(nil).CommitByID), id)
+}
+
+// DeleteAbsence mocks base method.
+func (m *Repository) DeleteAbsence(userID int, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAbsence", userID, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAbsence indicates an expected call of DeleteAbsence.
+func (mr *RepositoryMockRecorder) DeleteAbsence(userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAbsence", reflect.TypeOf((*Repository)(nil).DeleteAbsence), userID, id)
+}
+
+// MarkAbsenceHandled mocks base method.
+func (m *Repository) MarkAbsenceHandled(id string, mrID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAbsenceHandled", id, mrID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAbsenceHandled indicates an expected call of MarkAbsenceHandled.
+func (mr *RepositoryMockRecorder) MarkAbsenceHandled(id, mrID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAbsenceHandled", reflect.TypeOf((*Repository)(nil).MarkAbsenceHandled), id, mrID)
+}
+
+// MarkMergeRequestReviewed mocks base method.
+func (m *Repository) MarkMergeRequestReviewed(mrID int, sha string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMergeRequestReviewed", mrID, sha)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMergeRequestReviewed indicates an expected call of MarkMergeRequestReviewed.
+func (mr *RepositoryMockRecorder) MarkMergeRequestReviewed(mrID, sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMergeRequestReviewed", reflect.TypeOf((*Repository)(nil).MarkMergeRequestReviewed), mrID, sha)
+}
+
 // MergeRequestByID mocks base method.
 func (m *Repository) MergeRequestByID(id int) (*ds.MergeRequest, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +257,35 @@ func (mr *RepositoryMockRecorder) Projects() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Projects", reflect.TypeOf((*Repository)(nil).Projects))
 }
 
+// ReplaceAbsences mocks base method.
+func (m *Repository) ReplaceAbsences(userID int, source ds.AbsenceSource, absences []*ds.Absence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceAbsences", userID, source, absences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceAbsences indicates an expected call of ReplaceAbsences.
+func (mr *RepositoryMockRecorder) ReplaceAbsences(userID, source, absences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAbsences", reflect.TypeOf((*Repository)(nil).ReplaceAbsences), userID, source, absences)
+}
+
+// ReviewSLA mocks base method.
+func (m *Repository) ReviewSLA(mrID int, teamID string) (*ds.ReviewSLA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewSLA", mrID, teamID)
+	ret0, _ := ret[0].(*ds.ReviewSLA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewSLA indicates an expected call of ReviewSLA.
+func (mr *RepositoryMockRecorder) ReviewSLA(mrID, teamID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewSLA", reflect.TypeOf((*Repository)(nil).ReviewSLA), mrID, teamID)
+}
+
 // Teams mocks base method.
 func (m *Repository) Teams() ([]*ds.Team, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +301,62 @@ func (mr *RepositoryMockRecorder) Teams() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Teams", reflect.TypeOf((*Repository)(nil).Teams))
 }
 
+// UpdateReviewSLA mocks base method.
+func (m *Repository) UpdateReviewSLA(sla *ds.ReviewSLA) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReviewSLA", sla)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReviewSLA indicates an expected call of UpdateReviewSLA.
+func (mr *RepositoryMockRecorder) UpdateReviewSLA(sla interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReviewSLA", reflect.TypeOf((*Repository)(nil).UpdateReviewSLA), sla)
+}
+
+// UpsertAIComment mocks base method.
+func (m *Repository) UpsertAIComment(comment *ds.AIComment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAIComment", comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAIComment indicates an expected call of UpsertAIComment.
+func (mr *RepositoryMockRecorder) UpsertAIComment(comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAIComment", reflect.TypeOf((*Repository)(nil).UpsertAIComment), comment)
+}
+
+// UpsertAIReview mocks base method.
+func (m *Repository) UpsertAIReview(review *ds.AIReview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAIReview", review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAIReview indicates an expected call of UpsertAIReview.
+func (mr *RepositoryMockRecorder) UpsertAIReview(review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAIReview", reflect.TypeOf((*Repository)(nil).UpsertAIReview), review)
+}
+
+// UpsertCommit mocks base method.
+func (m *Repository) UpsertCommit(commit *ds.Commit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCommit", commit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCommit indicates an expected call of UpsertCommit.
+func (mr *RepositoryMockRecorder) UpsertCommit(commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCommit", reflect.TypeOf((*Repository)(nil).UpsertCommit), commit)
+}
+
 // UpsertMergeRequest mocks base method.
 func (m *Repository) UpsertMergeRequest(mr *ds.MergeRequest) error {
 	m.ctrl.T.Helper()
@@ -178,6 +410,110 @@ func (m *GitlabClient) EXPECT() *GitlabClientMockRecorder {
 	return m.recorder
 }
 
+// AddCommentToCommit mocks base method.
+func (m *GitlabClient) AddCommentToCommit(projectID int, commitID, comment string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommentToCommit", projectID, commitID, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCommentToCommit indicates an expected call of AddCommentToCommit.
+func (mr *GitlabClientMockRecorder) AddCommentToCommit(projectID, commitID, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommentToCommit", reflect.TypeOf((*GitlabClient)(nil).AddCommentToCommit), projectID, commitID, comment)
+}
+
+// AddCommentToMergeRequests mocks base method.
+func (m *GitlabClient) AddCommentToMergeRequests(projectID, iid int, comment string) (*service.Discussion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommentToMergeRequests", projectID, iid, comment)
+	ret0, _ := ret[0].(*service.Discussion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCommentToMergeRequests indicates an expected call of AddCommentToMergeRequests.
+func (mr *GitlabClientMockRecorder) AddCommentToMergeRequests(projectID, iid, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommentToMergeRequests", reflect.TypeOf((*GitlabClient)(nil).AddCommentToMergeRequests), projectID, iid, comment)
+}
+
+// AddPositionedCommentToMergeRequest mocks base method.
+func (m *GitlabClient) AddPositionedCommentToMergeRequest(projectID, iid int, position service.CommentPosition, comment string) (*service.Discussion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPositionedCommentToMergeRequest", projectID, iid, position, comment)
+	ret0, _ := ret[0].(*service.Discussion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPositionedCommentToMergeRequest indicates an expected call of AddPositionedCommentToMergeRequest.
+func (mr *GitlabClientMockRecorder) AddPositionedCommentToMergeRequest(projectID, iid, position, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPositionedCommentToMergeRequest", reflect.TypeOf((*GitlabClient)(nil).AddPositionedCommentToMergeRequest), projectID, iid, position, comment)
+}
+
+// CommitsByProject mocks base method.
+func (m *GitlabClient) CommitsByProject(projectID int, createdAfter time.Time) ([]*ds.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitsByProject", projectID, createdAfter)
+	ret0, _ := ret[0].([]*ds.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitsByProject indicates an expected call of CommitsByProject.
+func (mr *GitlabClientMockRecorder) CommitsByProject(projectID, createdAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitsByProject", reflect.TypeOf((*GitlabClient)(nil).CommitsByProject), projectID, createdAfter)
+}
+
+// GetCommitDiff mocks base method.
+func (m *GitlabClient) GetCommitDiff(projectID int, commitID string) ([]*service.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitDiff", projectID, commitID)
+	ret0, _ := ret[0].([]*service.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitDiff indicates an expected call of GetCommitDiff.
+func (mr *GitlabClientMockRecorder) GetCommitDiff(projectID, commitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitDiff", reflect.TypeOf((*GitlabClient)(nil).GetCommitDiff), projectID, commitID)
+}
+
+// GetMergeRequestDiff mocks base method.
+func (m *GitlabClient) GetMergeRequestDiff(projectID, iid int) ([]*service.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMergeRequestDiff", projectID, iid)
+	ret0, _ := ret[0].([]*service.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMergeRequestDiff indicates an expected call of GetMergeRequestDiff.
+func (mr *GitlabClientMockRecorder) GetMergeRequestDiff(projectID, iid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMergeRequestDiff", reflect.TypeOf((*GitlabClient)(nil).GetMergeRequestDiff), projectID, iid)
+}
+
+// GetRawFile mocks base method.
+func (m *GitlabClient) GetRawFile(projectID int, path, ref string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRawFile", projectID, path, ref)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRawFile indicates an expected call of GetRawFile.
+func (mr *GitlabClientMockRecorder) GetRawFile(projectID, path, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRawFile", reflect.TypeOf((*GitlabClient)(nil).GetRawFile), projectID, path, ref)
+}
+
 // MergeRequestApproves mocks base method.
 func (m *GitlabClient) MergeRequestApproves(projectID, iid int) ([]*ds.BasicUser, error) {
 	m.ctrl.T.Helper()
@@ -193,6 +529,66 @@ func (mr *GitlabClientMockRecorder) MergeRequestApproves(projectID, iid interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestApproves", reflect.TypeOf((*GitlabClient)(nil).MergeRequestApproves), projectID, iid)
 }
 
+// MergeRequestNoteFeedback mocks base method.
+func (m *GitlabClient) MergeRequestNoteFeedback(projectID, iid int, discussionID string, noteID int) (*ds.AIFeedback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeRequestNoteFeedback", projectID, iid, discussionID, noteID)
+	ret0, _ := ret[0].(*ds.AIFeedback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeRequestNoteFeedback indicates an expected call of MergeRequestNoteFeedback.
+func (mr *GitlabClientMockRecorder) MergeRequestNoteFeedback(projectID, iid, discussionID, noteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestNoteFeedback", reflect.TypeOf((*GitlabClient)(nil).MergeRequestNoteFeedback), projectID, iid, discussionID, noteID)
+}
+
+// MergeRequestNotes mocks base method.
+func (m *GitlabClient) MergeRequestNotes(projectID, iid int) ([]*ds.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeRequestNotes", projectID, iid)
+	ret0, _ := ret[0].([]*ds.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeRequestNotes indicates an expected call of MergeRequestNotes.
+func (mr *GitlabClientMockRecorder) MergeRequestNotes(projectID, iid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestNotes", reflect.TypeOf((*GitlabClient)(nil).MergeRequestNotes), projectID, iid)
+}
+
+// MergeRequestVersionDiff mocks base method.
+func (m *GitlabClient) MergeRequestVersionDiff(projectID, iid, versionID int) ([]*service.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeRequestVersionDiff", projectID, iid, versionID)
+	ret0, _ := ret[0].([]*service.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeRequestVersionDiff indicates an expected call of MergeRequestVersionDiff.
+func (mr *GitlabClientMockRecorder) MergeRequestVersionDiff(projectID, iid, versionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestVersionDiff", reflect.TypeOf((*GitlabClient)(nil).MergeRequestVersionDiff), projectID, iid, versionID)
+}
+
+// MergeRequestVersions mocks base method.
+func (m *GitlabClient) MergeRequestVersions(projectID, iid int) ([]*ds.MergeRequestVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeRequestVersions", projectID, iid)
+	ret0, _ := ret[0].([]*ds.MergeRequestVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeRequestVersions indicates an expected call of MergeRequestVersions.
+func (mr *GitlabClientMockRecorder) MergeRequestVersions(projectID, iid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestVersions", reflect.TypeOf((*GitlabClient)(nil).MergeRequestVersions), projectID, iid)
+}
+
 // MergeRequestsByProject mocks base method.
 func (m *GitlabClient) MergeRequestsByProject(projectID int, createdAfter time.Time) ([]*ds.MergeRequest, error) {
 	m.ctrl.T.Helper()
@@ -208,6 +604,138 @@ func (mr *GitlabClientMockRecorder) MergeRequestsByProject(projectID, createdAft
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeRequestsByProject", reflect.TypeOf((*GitlabClient)(nil).MergeRequestsByProject), projectID, createdAfter)
 }
 
+// SetReviewers mocks base method.
+func (m *GitlabClient) SetReviewers(mr *ds.MergeRequest, reviewers []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReviewers", mr, reviewers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReviewers indicates an expected call of SetReviewers.
+func (mr_2 *GitlabClientMockRecorder) SetReviewers(mr, reviewers interface{}) *gomock.Call {
+	mr_2.mock.ctrl.T.Helper()
+	return mr_2.mock.ctrl.RecordCallWithMethodType(mr_2.mock, "SetReviewers", reflect.TypeOf((*GitlabClient)(nil).SetReviewers), mr, reviewers)
+}
+
+// UpdateMergeRequestLabels mocks base method.
+func (m *GitlabClient) UpdateMergeRequestLabels(projectID, iid int, add, remove []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMergeRequestLabels", projectID, iid, add, remove)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMergeRequestLabels indicates an expected call of UpdateMergeRequestLabels.
+func (mr *GitlabClientMockRecorder) UpdateMergeRequestLabels(projectID, iid, add, remove interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMergeRequestLabels", reflect.TypeOf((*GitlabClient)(nil).UpdateMergeRequestLabels), projectID, iid, add, remove)
+}
+
+// OpenAIClient is a mock of OpenAIClient interface.
+type OpenAIClient struct {
+	ctrl     *gomock.Controller
+	recorder *OpenAIClientMockRecorder
+}
+
+// OpenAIClientMockRecorder is the mock recorder for OpenAIClient.
+type OpenAIClientMockRecorder struct {
+	mock *OpenAIClient
+}
+
+// NewOpenAIClient creates a new mock instance.
+func NewOpenAIClient(ctrl *gomock.Controller) *OpenAIClient {
+	mock := &OpenAIClient{ctrl: ctrl}
+	mock.recorder = &OpenAIClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *OpenAIClient) EXPECT() *OpenAIClientMockRecorder {
+	return m.recorder
+}
+
+// GenerateAICodeReviewComment mocks base method.
+func (m *OpenAIClient) GenerateAICodeReviewComment(diff string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateAICodeReviewComment", diff)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateAICodeReviewComment indicates an expected call of GenerateAICodeReviewComment.
+func (mr *OpenAIClientMockRecorder) GenerateAICodeReviewComment(diff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAICodeReviewComment", reflect.TypeOf((*OpenAIClient)(nil).GenerateAICodeReviewComment), diff)
+}
+
+// Model mocks base method.
+func (m *OpenAIClient) Model() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Model")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Model indicates an expected call of Model.
+func (mr *OpenAIClientMockRecorder) Model() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Model", reflect.TypeOf((*OpenAIClient)(nil).Model))
+}
+
+// PromptVersion mocks base method.
+func (m *OpenAIClient) PromptVersion() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromptVersion")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// PromptVersion indicates an expected call of PromptVersion.
+func (mr *OpenAIClientMockRecorder) PromptVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromptVersion", reflect.TypeOf((*OpenAIClient)(nil).PromptVersion))
+}
+
+// RiskClassifier is a mock of RiskClassifier interface.
+type RiskClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *RiskClassifierMockRecorder
+}
+
+// RiskClassifierMockRecorder is the mock recorder for RiskClassifier.
+type RiskClassifierMockRecorder struct {
+	mock *RiskClassifier
+}
+
+// NewRiskClassifier creates a new mock instance.
+func NewRiskClassifier(ctrl *gomock.Controller) *RiskClassifier {
+	mock := &RiskClassifier{ctrl: ctrl}
+	mock.recorder = &RiskClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *RiskClassifier) EXPECT() *RiskClassifierMockRecorder {
+	return m.recorder
+}
+
+// ClassifyRisk mocks base method.
+func (m *RiskClassifier) ClassifyRisk(message string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClassifyRisk", message)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClassifyRisk indicates an expected call of ClassifyRisk.
+func (mr *RiskClassifierMockRecorder) ClassifyRisk(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyRisk", reflect.TypeOf((*RiskClassifier)(nil).ClassifyRisk), message)
+}
+
 // SlackClient is a mock of SlackClient interface.
 type SlackClient struct {
 	ctrl     *gomock.Controller
@@ -376,3 +904,78 @@ func (mr_2 *PolicyMockRecorder) ProcessChanges(team, mr interface{}) *gomock.Cal
 	mr_2.mock.ctrl.T.Helper()
 	return mr_2.mock.ctrl.RecordCallWithMethodType(mr_2.mock, "ProcessChanges", reflect.TypeOf((*Policy)(nil).ProcessChanges), team, mr)
 }
+
+// ReviewerPicker is a mock of ReviewerPicker interface.
+type ReviewerPicker struct {
+	ctrl     *gomock.Controller
+	recorder *ReviewerPickerMockRecorder
+}
+
+// ReviewerPickerMockRecorder is the mock recorder for ReviewerPicker.
+type ReviewerPickerMockRecorder struct {
+	mock *ReviewerPicker
+}
+
+// NewReviewerPicker creates a new mock instance.
+func NewReviewerPicker(ctrl *gomock.Controller) *ReviewerPicker {
+	mock := &ReviewerPicker{ctrl: ctrl}
+	mock.recorder = &ReviewerPickerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *ReviewerPicker) EXPECT() *ReviewerPickerMockRecorder {
+	return m.recorder
+}
+
+// Pick mocks base method.
+func (m *ReviewerPicker) Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pick", team, pool, mr, candidates, n)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pick indicates an expected call of Pick.
+func (mr_2 *ReviewerPickerMockRecorder) Pick(team, pool, mr, candidates, n interface{}) *gomock.Call {
+	mr_2.mock.ctrl.T.Helper()
+	return mr_2.mock.ctrl.RecordCallWithMethodType(mr_2.mock, "Pick", reflect.TypeOf((*ReviewerPicker)(nil).Pick), team, pool, mr, candidates, n)
+}
+
+// SettingsValidator is a mock of SettingsValidator interface.
+type SettingsValidator struct {
+	ctrl     *gomock.Controller
+	recorder *SettingsValidatorMockRecorder
+}
+
+// SettingsValidatorMockRecorder is the mock recorder for SettingsValidator.
+type SettingsValidatorMockRecorder struct {
+	mock *SettingsValidator
+}
+
+// NewSettingsValidator creates a new mock instance.
+func NewSettingsValidator(ctrl *gomock.Controller) *SettingsValidator {
+	mock := &SettingsValidator{ctrl: ctrl}
+	mock.recorder = &SettingsValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *SettingsValidator) EXPECT() *SettingsValidatorMockRecorder {
+	return m.recorder
+}
+
+// ValidateSettings mocks base method.
+func (m *SettingsValidator) ValidateSettings(team *ds.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateSettings", team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateSettings indicates an expected call of ValidateSettings.
+func (mr *SettingsValidatorMockRecorder) ValidateSettings(team interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateSettings", reflect.TypeOf((*SettingsValidator)(nil).ValidateSettings), team)
+}
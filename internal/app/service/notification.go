@@ -3,6 +3,7 @@ package service
 import (
 	"bytes"
 	"math"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
@@ -17,8 +18,7 @@ func (s *Service) GetAuthoredReviewedMRs(
 	team *ds.Team,
 	users []*ds.User,
 ) (authorToMR, reviewerToMR map[int][]*ds.MergeRequest, err error) {
-	policy, ok := s.policies[team.Policy]
-	if !ok {
+	if _, ok := s.policies[team.Policy]; !ok {
 		return nil, nil, errors.Errorf("policy %s not found", team.Policy)
 	}
 
@@ -36,7 +36,7 @@ func (s *Service) GetAuthoredReviewedMRs(
 			continue
 		}
 
-		if policy.ApprovedByPolicy(team, mr) {
+		if s.approvedByTeams(team, mr) {
 			continue
 		}
 
@@ -52,8 +52,14 @@ func (s *Service) GetAuthoredReviewedMRs(
 
 	reviewerToMR = make(map[int][]*ds.MergeRequest, len(toReviewMRs))
 	for _, mr := range toReviewMRs {
+		routed, policy, ok := s.teamPolicy(team, mr)
+		if !ok {
+			// nobody waits for reviews of merge requests without a policy
+			continue
+		}
+
 		for _, reviewer := range mr.Reviewers {
-			if policy.ApprovedByUser(team, mr, reviewer) {
+			if policy.ApprovedByUser(routed, mr, reviewer) {
 				continue
 			}
 
@@ -70,7 +76,7 @@ func (s *Service) UserNotification(
 	authorToMR, reviewerToMR map[int][]*ds.MergeRequest,
 ) (message string, err error) {
 	// TODO: optimize initializations for performance
-	userTemplate := template.New("user_notification").Funcs(s.templateFuncMap(team.Notifications.Locale))
+	userTemplate := template.New("user_notification").Funcs(s.templateFuncMap(team))
 
 	userTemplate, err = userTemplate.Parse(team.Notifications.UserTemplate)
 	if err != nil {
@@ -95,7 +101,7 @@ func (s *Service) TeamNotification(
 	team *ds.Team,
 	authorToMR, reviewerToMR map[int][]*ds.MergeRequest,
 ) (message string, err error) {
-	channelTemplate := template.New("team_notification").Funcs(s.templateFuncMap(team.Notifications.Locale))
+	channelTemplate := template.New("team_notification").Funcs(s.templateFuncMap(team))
 
 	channelTemplate, err = channelTemplate.Parse(team.Notifications.ChannelTemplate)
 	if err != nil {
@@ -147,11 +153,11 @@ func (s *Service) TeamNotification(
 	return chanMsg.String(), nil
 }
 
-func (s *Service) templateFuncMap(locale string) template.FuncMap {
-	loc, ok := templating.ParseLocale(locale)
+func (s *Service) templateFuncMap(team *ds.Team) template.FuncMap {
+	loc, ok := templating.ParseLocale(team.Notifications.Locale)
 
 	if !ok {
-		log.Warn().Str("locale", locale).Msg("failed to parse locale, using default (en_EN)")
+		log.Warn().Str("locale", team.Notifications.Locale).Msg("failed to parse locale, using default (en_EN)")
 	}
 
 	tools := templating.NewTools(loc)
@@ -160,5 +166,21 @@ func (s *Service) templateFuncMap(locale string) template.FuncMap {
 		"since":      tools.Since,
 		"plural":     tools.Plural,
 		"motivation": tools.Motivation,
+		"byRisk":     byRisk,
+		"skills":     pickedSkills,
+	}
+}
+
+// pickedSkills is a template function listing skills the user was picked as a reviewer of the merge request for
+func pickedSkills(user *ds.User, mr *ds.MergeRequest) string {
+	if user == nil || mr == nil {
+		return ""
 	}
+
+	reason := mr.PickReason(user.GitLabID)
+	if reason == nil {
+		return ""
+	}
+
+	return strings.Join(reason.Skills, ", ")
 }
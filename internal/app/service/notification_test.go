@@ -158,9 +158,9 @@ func TestNotificationsService(t *testing.T) {
 		Return([]*ds.MergeRequest{MR2, MR3, MR4}, nil).
 		Times(1)
 
-	svc, svcErr := service.New(repository, nil, map[ds.PolicyName]service.Policy{
+	svc, svcErr := service.New(service.Config{}, repository, nil, map[ds.PolicyName]service.Policy{
 		"test_policy": policy,
-	}, nil, nil)
+	}, nil, nil, nil)
 	require.NoError(t, svcErr, "service.New() failed")
 
 	var (
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/redact"
+)
+
+// SecretFinding is a likely secret committed in added lines of a diff
+type SecretFinding struct {
+	Detector string
+	Path     string
+}
+
+type projectRedaction struct {
+	settings ds.RedactionSettings
+	redactor *redact.Redactor
+}
+
+// NewRedactor compiles patterns and detectors of the redaction settings of a project
+func NewRedactor(settings ds.RedactionSettings) (*redact.Redactor, error) {
+	patterns := lo.Map(settings.Patterns, func(p ds.RedactionPattern, _ int) redact.Pattern {
+		return redact.Pattern{Name: p.Name, Regex: p.Regex}
+	})
+
+	return redact.New(patterns, settings.DisabledDetectors...)
+}
+
+func newProjectRedaction(settings ds.RedactionSettings) (*projectRedaction, error) {
+	redactor, err := NewRedactor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &projectRedaction{
+		settings: settings,
+		redactor: redactor,
+	}, nil
+}
+
+// initRedaction compiles redaction settings of the projects, projects without own settings use builtin detectors
+func (s *Service) initRedaction(projects []*ds.Project) error {
+	var err error
+
+	s.redaction = make(map[int]*projectRedaction, len(projects)+1)
+
+	s.defaultRedaction, err = newProjectRedaction(ds.RedactionSettings{})
+	if err != nil {
+		return errors.Wrap(err, "failed to init default redaction")
+	}
+
+	for _, project := range projects {
+		s.redaction[project.ID], err = newProjectRedaction(project.Redaction)
+		if err != nil {
+			return errors.Wrapf(err, "invalid redaction settings of project %s", project.Name)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) projectRedaction(projectID int) *projectRedaction {
+	if pr, ok := s.redaction[projectID]; ok {
+		return pr
+	}
+
+	return s.defaultRedaction
+}
+
+// redactDiffs masks secrets and personal data in the diffs before they are sent to the LLM.
+// Original diffs are not modified.
+func (s *Service) redactDiffs(projectID int, diffs []*Diff) ([]*Diff, []SecretFinding) {
+	pr := s.projectRedaction(projectID)
+	if pr == nil || pr.settings.Disabled {
+		return diffs, nil
+	}
+
+	return RedactDiffs(pr.redactor, diffs)
+}
+
+// RedactDiffs returns copies of the diffs with masked values and likely secrets found in added lines
+func RedactDiffs(redactor *redact.Redactor, diffs []*Diff) ([]*Diff, []SecretFinding) {
+	redacted := make([]*Diff, 0, len(diffs))
+	secrets := make([]SecretFinding, 0)
+
+	for _, diff := range diffs {
+		content, findings := redactor.Redact(diff.Content)
+
+		lines := strings.Split(diff.Content, "\n")
+		for _, finding := range findings {
+			// only added lines mean the secret was committed
+			if !finding.Secret || !strings.HasPrefix(lines[finding.Line], "+") {
+				continue
+			}
+
+			secrets = append(secrets, SecretFinding{
+				Detector: finding.Detector,
+				Path:     diff.NewPath,
+			})
+		}
+
+		d := *diff
+		d.Content = content
+		redacted = append(redacted, &d)
+	}
+
+	return redacted, lo.Uniq(secrets)
+}
+
+// secretsWarningRequired checks if project wants a separate note about committed secrets
+func (s *Service) secretsWarningRequired(projectID int, secrets []SecretFinding) bool {
+	pr := s.projectRedaction(projectID)
+
+	return pr != nil && pr.settings.WarnOnSecrets && len(secrets) > 0
+}
+
+// SecretsWarning composes a note about likely secrets, the values are never included
+func SecretsWarning(secrets []SecretFinding) string {
+	var msg strings.Builder
+
+	msg.WriteString(":warning: **Likely secrets were committed.**\n\n")
+
+	sorted := make([]SecretFinding, len(secrets))
+	copy(sorted, secrets)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+
+		return sorted[i].Detector < sorted[j].Detector
+	})
+
+	for _, secret := range sorted {
+		msg.WriteString(fmt.Sprintf("- `%s` in `%s`\n", secret.Detector, secret.Path))
+	}
+
+	msg.WriteString("\nThe values were masked before the AI review. " +
+		"If they are real, remove them from the history and rotate them.")
+
+	return msg.String()
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+type ReReviewConfig struct {
+	// Threshold is how many lines changed since an approve make it stale, 0 disables re-review
+	Threshold int
+}
+
+// syncApprovals keeps versions of still given approves, versions of new approves are resolved by the timeline
+func syncApprovals(prev []*ds.Approval, approves []*ds.BasicUser) []*ds.Approval {
+	res := make([]*ds.Approval, 0, len(approves))
+
+	for _, approve := range approves {
+		approval, ok := lo.Find(prev, func(a *ds.Approval) bool { return a.UserID == approve.GitLabID })
+		if !ok {
+			approval = &ds.Approval{UserID: approve.GitLabID}
+		}
+
+		res = append(res, approval)
+	}
+
+	return res
+}
+
+// freshApproves returns approves which are not stale
+func freshApproves(approves []*ds.BasicUser, approvals []*ds.Approval) []*ds.BasicUser {
+	return lo.Filter(approves, func(approve *ds.BasicUser, _ int) bool {
+		return !lo.ContainsBy(approvals, func(a *ds.Approval) bool { return a.UserID == approve.GitLabID && a.Stale })
+	})
+}
+
+// lastApprovedAt returns the time of the last approve of the user in the timeline, nil if there is no approve
+func lastApprovedAt(notes []*ds.Note, userID int) *time.Time {
+	var approvedAt *time.Time
+
+	for _, note := range notes {
+		if note.AuthorID == userID && note.IsApprove() {
+			approvedAt = lo.ToPtr(note.CreatedAt)
+		}
+	}
+
+	return approvedAt
+}
+
+// approvedVersion returns the diff version at the last approve of the user in the timeline,
+// the newest version if the approve is not in the timeline
+func approvedVersion(notes []*ds.Note, versions []*ds.MergeRequestVersion, userID int) *ds.MergeRequestVersion {
+	approvedAt := lastApprovedAt(notes, userID)
+	if approvedAt == nil {
+		return versions[0]
+	}
+
+	for _, version := range versions {
+		if !version.CreatedAt.After(*approvedAt) {
+			return version
+		}
+	}
+
+	return versions[len(versions)-1]
+}
+
+// changedLines returns added and removed lines of every file of the diff
+func changedLines(diffs []*Diff) map[string]map[string]int {
+	res := make(map[string]map[string]int, len(diffs))
+
+	for _, diff := range diffs {
+		path := diff.NewPath
+		if path == "" {
+			path = diff.OldPath
+		}
+
+		if res[path] == nil {
+			res[path] = make(map[string]int)
+		}
+
+		for _, line := range strings.Split(diff.Content, "\n") {
+			if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+				continue
+			}
+
+			if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+				res[path][line]++
+			}
+		}
+	}
+
+	return res
+}
+
+// interdiffLines counts changed lines which differ between two versions of changes of the merge request,
+// changes of the target branch brought by rebases are not in them
+func interdiffLines(from, to []*Diff) int {
+	before, after := changedLines(from), changedLines(to)
+
+	count := 0
+
+	for path, lines := range after {
+		for line, n := range lines {
+			if d := n - before[path][line]; d > 0 {
+				count += d
+			}
+		}
+	}
+
+	for path, lines := range before {
+		for line, n := range lines {
+			if d := n - after[path][line]; d > 0 {
+				count += d
+			}
+		}
+	}
+
+	return count
+}
+
+// trackApprovals marks approves given before big changes as stale, requests re-review of them
+// and returns approves counted by policies
+func (s *Service) trackApprovals(old, mr *ds.MergeRequest, approves []*ds.BasicUser, now time.Time) []*ds.BasicUser {
+	var prev []*ds.Approval
+	if old != nil {
+		prev = old.Approvals
+	}
+
+	mr.Approvals = syncApprovals(prev, approves)
+
+	if s.cfg.ReReview.Threshold <= 0 {
+		return approves
+	}
+
+	l := log.With().Int("project_id", mr.ProjectID).Int("iid", mr.IID).Logger()
+
+	err := s.renewApprovals(mr, now)
+	if err != nil {
+		// stale approves stay stale until the next poll
+		l.Error().Err(err).Msg("failed to check approves given again")
+	}
+
+	// approves are checked once per pushed revision
+	unchecked := lo.Filter(mr.Approvals, func(approval *ds.Approval, _ int) bool {
+		return !approval.Stale && (approval.VersionID == 0 || approval.CheckedSHA != mr.SHA)
+	})
+	if len(unchecked) == 0 {
+		return freshApproves(approves, mr.Approvals)
+	}
+
+	stale, err := s.staleApprovals(mr, unchecked, now)
+	if err != nil {
+		// approves are kept, they are checked again on the next poll
+		l.Error().Err(err).Msg("failed to check approved versions")
+	}
+
+	if len(stale) > 0 {
+		approvers := lo.FilterMap(stale, func(approval *ds.Approval, _ int) (*ds.BasicUser, bool) {
+			return lo.Find(approves, func(user *ds.BasicUser) bool { return user.GitLabID == approval.UserID })
+		})
+
+		err = s.requestReReview(mr, approvers)
+		if err != nil {
+			l.Error().Err(err).Msg("failed to request re-review")
+		}
+	}
+
+	return freshApproves(approves, mr.Approvals)
+}
+
+// renewApprovals resets stale approvals given again after they became stale: a revoke and a new approve
+// between two polls keep the approve in the list, only the timeline tells it was given again
+func (s *Service) renewApprovals(mr *ds.MergeRequest, now time.Time) error {
+	stale := lo.Filter(mr.Approvals, func(approval *ds.Approval, _ int) bool { return approval.Stale })
+	if len(stale) == 0 {
+		return nil
+	}
+
+	// approvals marked stale before the time was stored are stale since they are seen
+	for _, approval := range stale {
+		if approval.StaleAt == nil {
+			approval.StaleAt = lo.ToPtr(now)
+		}
+	}
+
+	notes, err := s.gitlab.MergeRequestNotes(mr.ProjectID, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get notes")
+	}
+
+	for _, approval := range stale {
+		approvedAt := lastApprovedAt(notes, approval.UserID)
+		if approvedAt == nil || !approvedAt.After(*approval.StaleAt) {
+			continue
+		}
+
+		// the version of the new approve is resolved by the timeline
+		*approval = ds.Approval{UserID: approval.UserID}
+	}
+
+	return nil
+}
+
+// staleApprovals resolves diff versions of approvals and marks stale the ones
+// whose changes differ from the newest version more than the threshold
+func (s *Service) staleApprovals(mr *ds.MergeRequest, approvals []*ds.Approval, now time.Time) ([]*ds.Approval, error) {
+	versions, err := s.gitlab.MergeRequestVersions(mr.ProjectID, mr.IID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get versions")
+	}
+
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	if lo.SomeBy(approvals, func(approval *ds.Approval) bool { return approval.VersionID == 0 }) {
+		notes, err := s.gitlab.MergeRequestNotes(mr.ProjectID, mr.IID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get notes")
+		}
+
+		for _, approval := range approvals {
+			if approval.VersionID == 0 {
+				version := approvedVersion(notes, versions, approval.UserID)
+				approval.VersionID, approval.SHA = version.ID, version.HeadSHA
+			}
+		}
+	}
+
+	latest := versions[0]
+	// the version of the pushed revision may be not created yet, approves are checked on the next poll then
+	checked := latest.HeadSHA == mr.SHA
+
+	// changes of a version are fetched once
+	diffs := make(map[int][]*Diff)
+	versionDiff := func(id int) ([]*Diff, error) {
+		if diff, ok := diffs[id]; ok {
+			return diff, nil
+		}
+
+		diff, err := s.gitlab.MergeRequestVersionDiff(mr.ProjectID, mr.IID, id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get changes of version %d", id)
+		}
+
+		diffs[id] = diff
+
+		return diff, nil
+	}
+
+	stale := make([]*ds.Approval, 0)
+
+	for _, approval := range approvals {
+		if approval.VersionID != latest.ID {
+			from, err := versionDiff(approval.VersionID)
+			if err != nil {
+				return stale, err
+			}
+
+			to, err := versionDiff(latest.ID)
+			if err != nil {
+				return stale, err
+			}
+
+			if interdiffLines(from, to) > s.cfg.ReReview.Threshold {
+				approval.Stale = true
+				approval.StaleAt = lo.ToPtr(now)
+				stale = append(stale, approval)
+			}
+		}
+
+		if checked {
+			approval.CheckedSHA = mr.SHA
+		}
+	}
+
+	return stale, nil
+}
+
+// requestReReview requests review of approvers of stale approves again and notifies them
+func (s *Service) requestReReview(mr *ds.MergeRequest, approvers []*ds.BasicUser) error {
+	isApprover := func(user *ds.BasicUser, _ int) bool {
+		return lo.ContainsBy(approvers, func(approver *ds.BasicUser) bool { return approver.GitLabID == user.GitLabID })
+	}
+	ids := func(users []*ds.BasicUser) []int {
+		return lo.Map(users, func(user *ds.BasicUser, _ int) int { return user.GitLabID })
+	}
+
+	others := lo.Reject(mr.Reviewers, isApprover)
+
+	// GitLab requests review only of added reviewers, so approvers who are reviewers are removed first
+	if len(others) < len(mr.Reviewers) {
+		err := s.gitlab.SetReviewers(mr, ids(others))
+		if err != nil {
+			return errors.Wrap(err, "failed to remove approvers from reviewers")
+		}
+	}
+
+	reviewers := append(others, approvers...)
+
+	err := s.gitlab.SetReviewers(mr, ids(reviewers))
+	if err != nil {
+		return errors.Wrap(err, "failed to request review of approvers")
+	}
+
+	// policies process the merge request on top of it
+	mr.Reviewers = reviewers
+
+	names := make([]string, 0, len(approvers))
+
+	for _, approver := range approvers {
+		name := approver.Name
+		if name == "" {
+			name = fmt.Sprintf("user %d", approver.GitLabID)
+		}
+
+		names = append(names, "**"+name+"**")
+
+		member := s.teammate(approver.GitLabID)
+		if member == nil || member.SlackID == "" {
+			continue
+		}
+
+		err := s.slack.SendMessage(member.SlackID,
+			fmt.Sprintf(":repeat: <%s|%s> has changed a lot since your approve, please review it again.", mr.URL, mr.Title))
+		if err != nil {
+			log.Error().Err(err).Int("user_id", approver.GitLabID).Msg("failed to notify about re-review")
+		}
+	}
+
+	_, err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID,
+		fmt.Sprintf(":repeat: The merge request has changed a lot since the approve of %s, "+
+			"the approve is not counted until it is revoked and given again.", strings.Join(names, ", ")))
+	if err != nil {
+		return errors.Wrap(err, "failed to comment re-review")
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestSyncApprovals(t *testing.T) {
+	t.Parallel()
+
+	prev := []*ds.Approval{
+		{UserID: 1, SHA: "a", VersionID: 1, Stale: true},
+		{UserID: 2, SHA: "a", VersionID: 1},
+		{UserID: 3, SHA: "b", VersionID: 2},
+	}
+
+	approves := []*ds.BasicUser{{GitLabID: 1}, {GitLabID: 3}, {GitLabID: 4}}
+
+	require.Equal(t, []*ds.Approval{
+		{UserID: 1, SHA: "a", VersionID: 1, Stale: true},
+		{UserID: 3, SHA: "b", VersionID: 2},
+		{UserID: 4},
+	}, syncApprovals(prev, approves), "revoked approves are forgotten, versions of new ones are not known yet")
+}
+
+func TestFreshApproves(t *testing.T) {
+	t.Parallel()
+
+	approves := []*ds.BasicUser{{GitLabID: 1}, {GitLabID: 2}, {GitLabID: 3}}
+	approvals := []*ds.Approval{
+		{UserID: 1, SHA: "a", Stale: true},
+		{UserID: 2, SHA: "a"},
+	}
+
+	require.Equal(t, []*ds.BasicUser{{GitLabID: 2}, {GitLabID: 3}}, freshApproves(approves, approvals))
+}
+
+func TestApprovedVersion(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time { return time.Date(2024, 3, 4, hour, 0, 0, 0, time.UTC) }
+
+	versions := []*ds.MergeRequestVersion{
+		{ID: 3, HeadSHA: "c", CreatedAt: at(14)},
+		{ID: 2, HeadSHA: "b", CreatedAt: at(12)},
+		{ID: 1, HeadSHA: "a", CreatedAt: at(10)},
+	}
+
+	notes := []*ds.Note{
+		{AuthorID: 1, Body: "approved this merge request", System: true, CreatedAt: at(11)},
+		{AuthorID: 2, Body: "approved this merge request", System: true, CreatedAt: at(11)},
+		{AuthorID: 2, Body: "unapproved this merge request", System: true, CreatedAt: at(12)},
+		{AuthorID: 2, Body: "approved this merge request", System: true, CreatedAt: at(13)},
+		{AuthorID: 3, Body: "approved this merge request", CreatedAt: at(13)},
+	}
+
+	require.Equal(t, 1, approvedVersion(notes, versions, 1).ID)
+	require.Equal(t, 2, approvedVersion(notes, versions, 2).ID, "the last approve counts")
+	require.Equal(t, 3, approvedVersion(notes, versions, 3).ID, "comments are not approves, the newest version is used")
+}
+
+func TestInterdiffLines(t *testing.T) {
+	t.Parallel()
+
+	approved := []*Diff{
+		{NewPath: "a.go", Content: "@@ -1,3 +1,3 @@\n context\n-old\n+new\n"},
+		{NewPath: "b.go", Content: "@@ -1 +1,2 @@\n+one\n+two\n"},
+	}
+
+	rebased := []*Diff{
+		{NewPath: "a.go", Content: "@@ -10,3 +10,3 @@\n other context\n-old\n+new\n"},
+		{NewPath: "b.go", Content: "@@ -1 +1,2 @@\n+one\n+two\n"},
+	}
+
+	changed := []*Diff{
+		{NewPath: "a.go", Content: "@@ -1,3 +1,3 @@\n context\n-old\n+newer\n"},
+		{NewPath: "c.go", Content: "@@ -0,0 +1 @@\n+three\n"},
+	}
+
+	require.Zero(t, interdiffLines(approved, rebased), "rebases move changes only")
+	// +new, +one, +two are gone, +newer and +three are added
+	require.Equal(t, 5, interdiffLines(approved, changed))
+}
+
+func TestService_trackApprovals(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time { return time.Date(2024, 3, 4, hour, 0, 0, 0, time.UTC) }
+
+	g := &gitlabStub{
+		versions: []*ds.MergeRequestVersion{
+			{ID: 3, HeadSHA: "c", CreatedAt: at(14)},
+			{ID: 2, HeadSHA: "b", CreatedAt: at(12)},
+			{ID: 1, HeadSHA: "a", CreatedAt: at(10)},
+		},
+		versionDiffs: map[int][]*Diff{
+			1: {{NewPath: "a.go", Content: "+one\n"}},
+			2: {{NewPath: "a.go", Content: "+one\n+two\n+three\n"}},
+			3: {{NewPath: "a.go", Content: "+one\n+two\n+three\n"}},
+		},
+		notes: []*ds.Note{
+			{AuthorID: 1, Body: "approved this merge request", System: true, CreatedAt: at(11)},
+			{AuthorID: 2, Body: "approved this merge request", System: true, CreatedAt: at(13)},
+		},
+	}
+	s := &Service{gitlab: g, cfg: Config{ReReview: ReReviewConfig{Threshold: 1}}}
+
+	approves := []*ds.BasicUser{{GitLabID: 1}, {GitLabID: 2}}
+	mr := &ds.MergeRequest{SHA: "c", Reviewers: []*ds.BasicUser{{GitLabID: 1}, {GitLabID: 5}}}
+
+	res := s.trackApprovals(nil, mr, approves, at(15))
+	require.Equal(t, []*ds.BasicUser{{GitLabID: 2}}, res, "the approve before new changes is stale")
+	require.Equal(t, []*ds.Approval{
+		{UserID: 1, SHA: "a", VersionID: 1, CheckedSHA: "c", Stale: true, StaleAt: lo.ToPtr(at(15))},
+		{UserID: 2, SHA: "b", VersionID: 2, CheckedSHA: "c"},
+	}, mr.Approvals)
+	require.Equal(t, [][]int{{5}, {5, 1}}, g.reviewers, "the reviewer is removed and added to request review again")
+	require.Len(t, g.comments, 1)
+
+	next := &ds.MergeRequest{SHA: "c"}
+	res = s.trackApprovals(mr, next, approves, at(16))
+	require.Equal(t, []*ds.BasicUser{{GitLabID: 2}}, res)
+	require.Equal(t, 1, g.versionCalls, "checked approves are not checked again")
+
+	// the approver revoked and approved again between two polls
+	g.notes = append(g.notes,
+		&ds.Note{AuthorID: 1, Body: "unapproved this merge request", System: true, CreatedAt: at(17)},
+		&ds.Note{AuthorID: 1, Body: "approved this merge request", System: true, CreatedAt: at(17)})
+
+	res = s.trackApprovals(next, &ds.MergeRequest{SHA: "c"}, approves, at(18))
+	require.Equal(t, approves, res, "the approve given again counts")
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// FileContext is a part of a file at the reviewed revision, it is added to the AI review prompt
+// to let the model see code around the changes
+type FileContext struct {
+	Path string
+	// FromLine and ToLine are 1-based numbers of the first and the last lines of Content
+	FromLine int
+	ToLine   int
+	Content  string
+	// Related is true for files which are not changed, but related to the changed ones (e.g. tests)
+	Related bool
+}
+
+// maxFunctionLines limits the enclosing declaration, larger ones fall back to the lines window
+const maxFunctionLines = 300
+
+// FileFetcher returns content of the file at the reviewed revision, nil if the file does not exist
+type FileFetcher func(path string) ([]byte, error)
+
+// reviewContext fetches the code around the changes at the ref and masks secrets in it
+func (s *Service) reviewContext(projectID int, ref string, diffs []*Diff) []*FileContext {
+	project, ok := s.projects[projectID]
+	if !ok || !project.ReviewContext.Enabled {
+		return nil
+	}
+
+	contexts := BuildReviewContext(project.ReviewContext, diffs, func(path string) ([]byte, error) {
+		return s.gitlab.GetRawFile(projectID, path, ref)
+	})
+
+	pr := s.projectRedaction(projectID)
+	if pr == nil || pr.settings.Disabled {
+		return contexts
+	}
+
+	for _, fc := range contexts {
+		fc.Content, _ = pr.redactor.Redact(fc.Content)
+	}
+
+	return contexts
+}
+
+// BuildReviewContext collects code around changed hunks and related files within the token budget.
+// Changed files context goes first, so related files are cut off first when the budget is exceeded.
+func BuildReviewContext(settings ds.ReviewContextSettings, diffs []*Diff, fetch FileFetcher) []*FileContext {
+	budget := settings.TokenBudget()
+	contexts := make([]*FileContext, 0, len(diffs))
+
+	add := func(fc *FileContext) bool {
+		tokens := estimateTokens(fc.Content)
+		if tokens > budget {
+			fc.Content, fc.ToLine = cutLines(fc.Content, fc.FromLine, budget)
+			budget = 0
+			if fc.Content != "" {
+				contexts = append(contexts, fc)
+			}
+
+			return false
+		}
+
+		budget -= tokens
+		contexts = append(contexts, fc)
+
+		return true
+	}
+
+	changed := make(map[string]bool, len(diffs))
+	for _, diff := range diffs {
+		changed[diff.NewPath] = true
+	}
+
+	for _, diff := range diffs {
+		// new files are entirely in the diff
+		if diff.DeletedFile || diff.NewFile || ignoredByAI(diff) {
+			continue
+		}
+
+		lines, ok := fetchLines(fetch, diff.NewPath)
+		if !ok {
+			continue
+		}
+
+		for _, r := range contextRanges(settings, lines, ParseHunks(diff.Content)) {
+			if !add(&FileContext{
+				Path:     diff.NewPath,
+				FromLine: r.from,
+				ToLine:   r.to,
+				Content:  strings.Join(lines[r.from-1:r.to], "\n"),
+			}) {
+				return contexts
+			}
+		}
+	}
+
+	if !settings.RelatedFiles {
+		return contexts
+	}
+
+	for _, diff := range diffs {
+		if diff.DeletedFile {
+			continue
+		}
+
+		for _, related := range RelatedPaths(diff.NewPath) {
+			if changed[related] {
+				continue
+			}
+
+			// the same file may be related to several changed ones
+			changed[related] = true
+
+			lines, ok := fetchLines(fetch, related)
+			if !ok {
+				continue
+			}
+
+			if !add(&FileContext{
+				Path:     related,
+				FromLine: 1,
+				ToLine:   len(lines),
+				Content:  strings.Join(lines, "\n"),
+				Related:  true,
+			}) {
+				return contexts
+			}
+		}
+	}
+
+	return contexts
+}
+
+func fetchLines(fetch FileFetcher, filePath string) ([]string, bool) {
+	content, err := fetch(filePath)
+	if err != nil {
+		log.Error().Err(err).Str("path", filePath).Msg("failed to fetch file for review context")
+		return nil, false
+	}
+
+	// not found or binary
+	if len(content) == 0 || bytes.IndexByte(content, 0) >= 0 {
+		return nil, false
+	}
+
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n"), true
+}
+
+type lineRange struct {
+	from, to int
+}
+
+// contextRanges returns merged 1-based ranges of lines around the hunks
+func contextRanges(settings ds.ReviewContextSettings, lines []string, hunks []Hunk) []lineRange {
+	ranges := make([]lineRange, 0, len(hunks))
+
+	for _, h := range hunks {
+		from, to := h.NewStart, h.NewStart+h.NewLines-1
+		if to < from {
+			to = from
+		}
+
+		r := lineRange{from: from - settings.ContextLines(), to: to + settings.ContextLines()}
+
+		if settings.WholeFunction {
+			if start, end, ok := enclosingDeclaration(lines, from, to); ok {
+				r = lineRange{from: start, to: end}
+			}
+		}
+
+		if r.from < 1 {
+			r.from = 1
+		}
+
+		if r.to > len(lines) {
+			r.to = len(lines)
+		}
+
+		if r.from > r.to {
+			continue
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].from < ranges[j].from
+	})
+
+	merged := make([]lineRange, 0, len(ranges))
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && r.from <= merged[last].to+1 {
+			if r.to > merged[last].to {
+				merged[last].to = r.to
+			}
+
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// enclosingDeclaration finds the top-level declaration (function, type, class) which contains lines from..to.
+// It is a language-agnostic heuristic: a declaration starts at a not indented line and ends
+// with a not indented closing bracket or right before the next not indented line.
+func enclosingDeclaration(lines []string, from, to int) (int, int, bool) {
+	if from < 1 || from > len(lines) {
+		return 0, 0, false
+	}
+
+	start := 0
+	for i := from; i >= 1; i-- {
+		if topLevel(lines[i-1]) && !closing(lines[i-1]) {
+			start = i
+			break
+		}
+	}
+
+	if start == 0 {
+		return 0, 0, false
+	}
+
+	end := len(lines)
+
+	for i := start + 1; i <= len(lines); i++ {
+		if !topLevel(lines[i-1]) {
+			continue
+		}
+
+		candidate := i - 1
+		if closing(lines[i-1]) {
+			candidate = i
+		}
+
+		// the changes may span several declarations
+		if candidate >= to {
+			end = candidate
+			break
+		}
+	}
+
+	if end-start+1 > maxFunctionLines {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
+func topLevel(line string) bool {
+	return line != "" && line[0] != ' ' && line[0] != '\t'
+}
+
+func closing(line string) bool {
+	return strings.HasPrefix(line, "}") || strings.HasPrefix(line, ")") || strings.HasPrefix(line, "]")
+}
+
+// RelatedPaths returns conventional paths of the test file for the source file and vice versa
+func RelatedPaths(filePath string) []string {
+	dir, file := path.Split(filePath)
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+
+	switch ext {
+	case ".go":
+		if strings.HasSuffix(base, "_test") {
+			return []string{dir + strings.TrimSuffix(base, "_test") + ext}
+		}
+
+		return []string{dir + base + "_test" + ext}
+	case ".js", ".jsx", ".ts", ".tsx":
+		for _, suffix := range []string{".test", ".spec"} {
+			if strings.HasSuffix(base, suffix) {
+				return []string{dir + strings.TrimSuffix(base, suffix) + ext}
+			}
+		}
+
+		return []string{dir + base + ".test" + ext, dir + base + ".spec" + ext}
+	case ".py":
+		if strings.HasPrefix(base, "test_") {
+			return []string{dir + strings.TrimPrefix(base, "test_") + ext}
+		}
+
+		return []string{dir + "test_" + base + ext}
+	}
+
+	return nil
+}
+
+// estimateTokens is a rough estimation of tokens for code, ~4 characters per token
+func estimateTokens(s string) int {
+	return len(s)/4 + 1
+}
+
+// cutLines keeps the first lines of the content fitting the token budget
+func cutLines(content string, fromLine int, budget int) (string, int) {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		budget -= estimateTokens(line + "\n")
+		if budget < 0 {
+			break
+		}
+
+		kept = append(kept, line)
+	}
+
+	return strings.Join(kept, "\n"), fromLine + len(kept) - 1
+}
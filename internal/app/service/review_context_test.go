@@ -0,0 +1,146 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+const goFile = `package util
+
+import "strings"
+
+// Upper makes s upper
+func Upper(s string) string {
+	s = strings.TrimSpace(s)
+	return strings.ToUpper(s)
+}
+
+// Lower makes s lower
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
+`
+
+func fakeFetcher(files map[string]string) FileFetcher {
+	return func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, nil
+		}
+
+		return []byte(content), nil
+	}
+}
+
+func TestParseHunks(t *testing.T) {
+	t.Parallel()
+
+	hunks := ParseHunks("@@ -1,3 +1,4 @@ package main\n+\n@@ -10 +11,0 @@\n-x")
+	require.Equal(t, []Hunk{
+		{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 4},
+		{OldStart: 10, OldLines: 1, NewStart: 11, NewLines: 0},
+	}, hunks)
+}
+
+func TestBuildReviewContext(t *testing.T) {
+	t.Parallel()
+
+	changed := &Diff{
+		OldPath: "util/util.go",
+		NewPath: "util/util.go",
+		Content: "@@ -7,1 +7,1 @@\n-	s = strings.Trim(s, \" \")\n+	s = strings.TrimSpace(s)",
+	}
+	files := map[string]string{
+		"util/util.go":      goFile,
+		"util/util_test.go": "package util\n\nfunc TestUpper(t *testing.T) {}\n",
+	}
+
+	tests := []struct {
+		name     string
+		settings ds.ReviewContextSettings
+		want     []*FileContext
+	}{
+		{
+			name:     "lines around the hunk",
+			settings: ds.ReviewContextSettings{Enabled: true, Lines: 1},
+			want: []*FileContext{
+				{Path: "util/util.go", FromLine: 6, ToLine: 8, Content: "func Upper(s string) string {\n\ts = strings.TrimSpace(s)\n\treturn strings.ToUpper(s)"},
+			},
+		},
+		{
+			name:     "whole function",
+			settings: ds.ReviewContextSettings{Enabled: true, WholeFunction: true},
+			want: []*FileContext{
+				{Path: "util/util.go", FromLine: 6, ToLine: 9, Content: "func Upper(s string) string {\n\ts = strings.TrimSpace(s)\n\treturn strings.ToUpper(s)\n}"},
+			},
+		},
+		{
+			name:     "with related test file",
+			settings: ds.ReviewContextSettings{Enabled: true, Lines: 1, RelatedFiles: true},
+			want: []*FileContext{
+				{Path: "util/util.go", FromLine: 6, ToLine: 8, Content: "func Upper(s string) string {\n\ts = strings.TrimSpace(s)\n\treturn strings.ToUpper(s)"},
+				{Path: "util/util_test.go", FromLine: 1, ToLine: 3, Content: "package util\n\nfunc TestUpper(t *testing.T) {}", Related: true},
+			},
+		},
+		{
+			name:     "token budget",
+			settings: ds.ReviewContextSettings{Enabled: true, Lines: 1, RelatedFiles: true, MaxTokens: 20},
+			want: []*FileContext{
+				{Path: "util/util.go", FromLine: 6, ToLine: 7, Content: "func Upper(s string) string {\n\ts = strings.TrimSpace(s)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := BuildReviewContext(tt.settings, []*Diff{changed}, fakeFetcher(files))
+			require.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func TestBuildReviewContext_SkipsNewAndMissingFiles(t *testing.T) {
+	t.Parallel()
+
+	diffs := []*Diff{
+		{NewPath: "new.go", OldPath: "new.go", NewFile: true, Content: "@@ -0,0 +1,2 @@\n+package new"},
+		{NewPath: "missing.go", OldPath: "missing.go", Content: "@@ -1,1 +1,1 @@\n-a\n+b"},
+	}
+
+	actual := BuildReviewContext(ds.ReviewContextSettings{Enabled: true}, diffs, fakeFetcher(map[string]string{
+		"new.go": "package new\n",
+	}))
+	require.Empty(t, actual)
+}
+
+func TestRelatedPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"pkg/a/client.go":      {"pkg/a/client_test.go"},
+		"pkg/a/client_test.go": {"pkg/a/client.go"},
+		"src/app.ts":           {"src/app.test.ts", "src/app.spec.ts"},
+		"src/app.spec.tsx":     {"src/app.tsx"},
+		"bot/handler.py":       {"bot/test_handler.py"},
+		"bot/test_handler.py":  {"bot/handler.py"},
+		"README.md":            nil,
+	}
+
+	for in, want := range tests {
+		require.Equal(t, want, RelatedPaths(in), in)
+	}
+}
+
+func TestComposeMessageForAI_WithContext(t *testing.T) {
+	t.Parallel()
+
+	msg := ComposeMessageForAI("title", "description", []*Diff{{OldPath: "a.go", NewPath: "a.go", Content: "+x\n"}},
+		&FileContext{Path: "a.go", FromLine: 10, ToLine: 11, Content: "func a() {\n}"})
+
+	require.Equal(t, "title\ndescription\n+x\n"+
+		"\n\nCode context of the changes (not changed lines are for reference only):\n"+
+		"\nchanged file a.go, lines 10-11:\n10: func a() {\n11: }\n", msg)
+}
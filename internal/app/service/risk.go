@@ -0,0 +1,251 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+const (
+	riskMediumLines = 100
+	riskHighLines   = 500
+	riskMediumFiles = 5
+	riskHighFiles   = 20
+)
+
+// riskyAreas raise the level to medium at least
+var riskyAreas = []string{"db", "infra"}
+
+// DiffStats is a size of the change
+type DiffStats struct {
+	Files   int
+	Added   int
+	Removed int
+}
+
+func CountDiffStats(diffs []*Diff) DiffStats {
+	stats := DiffStats{Files: len(diffs)}
+
+	for _, diff := range diffs {
+		for _, line := range strings.Split(diff.Content, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			case strings.HasPrefix(line, "+"):
+				stats.Added++
+			case strings.HasPrefix(line, "-"):
+				stats.Removed++
+			}
+		}
+	}
+
+	return stats
+}
+
+// TouchedAreas returns sorted names of the areas with at least one changed path
+func TouchedAreas(areas map[string][]string, diffs []*Diff) []string {
+	touched := make([]string, 0, len(areas))
+
+	for area, patterns := range areas {
+		for _, diff := range diffs {
+			if glob.MatchAny(patterns, diff.NewPath) || glob.MatchAny(patterns, diff.OldPath) {
+				touched = append(touched, area)
+				break
+			}
+		}
+	}
+
+	sort.Strings(touched)
+
+	return touched
+}
+
+// ClassifyRisk estimates the risk by diff stats and touched paths
+func ClassifyRisk(settings ds.RiskSettings, diffs []*Diff) *ds.Risk {
+	stats := CountDiffStats(diffs)
+	areas := TouchedAreas(settings.AreaGlobs(), diffs)
+	lines := stats.Added + stats.Removed
+
+	level := ds.RiskLow
+	switch {
+	case lines > riskHighLines || stats.Files > riskHighFiles:
+		level = ds.RiskHigh
+	case lines > riskMediumLines || stats.Files > riskMediumFiles || len(lo.Intersect(areas, riskyAreas)) > 0:
+		level = ds.RiskMedium
+	}
+
+	return &ds.Risk{
+		Level:  level,
+		Areas:  areas,
+		Reason: fmt.Sprintf("%d changed lines in %d files", lines, stats.Files),
+	}
+}
+
+// llmRisk is the judgement the LLM is asked to reply with
+type llmRisk struct {
+	Level  ds.RiskLevel `json:"level"`
+	Areas  []string     `json:"areas"`
+	Reason string       `json:"reason"`
+}
+
+// ParseLLMRisk parses the JSON object of the reply, text around the object is ignored
+func ParseLLMRisk(reply string) (*ds.Risk, error) {
+	from := strings.Index(reply, "{")
+	to := strings.LastIndex(reply, "}")
+	if from < 0 || to < from {
+		return nil, errors.New("no json object in the reply")
+	}
+
+	var judgement llmRisk
+
+	err := json.Unmarshal([]byte(reply[from:to+1]), &judgement)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid json in the reply")
+	}
+
+	judgement.Level = ds.RiskLevel(strings.ToLower(string(judgement.Level)))
+	if judgement.Level.Rank() == 0 {
+		return nil, errors.Errorf("unknown risk level %q", judgement.Level)
+	}
+
+	return &ds.Risk{
+		Level:  judgement.Level,
+		Areas:  judgement.Areas,
+		Reason: judgement.Reason,
+	}, nil
+}
+
+// MergeRisk takes the higher level and the union of known areas, the LLM reason is preferred
+func MergeRisk(heuristic, judgement *ds.Risk, knownAreas map[string][]string) *ds.Risk {
+	if judgement == nil {
+		return heuristic
+	}
+
+	merged := *heuristic
+	if judgement.Level.Rank() > merged.Level.Rank() {
+		merged.Level = judgement.Level
+	}
+
+	areas := lo.Filter(judgement.Areas, func(area string, _ int) bool {
+		_, ok := knownAreas[area]
+		return ok
+	})
+	merged.Areas = lo.Uniq(append(append([]string{}, heuristic.Areas...), areas...))
+	sort.Strings(merged.Areas)
+
+	if judgement.Reason != "" {
+		merged.Reason = judgement.Reason
+	}
+
+	return &merged
+}
+
+// RiskMessageForAI describes the change for the risk judgement
+func RiskMessageForAI(mr *ds.MergeRequest, areas map[string][]string, diffs []*Diff) string {
+	stats := CountDiffStats(diffs)
+	names := lo.Keys(areas)
+	sort.Strings(names)
+
+	var msg strings.Builder
+
+	msg.WriteString("Classify the risk of the merge request. Reply with a JSON object only: " +
+		`{"level": "low|medium|high", "areas": [...], "reason": "one sentence"}.` + "\n")
+	msg.WriteString(fmt.Sprintf("Known areas: %s.\n", strings.Join(names, ", ")))
+	msg.WriteString(fmt.Sprintf("Stats: %d files, +%d -%d lines.\n\n", stats.Files, stats.Added, stats.Removed))
+	msg.WriteString(ComposeMessageForAI(mr.Title, mr.Description, diffs))
+
+	return msg.String()
+}
+
+// RiskLabels returns labels to add and labels to remove for the risk
+func RiskLabels(settings ds.RiskSettings, risk *ds.Risk) (add, remove []string) {
+	for _, level := range []ds.RiskLevel{ds.RiskLow, ds.RiskMedium, ds.RiskHigh} {
+		if level == risk.Level {
+			add = append(add, settings.LevelLabel(level))
+		} else {
+			remove = append(remove, settings.LevelLabel(level))
+		}
+	}
+
+	areas := lo.Keys(settings.AreaGlobs())
+	sort.Strings(areas)
+
+	for _, area := range areas {
+		if lo.Contains(risk.Areas, area) {
+			add = append(add, settings.AreaLabel(area))
+		} else {
+			remove = append(remove, settings.AreaLabel(area))
+		}
+	}
+
+	return add, remove
+}
+
+// classifyRisk stores the risk of the merge request revision and labels it, if the project wants it.
+// diffs must be already redacted, they may be sent to the LLM.
+func (s *Service) classifyRisk(mr *ds.MergeRequest, diffs []*Diff) error {
+	project, ok := s.projects[mr.ProjectID]
+	if !ok || !project.Risk.Enabled {
+		return nil
+	}
+
+	settings := project.Risk
+	risk := ClassifyRisk(settings, diffs)
+
+	classifier, ok := s.openai.(RiskClassifier)
+	if settings.LLM && ok {
+		reply, err := classifier.ClassifyRisk(RiskMessageForAI(mr, settings.AreaGlobs(), diffs))
+		if err != nil {
+			log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to classify risk by llm")
+		} else {
+			judgement, err := ParseLLMRisk(reply)
+			if err != nil {
+				log.Warn().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("invalid llm risk reply")
+			}
+
+			risk = MergeRisk(risk, judgement, settings.AreaGlobs())
+		}
+	}
+
+	risk.SHA = mr.SHA
+	risk.ClassifiedAt = time.Now()
+	mr.Risk = risk
+
+	err := s.r.UpsertMergeRequest(mr)
+	if err != nil {
+		return errors.Wrap(err, "failed to store risk of merge request")
+	}
+
+	if !settings.Labels {
+		return nil
+	}
+
+	add, remove := RiskLabels(settings, risk)
+
+	err = s.gitlab.UpdateMergeRequestLabels(mr.ProjectID, mr.IID, add, remove)
+	if err != nil {
+		return errors.Wrap(err, "failed to label merge request")
+	}
+
+	return nil
+}
+
+// byRisk sorts merge requests from the highest risk, not classified ones go last
+func byRisk(mrs []*ds.MergeRequest) []*ds.MergeRequest {
+	sorted := make([]*ds.MergeRequest, len(mrs))
+	copy(sorted, mrs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RiskLevel().Rank() > sorted[j].RiskLevel().Rank()
+	})
+
+	return sorted
+}
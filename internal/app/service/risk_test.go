@@ -0,0 +1,83 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestClassifyRisk(t *testing.T) {
+	t.Parallel()
+
+	small := &Diff{NewPath: "internal/app/service/service.go", Content: "@@ -1 +1 @@\n-a\n+b\n"}
+	migration := &Diff{NewPath: "migrations/0001_init.up.sql", Content: "@@ -0,0 +1 @@\n+CREATE TABLE a();\n"}
+	large := &Diff{NewPath: "web/app.tsx", Content: "@@ -0,0 +1,600 @@\n" + strings.Repeat("+line\n", 600)}
+
+	tests := []struct {
+		name  string
+		diffs []*Diff
+		level ds.RiskLevel
+		areas []string
+	}{
+		{name: "small change", diffs: []*Diff{small}, level: ds.RiskLow, areas: []string{}},
+		{name: "migration", diffs: []*Diff{small, migration}, level: ds.RiskMedium, areas: []string{"db"}},
+		{name: "large change", diffs: []*Diff{large}, level: ds.RiskHigh, areas: []string{"ui"}},
+	}
+
+	for _, tt := range tests {
+		risk := ClassifyRisk(ds.RiskSettings{}, tt.diffs)
+		require.Equal(t, tt.level, risk.Level, tt.name)
+		require.Equal(t, tt.areas, risk.Areas, tt.name)
+	}
+}
+
+func TestParseLLMRisk(t *testing.T) {
+	t.Parallel()
+
+	risk, err := ParseLLMRisk("Sure:\n```json\n{\"level\": \"High\", \"areas\": [\"api\"], \"reason\": \"breaks clients\"}\n```")
+	require.NoError(t, err)
+	require.Equal(t, &ds.Risk{Level: ds.RiskHigh, Areas: []string{"api"}, Reason: "breaks clients"}, risk)
+
+	_, err = ParseLLMRisk(`{"level": "critical"}`)
+	require.Error(t, err)
+
+	_, err = ParseLLMRisk("no idea")
+	require.Error(t, err)
+}
+
+func TestMergeRisk(t *testing.T) {
+	t.Parallel()
+
+	heuristic := &ds.Risk{Level: ds.RiskMedium, Areas: []string{"db"}, Reason: "120 changed lines in 2 files"}
+
+	require.Equal(t, heuristic, MergeRisk(heuristic, nil, ds.DefaultRiskAreas))
+
+	require.Equal(t,
+		&ds.Risk{Level: ds.RiskHigh, Areas: []string{"api", "db"}, Reason: "breaks clients"},
+		MergeRisk(heuristic, &ds.Risk{Level: ds.RiskHigh, Areas: []string{"api", "unknown"}, Reason: "breaks clients"}, ds.DefaultRiskAreas))
+
+	require.Equal(t, ds.RiskMedium,
+		MergeRisk(heuristic, &ds.Risk{Level: ds.RiskLow}, ds.DefaultRiskAreas).Level,
+		"the llm must not lower the level")
+}
+
+func TestRiskLabels(t *testing.T) {
+	t.Parallel()
+
+	add, remove := RiskLabels(ds.RiskSettings{}, &ds.Risk{Level: ds.RiskHigh, Areas: []string{"db", "ui"}})
+	require.Equal(t, []string{"risk::high", "area:db", "area:ui"}, add, "every touched area is labeled")
+	require.Equal(t, []string{"risk::low", "risk::medium", "area:api", "area:infra"}, remove)
+}
+
+func Test_byRisk(t *testing.T) {
+	t.Parallel()
+
+	low := &ds.MergeRequest{ID: 1, Risk: &ds.Risk{Level: ds.RiskLow}}
+	high := &ds.MergeRequest{ID: 2, Risk: &ds.Risk{Level: ds.RiskHigh}}
+	unknown := &ds.MergeRequest{ID: 3}
+
+	require.Equal(t, []*ds.MergeRequest{high, low, unknown}, byRisk([]*ds.MergeRequest{unknown, low, high}))
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// changedPaths returns new and old paths of changed files without duplicates
+func changedPaths(diffs []*Diff) []string {
+	paths := make([]string, 0, len(diffs))
+
+	for _, diff := range diffs {
+		paths = append(paths, diff.NewPath)
+		if diff.OldPath != "" && diff.OldPath != diff.NewPath {
+			paths = append(paths, diff.OldPath)
+		}
+	}
+
+	return lo.Uniq(paths)
+}
+
+// involvedTeams returns IDs of teams owning any of the touched paths, teams of the author are not listed
+func involvedTeams(teams []*ds.Team, author *ds.BasicUser, paths []string) []string {
+	involved := make([]string, 0)
+
+	for _, team := range teams {
+		if len(team.Paths) == 0 || team.Teammate(author) {
+			continue
+		}
+
+		touched := lo.ContainsBy(paths, func(path string) bool {
+			return glob.MatchAny(team.Paths, path)
+		})
+
+		if touched {
+			involved = append(involved, team.ID)
+		}
+	}
+
+	return involved
+}
+
+// routeMergeRequest finds teams whose paths are touched by the new revision of the merge request,
+// changed paths are kept for matching skills of reviewers
+func (s *Service) routeMergeRequest(old, mr *ds.MergeRequest) {
+	if old != nil {
+		mr.InvolvedTeams = old.InvolvedTeams
+		mr.ChangedPaths = old.ChangedPaths
+	}
+
+	if mr.Author == nil || (old != nil && old.SHA == mr.SHA) {
+		return
+	}
+
+	if !lo.ContainsBy(s.currentTeams(), func(team *ds.Team) bool { return len(team.Paths) > 0 || len(team.Skills) > 0 }) {
+		return
+	}
+
+	diffs, err := s.gitlab.GetMergeRequestDiff(mr.ProjectID, mr.IID)
+	if err != nil {
+		// teams of the previous revision are kept
+		log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to get diff for routing")
+		return
+	}
+
+	mr.ChangedPaths = changedPaths(diffs)
+	mr.InvolvedTeams = involvedTeams(s.currentTeams(), mr.Author, mr.ChangedPaths)
+}
+
+// pendingTeams returns IDs of teams involved in the merge request whose policies don't approve it yet
+func (s *Service) pendingTeams(mr *ds.MergeRequest) []string {
+	pending := make([]string, 0)
+
+	for _, team := range s.currentTeams() {
+		if mr.Author == nil || !team.Involved(mr) {
+			continue
+		}
+
+		// teams in dry-run mode don't hold actions of other teams
+		routed, policy, ok := s.teamPolicy(team, mr)
+		if !ok || s.dryRun(routed) {
+			continue
+		}
+
+		if !policy.ApprovedByPolicy(routed, mr) {
+			pending = append(pending, team.ID)
+		}
+	}
+
+	return pending
+}
+
+// approvedByTeams checks if the merge request is approved by the policy of the team
+// and by policies of other involved teams, merge requests without a policy are approved
+func (s *Service) approvedByTeams(team *ds.Team, mr *ds.MergeRequest) bool {
+	routed, policy, ok := s.teamPolicy(team, mr)
+	if ok && !policy.ApprovedByPolicy(routed, mr) {
+		return false
+	}
+
+	// without other teams the policy of the team decides alone
+	if len(mr.InvolvedTeams) == 0 {
+		return true
+	}
+
+	return len(lo.Without(s.pendingTeams(mr), team.ID)) == 0
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestInvolvedTeams(t *testing.T) {
+	t.Parallel()
+
+	teams := []*ds.Team{
+		{ID: "backend", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 1}}}, Paths: []string{"proto/"}},
+		{ID: "platform", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 2}}}, Paths: []string{"proto/", "deploy/**/*.yml"}},
+		{ID: "mobile", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 3}}}},
+		{ID: "web", Members: []*ds.User{{BasicUser: &ds.BasicUser{GitLabID: 4}}}, Paths: []string{"web/"}},
+	}
+
+	author := &ds.BasicUser{GitLabID: 1}
+
+	tests := []struct {
+		name  string
+		diffs []*Diff
+		want  []string
+	}{
+		{name: "own paths", diffs: []*Diff{{NewPath: "cmd/main.go", OldPath: "cmd/main.go"}}, want: []string{}},
+		{name: "shared proto", diffs: []*Diff{{NewPath: "proto/api/v1/user.proto", OldPath: "proto/api/v1/user.proto"}}, want: []string{"platform"}},
+		{name: "moved out of paths", diffs: []*Diff{{NewPath: "legacy/app.js", OldPath: "web/app.js"}}, want: []string{"web"}},
+		{
+			name:  "several teams",
+			diffs: []*Diff{{NewPath: "deploy/prod/values.yml"}, {NewPath: "web/index.html"}},
+			want:  []string{"platform", "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, involvedTeams(teams, author, changedPaths(tt.diffs)), tt.name)
+	}
+}
+
+func Test_pickedSkills(t *testing.T) {
+	t.Parallel()
+
+	user := &ds.User{BasicUser: &ds.BasicUser{GitLabID: 1}, Skills: []string{"backend", "db"}}
+	other := &ds.User{BasicUser: &ds.BasicUser{GitLabID: 2}, Skills: []string{"db"}}
+
+	mr := &ds.MergeRequest{
+		ChangedPaths: []string{"migrations/001.sql"},
+		PickReasons:  []*ds.PickReason{{UserID: 1, Skills: []string{"backend", "db"}}, {UserID: 3, CodeOwner: true}},
+	}
+
+	require.Equal(t, "backend, db", pickedSkills(user, mr))
+	require.Equal(t, "", pickedSkills(other, mr), "skills of reviewers not picked for them are not listed")
+	require.Equal(t, "", pickedSkills(&ds.User{BasicUser: &ds.BasicUser{GitLabID: 3}}, mr))
+	require.Equal(t, "", pickedSkills(user, &ds.MergeRequest{}))
+}
+
+func TestService_pendingTeams(t *testing.T) {
+	t.Parallel()
+
+	live, dry := &policyStub{}, &policyStub{}
+
+	s := &Service{
+		cfg: Config{DryRunPolicies: []ds.PolicyName{"tlar"}},
+		teams: []*ds.Team{
+			{ID: "backend", Policy: "rd"},
+			{ID: "frontend", Policy: "rd", DryRun: true},
+			{ID: "mobile", Policy: "tlar"},
+		},
+		policies: map[ds.PolicyName]Policy{
+			"rd":                           live,
+			"tlar":                         live,
+			ds.PolicyName("rd").DryRun():   dry,
+			ds.PolicyName("tlar").DryRun(): dry,
+		},
+	}
+
+	mr := &ds.MergeRequest{
+		Author:        &ds.BasicUser{GitLabID: 1},
+		InvolvedTeams: []string{"backend", "frontend", "mobile"},
+	}
+
+	require.Equal(t, []string{"backend"}, s.pendingTeams(mr), "teams in dry-run mode don't hold other teams")
+
+	_, policy, ok := s.teamPolicy(s.teams[1], mr)
+	require.True(t, ok)
+	require.Same(t, dry, policy, "the dry-run policy answers for the team in dry-run mode")
+}
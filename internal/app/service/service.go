@@ -1,9 +1,10 @@
-//go:generate mockgen -source=service.go -destination=mocks/service.go -package=mocks -mock_names=Policy=Policy,SlackClient=SlackClient,Repository=Repository,GitlabClient=GitlabClient
+//go:generate mockgen -source=service.go -destination=mocks/service.go -package=mocks -mock_names=Policy=Policy,SlackClient=SlackClient,Repository=Repository,GitlabClient=GitlabClient,OpenAIClient=OpenAIClient,RiskClassifier=RiskClassifier,SettingsValidator=SettingsValidator,ReviewerPicker=ReviewerPicker
 package service
 
 import (
 	"context"
 	"github.com/rs/zerolog/log"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -21,9 +22,26 @@ type Repository interface {
 	MergeRequestsByAuthor(authorID []int) ([]*ds.MergeRequest, error)
 	MergeRequestsByReviewer(reviewerID []int) ([]*ds.MergeRequest, error)
 	UpsertMergeRequest(mr *ds.MergeRequest) error
+	// MarkMergeRequestReviewed saves the revision of the merge request reviewed successfully
+	MarkMergeRequestReviewed(mrID int, sha string) error
+	// AddPostedFindings saves keys of rule findings commented on the merge request
+	AddPostedFindings(mrID int, keys []string) error
 	CommitByID(id string) (*ds.Commit, error)
 	UpsertCommit(commit *ds.Commit) error
 	UserBySlackID(slackID string) (*ds.User, *ds.Team, error)
+	AIReviewByHash(hash string) (*ds.AIReview, error)
+	UpsertAIReview(review *ds.AIReview) error
+	UpsertAIComment(comment *ds.AIComment) error
+	AICommentsCreatedAfter(after time.Time) ([]*ds.AIComment, error)
+	AddAbsence(absence *ds.Absence) error
+	AbsencesByUser(userID int, after time.Time) ([]*ds.Absence, error)
+	ActiveAbsences(at time.Time) ([]*ds.Absence, error)
+	DeleteAbsence(userID int, id string) (bool, error)
+	ReplaceAbsences(userID int, source ds.AbsenceSource, absences []*ds.Absence) error
+	MarkAbsenceHandled(id string, mrID int) error
+	// ReviewSLA returns the SLA state of the team reviewers of the merge request, nil if not tracked yet
+	ReviewSLA(mrID int, teamID string) (*ds.ReviewSLA, error)
+	UpdateReviewSLA(sla *ds.ReviewSLA) error
 }
 
 type Diff struct {
@@ -35,11 +53,38 @@ type Diff struct {
 	DeletedFile bool
 }
 
+// CommentPosition is a line of the new version of the file in the diff
+type CommentPosition struct {
+	Path    string
+	OldPath string
+	Line    int
+}
+
+// Discussion is a reference to a created discussion and its first note
+type Discussion struct {
+	ID     string
+	NoteID int
+}
+
 type GitlabClient interface {
 	MergeRequestsByProject(projectID int, createdAfter time.Time) ([]*ds.MergeRequest, error)
 	MergeRequestApproves(projectID int, iid int) ([]*ds.BasicUser, error)
 	GetMergeRequestDiff(projectID int, iid int) ([]*Diff, error)
-	AddCommentToMergeRequests(projectID int, iid int, comment string) error
+	// MergeRequestVersions returns diff versions of the merge request from the newest one
+	MergeRequestVersions(projectID int, iid int) ([]*ds.MergeRequestVersion, error)
+	// MergeRequestVersionDiff returns changes of the merge request at the diff version
+	MergeRequestVersionDiff(projectID int, iid int, versionID int) ([]*Diff, error)
+	// GetRawFile returns content of the file at the ref, nil if the file does not exist
+	GetRawFile(projectID int, path string, ref string) ([]byte, error)
+	AddCommentToMergeRequests(projectID int, iid int, comment string) (*Discussion, error)
+	AddPositionedCommentToMergeRequest(projectID int, iid int, position CommentPosition, comment string) (*Discussion, error)
+	// MergeRequestNoteFeedback returns award emoji of the note and replies count of its discussion
+	MergeRequestNoteFeedback(projectID int, iid int, discussionID string, noteID int) (*ds.AIFeedback, error)
+	UpdateMergeRequestLabels(projectID int, iid int, add []string, remove []string) error
+	// SetReviewers overwrites reviewers list for the merge request
+	SetReviewers(mr *ds.MergeRequest, reviewers []int) error
+	// MergeRequestNotes returns comments and system notes from the oldest one
+	MergeRequestNotes(projectID int, iid int) ([]*ds.Note, error)
 
 	CommitsByProject(projectID int, createdAfter time.Time) ([]*ds.Commit, error)
 	GetCommitDiff(projectID int, commitID string) ([]*Diff, error)
@@ -48,6 +93,16 @@ type GitlabClient interface {
 
 type OpenAIClient interface {
 	GenerateAICodeReviewComment(diff string) (string, error)
+	// Model returns the name of the model used for code review
+	Model() string
+	// PromptVersion changes every time the instructions or the prompt format are changed
+	PromptVersion() string
+}
+
+// RiskClassifier is implemented by LLM clients able to judge the risk of a change
+type RiskClassifier interface {
+	// ClassifyRisk returns a JSON object with level, areas and reason
+	ClassifyRisk(message string) (string, error)
 }
 
 type SlackClient interface {
@@ -69,41 +124,103 @@ type Policy interface {
 	ApprovedByPolicy(team *ds.Team, mr *ds.MergeRequest) bool
 }
 
+// ReviewerPicker picks reviewers among candidates of the team pool like policies do (selection.Strategy)
+type ReviewerPicker interface {
+	Pick(team *ds.Team, pool string, mr *ds.MergeRequest, candidates []int, n int) ([]int, error)
+}
+
+// SettingsValidator is implemented by policies with team settings, invalid settings fail teams loading
+type SettingsValidator interface {
+	ValidateSettings(team *ds.Team) error
+}
+
+type Config struct {
+	// AIReviewCacheTTL how long AI review results are reused for the same diff
+	AIReviewCacheTTL time.Duration
+	// AIFeedback controls feedback collection on AI comments
+	AIFeedback FeedbackConfig
+	// Absence controls handling of reviews of absent users
+	Absence AbsenceConfig
+	// SLA controls checks of review SLA of teams
+	SLA SLAConfig
+	// ReReview controls re-review of approves given before big changes
+	ReReview ReReviewConfig
+	// DryRunPolicies are run in dry-run mode for all teams, teams may turn it on with dry_run as well
+	DryRunPolicies []ds.PolicyName
+}
+
 type Service struct {
+	cfg      Config
 	r        Repository
 	gitlab   GitlabClient
 	slack    SlackClient
 	openai   OpenAIClient
-	teams    []*ds.Team
 	policies map[ds.PolicyName]Policy
+	picker   ReviewerPicker
 	cron     *cron.Cron
 
+	// projects by id, loaded on subscription
+	projects map[int]*ds.Project
+
+	// redaction settings by project id
+	redaction        map[int]*projectRedaction
+	defaultRedaction *projectRedaction
+
+	// compiled diff rules by project id
+	diffRules map[int]*DiffRules
+
+	// teams and parsed review SLA by team id are replaced together on reload
+	teamsMu sync.RWMutex
+	teams   []*ds.Team
+	slas    map[string]*teamSLA
+
+	// categories of AI comments which are not posted because of negative feedback
+	suppressedMu sync.RWMutex
+	suppressed   map[string]bool
+
 	workers []Worker
 }
 
-func New(r Repository, g GitlabClient, p map[ds.PolicyName]Policy, slack SlackClient, openai OpenAIClient) (*Service, error) {
+func New(
+	cfg Config,
+	r Repository,
+	g GitlabClient,
+	p map[ds.PolicyName]Policy,
+	picker ReviewerPicker,
+	slack SlackClient,
+	openai OpenAIClient,
+) (*Service, error) {
 	svc := &Service{
+		cfg:      cfg,
 		r:        r,
 		gitlab:   g,
 		slack:    slack,
 		openai:   openai,
-		teams:    nil,
 		policies: p,
+		picker:   picker,
 		cron:     nil,
 		workers:  nil,
 	}
 
-	// TODO: team hot reload (just don't save it in service)
 	err := svc.loadTeams()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to pre-cache teams")
 	}
 
+	err = svc.initRedaction(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init redaction")
+	}
+
 	err = svc.initNotifications()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init notifications")
 	}
 
+	svc.initFeedback()
+	svc.initAbsences()
+	svc.initSLA()
+
 	return svc, nil
 }
 
@@ -131,7 +248,7 @@ func (s *Service) SubscribeOnSlack() error {
 		return errors.Wrap(err, "failed to subscribe on slack events")
 	}
 
-	wrk := worker.NewSlackWorker(s, s.r, s.slack, events)
+	wrk := worker.NewSlackWorker(s, s, s.r, s.slack, events)
 	go wrk.Run()
 
 	s.workers = append(s.workers, wrk)
@@ -154,6 +271,21 @@ func (s *Service) SubscribeOnProjects(pullPeriod time.Duration) error {
 		log.Warn().Msg("no project found")
 	}
 
+	s.projects = make(map[int]*ds.Project, len(projects))
+	for _, project := range projects {
+		s.projects[project.ID] = project
+	}
+
+	err = s.initRedaction(projects)
+	if err != nil {
+		return errors.Wrap(err, "failed to init redaction")
+	}
+
+	err = s.initDiffRules(projects)
+	if err != nil {
+		return errors.Wrap(err, "failed to init diff rules")
+	}
+
 	for _, project := range projects {
 		log.Info().Str("project_name", project.Name).Msg("init project watcher of")
 		var wrk Worker
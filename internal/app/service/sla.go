@@ -0,0 +1,492 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/pkg/workhours"
+)
+
+type SLAConfig struct {
+	// CheckPeriod is how often reviews are checked against SLA of teams, 0 disables it
+	CheckPeriod time.Duration
+}
+
+type slaKind string
+
+const (
+	slaFirstResponse slaKind = "first_response"
+	slaApprove       slaKind = "approve"
+)
+
+type slaStep string
+
+// steps fire in this order, every step fires once per reviewer and kind
+const (
+	slaNudge    slaStep = "nudge"
+	slaEscalate slaStep = "escalate"
+	slaReassign slaStep = "reassign"
+)
+
+// teamSLA is parsed SLASettings of the team
+type teamSLA struct {
+	calendar      *workhours.Calendar
+	limits        map[slaKind]time.Duration
+	escalateAfter time.Duration
+	escalateTo    ds.SLAEscalationTarget
+	// reassignAfter is negative if reviewers are not replaced
+	reassignAfter time.Duration
+}
+
+// parseSLA validates settings of the team and applies defaults
+func parseSLA(settings *ds.SLASettings) (*teamSLA, error) {
+	wh := settings.WorkingHours
+
+	loc, err := time.LoadLocation(wh.Timezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid timezone")
+	}
+
+	if wh.Start == "" {
+		wh.Start = "10:00"
+	}
+
+	if wh.End == "" {
+		wh.End = "19:00"
+	}
+
+	if len(wh.Weekdays) == 0 {
+		wh.Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+
+	calendar, err := workhours.New(loc, wh.Start, wh.End, wh.Weekdays)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid working hours")
+	}
+
+	sla := &teamSLA{
+		calendar:      calendar,
+		limits:        make(map[slaKind]time.Duration, 2),
+		escalateTo:    settings.EscalateTo,
+		reassignAfter: -1,
+	}
+
+	parse := func(name, value string) (time.Duration, error) {
+		d, err := calendar.ParseDuration(value)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid %s", name)
+		}
+
+		return d, nil
+	}
+
+	for kind, value := range map[slaKind]string{slaFirstResponse: settings.FirstResponse, slaApprove: settings.Approve} {
+		if value == "" {
+			continue
+		}
+
+		sla.limits[kind], err = parse(string(kind), value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(sla.limits) == 0 {
+		return nil, errors.New("first_response or approve is required")
+	}
+
+	if settings.EscalateAfter != "" {
+		sla.escalateAfter, err = parse("escalate_after", settings.EscalateAfter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.ReassignAfter != "" {
+		sla.reassignAfter, err = parse("reassign_after", settings.ReassignAfter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch sla.escalateTo {
+	case "":
+		sla.escalateTo = ds.SLAEscalateToLeads
+	case ds.SLAEscalateToLeads, ds.SLAEscalateToChannel:
+	default:
+		return nil, errors.Errorf("unknown escalate_to %q", sla.escalateTo)
+	}
+
+	return sla, nil
+}
+
+// dueSteps returns steps of the kind due at the moment in the order of firing
+func (t *teamSLA) dueSteps(kind slaKind, requestedAt, now time.Time) []slaStep {
+	limit, ok := t.limits[kind]
+	if !ok {
+		return nil
+	}
+
+	steps := make([]slaStep, 0, 3)
+
+	breach := t.calendar.Add(requestedAt, limit)
+	if now.Before(breach) {
+		return steps
+	}
+
+	steps = append(steps, slaNudge)
+
+	escalation := t.calendar.Add(breach, t.escalateAfter)
+	if now.Before(escalation) {
+		return steps
+	}
+
+	steps = append(steps, slaEscalate)
+
+	if t.reassignAfter >= 0 && !now.Before(t.calendar.Add(escalation, t.reassignAfter)) {
+		steps = append(steps, slaReassign)
+	}
+
+	return steps
+}
+
+func slaFired(reviewer *ds.SLAReviewer, kind slaKind, step slaStep) bool {
+	return lo.Contains(reviewer.Fired, string(kind)+":"+string(step))
+}
+
+func (s *Service) initSLA() {
+	if s.cfg.SLA.CheckPeriod <= 0 {
+		return
+	}
+
+	s.cron.Schedule(cron.Every(s.cfg.SLA.CheckPeriod), cron.FuncJob(s.checkSLA))
+}
+
+// checkSLA nudges, escalates and replaces reviewers of open merge requests of teams with SLA
+func (s *Service) checkSLA() {
+	now := time.Now()
+
+	absent, err := s.AbsentUsers(now)
+	if err != nil {
+		// absent reviewers are handled separately, escalating them is noisy but not harmful
+		log.Error().Err(err).Msg("failed to get absent users")
+	}
+
+	for _, team := range s.currentTeams() {
+		sla, ok := s.teamSLA(team.ID)
+		if !ok {
+			continue
+		}
+
+		mrs, err := s.r.MergeRequestsByReviewer(lo.Map(team.Members, func(member *ds.User, _ int) int {
+			return member.GitLabID
+		}))
+		if err != nil {
+			log.Error().Err(err).Str("team_id", team.ID).Msg("failed to get merge requests of the team")
+			continue
+		}
+
+		for _, mr := range mrs {
+			if !mr.State.Is(ds.StateOpened) || mr.Draft || mr.Author == nil || !team.Involved(mr) {
+				continue
+			}
+
+			// reviewers of teams in dry-run mode are not managed by the bot
+			routed, policy, ok := s.teamPolicy(team, mr)
+			if !ok || s.dryRun(routed) {
+				continue
+			}
+
+			// reviewers of the team are done when its policy approves, even if other teams don't
+			if policy.ApprovedByPolicy(routed, mr) {
+				continue
+			}
+
+			err = s.checkMergeRequestSLA(team, sla, mr, absent, now)
+			if err != nil {
+				log.Error().Err(err).Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("failed to check review SLA")
+			}
+		}
+	}
+}
+
+func (s *Service) checkMergeRequestSLA(team *ds.Team, sla *teamSLA, mr *ds.MergeRequest, absent map[int]bool, now time.Time) error {
+	state, err := s.r.ReviewSLA(mr.ID, team.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get review sla")
+	}
+
+	if state == nil {
+		state = &ds.ReviewSLA{MergeRequestID: mr.ID, TeamID: team.ID}
+	}
+
+	var (
+		notes   []*ds.Note
+		fireErr error
+	)
+
+	// the timeline is fetched for times of new review requests or if the first response is overdue
+	if len(untrackedSLAReviewers(state.Reviewers, team, mr)) > 0 {
+		notes, err = s.gitlab.MergeRequestNotes(mr.ProjectID, mr.IID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get notes")
+		}
+	}
+
+	state.Reviewers = syncSLAReviewers(state.Reviewers, team, mr, notes, now)
+
+reviewers:
+	for _, reviewer := range state.Reviewers {
+		if absent[reviewer.ID] {
+			continue
+		}
+
+		approved := lo.ContainsBy(mr.Approves, func(user *ds.BasicUser) bool { return user.GitLabID == reviewer.ID })
+
+		if reviewer.RespondedAt == nil && approved {
+			reviewer.RespondedAt = lo.ToPtr(now)
+		}
+
+		if reviewer.RespondedAt == nil && len(sla.dueSteps(slaFirstResponse, reviewer.RequestedAt, now)) > 0 {
+			if notes == nil {
+				notes, err = s.gitlab.MergeRequestNotes(mr.ProjectID, mr.IID)
+				if err != nil {
+					fireErr = errors.Wrap(err, "failed to get notes")
+					break reviewers
+				}
+			}
+
+			reviewer.RespondedAt = firstResponse(notes, reviewer.ID, reviewer.RequestedAt)
+		}
+
+		kinds := make([]slaKind, 0, 2)
+		if reviewer.RespondedAt == nil {
+			kinds = append(kinds, slaFirstResponse)
+		}
+
+		if !approved {
+			kinds = append(kinds, slaApprove)
+		}
+
+		for _, kind := range kinds {
+			// fired steps are saved even if the next one fails
+			fireErr = s.fireSLASteps(team, sla, mr, reviewer, kind, absent, now)
+			if fireErr != nil {
+				break reviewers
+			}
+
+			// replaced already
+			if !lo.ContainsBy(mr.Reviewers, func(user *ds.BasicUser) bool { return user.GitLabID == reviewer.ID }) {
+				break
+			}
+		}
+	}
+
+	err = s.r.UpdateReviewSLA(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to save review sla")
+	}
+
+	return fireErr
+}
+
+// slaReviewers returns reviewers of the merge request from the team tracked by SLA
+func slaReviewers(team *ds.Team, mr *ds.MergeRequest) []*ds.BasicUser {
+	return lo.Filter(mr.Reviewers, func(reviewer *ds.BasicUser, _ int) bool {
+		return reviewer.GitLabID != mr.Author.GitLabID && team.Teammate(reviewer)
+	})
+}
+
+func untrackedSLAReviewers(tracked []*ds.SLAReviewer, team *ds.Team, mr *ds.MergeRequest) []*ds.BasicUser {
+	return lo.Filter(slaReviewers(team, mr), func(reviewer *ds.BasicUser, _ int) bool {
+		return !lo.ContainsBy(tracked, func(r *ds.SLAReviewer) bool { return r.ID == reviewer.GitLabID })
+	})
+}
+
+// syncSLAReviewers starts tracking new reviewers from the team and forgets removed ones
+func syncSLAReviewers(tracked []*ds.SLAReviewer, team *ds.Team, mr *ds.MergeRequest, notes []*ds.Note, now time.Time) []*ds.SLAReviewer {
+	res := make([]*ds.SLAReviewer, 0, len(mr.Reviewers))
+
+	for _, reviewer := range slaReviewers(team, mr) {
+		state, ok := lo.Find(tracked, func(r *ds.SLAReviewer) bool { return r.ID == reviewer.GitLabID })
+		if !ok {
+			state = &ds.SLAReviewer{ID: reviewer.GitLabID, RequestedAt: reviewRequestedAt(notes, reviewer.GitLabID, now), Fired: []string{}}
+		}
+
+		res = append(res, state)
+	}
+
+	return res
+}
+
+// reviewRequestedAt returns the time of the last review request of the user in the timeline,
+// now if there is none (e.g. the timeline is not available)
+func reviewRequestedAt(notes []*ds.Note, userID int, now time.Time) time.Time {
+	requestedAt := now
+
+	for _, note := range notes {
+		if note.CreatedAt.After(now) {
+			break
+		}
+
+		if lo.Contains(note.ReviewRequested, userID) {
+			requestedAt = note.CreatedAt
+		}
+	}
+
+	return requestedAt
+}
+
+// firstResponse returns the time of the first comment or approve of the user since the review request
+func firstResponse(notes []*ds.Note, userID int, since time.Time) *time.Time {
+	for _, note := range notes {
+		if note.AuthorID == userID && !note.CreatedAt.Before(since) && (!note.System || note.IsApprove()) {
+			return lo.ToPtr(note.CreatedAt)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) fireSLASteps(
+	team *ds.Team,
+	sla *teamSLA,
+	mr *ds.MergeRequest,
+	reviewer *ds.SLAReviewer,
+	kind slaKind,
+	absent map[int]bool,
+	now time.Time,
+) error {
+	for _, step := range sla.dueSteps(kind, reviewer.RequestedAt, now) {
+		if slaFired(reviewer, kind, step) {
+			continue
+		}
+
+		var err error
+
+		switch step {
+		case slaNudge:
+			err = s.nudgeReviewer(team, mr, reviewer.ID, kind)
+		case slaEscalate:
+			err = s.escalateReview(team, sla, mr, reviewer.ID, kind)
+		case slaReassign:
+			err = s.reassignOverdueReview(mr, reviewer.ID, absent)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to %s %s", step, kind)
+		}
+
+		reviewer.Fired = append(reviewer.Fired, string(kind)+":"+string(step))
+	}
+
+	return nil
+}
+
+func slaWaiting(kind slaKind) string {
+	if kind == slaFirstResponse {
+		return "waits for your first response"
+	}
+
+	return "waits for your approve"
+}
+
+// nudgeReviewer reminds the reviewer in Slack, or with a comment if the reviewer has no Slack
+func (s *Service) nudgeReviewer(team *ds.Team, mr *ds.MergeRequest, reviewerID int, kind slaKind) error {
+	member, ok := lo.Find(team.Members, func(member *ds.User) bool { return member.GitLabID == reviewerID })
+	if ok && member.SlackID != "" {
+		return s.slack.SendMessage(member.SlackID, fmt.Sprintf(":hourglass: <%s|%s> %s.", mr.URL, mr.Title, slaWaiting(kind)))
+	}
+
+	name := fmt.Sprintf("user %d", reviewerID)
+	if ok && member.Name != "" {
+		name = member.Name
+	}
+
+	_, err := s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID,
+		fmt.Sprintf(":hourglass: **%s**, the merge request %s.", name, slaWaiting(kind)))
+
+	return err
+}
+
+// escalateReview tells leads or the team channel about the overdue review
+func (s *Service) escalateReview(team *ds.Team, sla *teamSLA, mr *ds.MergeRequest, reviewerID int, kind slaKind) error {
+	name := fmt.Sprintf("user %d", reviewerID)
+	if member := s.teammate(reviewerID); member != nil && member.Name != "" {
+		name = member.Name
+	}
+
+	what := "first response"
+	if kind == slaApprove {
+		what = "approve"
+	}
+
+	msg := fmt.Sprintf(":rotating_light: <%s|%s> is still waiting for the %s of %s.", mr.URL, mr.Title, what, name)
+
+	recipients := make([]string, 0)
+
+	switch sla.escalateTo {
+	case ds.SLAEscalateToChannel:
+		if team.Notifications.ChannelID != "" {
+			recipients = append(recipients, team.Notifications.ChannelID)
+		}
+	default:
+		for _, lead := range ds.Leads(team.Members) {
+			if lead.SlackID != "" && lead.GitLabID != reviewerID {
+				recipients = append(recipients, lead.SlackID)
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		log.Warn().Str("team_id", team.ID).Int("iid", mr.IID).Msg("nobody to escalate the overdue review to")
+		return nil
+	}
+
+	for _, recipient := range recipients {
+		err := s.slack.SendMessage(recipient, msg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reassignOverdueReview replaces the reviewer by a present teammate with the same labels
+func (s *Service) reassignOverdueReview(mr *ds.MergeRequest, reviewerID int, absent map[int]bool) error {
+	replacement, err := s.replacementReviewer(mr, reviewerID, absent)
+	if err != nil {
+		return err
+	}
+
+	if replacement == nil {
+		log.Warn().Int("project_id", mr.ProjectID).Int("iid", mr.IID).Msg("no replacement for the overdue reviewer")
+		return nil
+	}
+
+	reviewers := lo.Filter(mr.Reviewers, func(user *ds.BasicUser, _ int) bool { return user.GitLabID != reviewerID })
+	reviewers = append(reviewers, replacement.BasicUser)
+
+	err = s.gitlab.SetReviewers(mr, lo.Map(reviewers, func(user *ds.BasicUser, _ int) int { return user.GitLabID }))
+	if err != nil {
+		return errors.Wrap(err, "failed to replace reviewer")
+	}
+
+	// other overdue reviewers of the merge request are replaced on top of it
+	mr.Reviewers = reviewers
+
+	_, err = s.gitlab.AddCommentToMergeRequests(mr.ProjectID, mr.IID,
+		":hourglass: The review is overdue and reassigned to **"+replacement.Name+"**.")
+	if err != nil {
+		return errors.Wrap(err, "failed to comment reassigned review")
+	}
+
+	return nil
+}
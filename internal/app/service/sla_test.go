@@ -0,0 +1,166 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
+)
+
+func TestParseSLA(t *testing.T) {
+	t.Parallel()
+
+	sla, err := parseSLA(&ds.SLASettings{FirstResponse: "4h", Approve: "2d"})
+	require.NoError(t, err)
+	require.Equal(t, map[slaKind]time.Duration{slaFirstResponse: 4 * time.Hour, slaApprove: 18 * time.Hour}, sla.limits)
+	require.Equal(t, ds.SLAEscalateToLeads, sla.escalateTo)
+	require.Negative(t, sla.reassignAfter, "reviewers are not replaced by default")
+
+	tests := []struct {
+		name     string
+		settings ds.SLASettings
+	}{
+		{name: "no limits", settings: ds.SLASettings{EscalateAfter: "1h"}},
+		{name: "invalid limit", settings: ds.SLASettings{FirstResponse: "soon"}},
+		{name: "invalid escalation target", settings: ds.SLASettings{FirstResponse: "4h", EscalateTo: "ceo"}},
+		{name: "invalid timezone", settings: ds.SLASettings{FirstResponse: "4h", WorkingHours: ds.WorkingHours{Timezone: "Mars/Olympus"}}},
+		{name: "invalid hours", settings: ds.SLASettings{FirstResponse: "4h", WorkingHours: ds.WorkingHours{Start: "19:00", End: "10:00"}}},
+	}
+
+	for _, tt := range tests {
+		_, err = parseSLA(&tt.settings)
+		require.Error(t, err, tt.name)
+	}
+}
+
+func TestTeamSLA_dueSteps(t *testing.T) {
+	t.Parallel()
+
+	sla, err := parseSLA(&ds.SLASettings{FirstResponse: "4h", EscalateAfter: "2h", ReassignAfter: "1d"})
+	require.NoError(t, err)
+
+	// Monday 11:00 UTC
+	requested := time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []slaStep
+	}{
+		{name: "in time", now: time.Date(2024, 3, 4, 14, 59, 0, 0, time.UTC), want: []slaStep{}},
+		{name: "breach", now: time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC), want: []slaStep{slaNudge}},
+		{name: "escalation", now: time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC), want: []slaStep{slaNudge, slaEscalate}},
+		{name: "evening is not working time", now: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), want: []slaStep{slaNudge, slaEscalate}},
+		{name: "reassign", now: time.Date(2024, 3, 5, 17, 0, 0, 0, time.UTC), want: []slaStep{slaNudge, slaEscalate, slaReassign}},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, sla.dueSteps(slaFirstResponse, requested, tt.now), tt.name)
+	}
+
+	require.Empty(t, sla.dueSteps(slaApprove, requested, requested.AddDate(0, 1, 0)), "no approve SLA")
+}
+
+func TestFirstResponse(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour int) time.Time { return time.Date(2024, 3, 4, hour, 0, 0, 0, time.UTC) }
+
+	notes := []*ds.Note{
+		{AuthorID: 1, Body: "requested review from @bob", System: true, CreatedAt: at(10)},
+		{AuthorID: 2, Body: "added 1 commit", System: true, CreatedAt: at(11)},
+		{AuthorID: 3, Body: "approved this merge request", System: true, CreatedAt: at(12)},
+		{AuthorID: 2, Body: "Why not a map?", CreatedAt: at(13)},
+	}
+
+	require.Equal(t, at(13), *firstResponse(notes, 2, at(10)), "system notes are not responses")
+	require.Equal(t, at(12), *firstResponse(notes, 3, at(10)), "approve is a response")
+	require.Nil(t, firstResponse(notes, 4, at(10)))
+	require.Nil(t, firstResponse(notes, 3, at(13)), "responses before the request are not counted")
+}
+
+func TestSyncSLAReviewers(t *testing.T) {
+	t.Parallel()
+
+	team := &ds.Team{Members: []*ds.User{
+		{BasicUser: &ds.BasicUser{GitLabID: 1}},
+		{BasicUser: &ds.BasicUser{GitLabID: 2}},
+		{BasicUser: &ds.BasicUser{GitLabID: 3}},
+	}}
+
+	before := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	now := before.Add(time.Hour)
+
+	tracked := []*ds.SLAReviewer{
+		{ID: 2, RequestedAt: before, Fired: []string{"first_response:nudge"}},
+		{ID: 3, RequestedAt: before},
+	}
+
+	mr := &ds.MergeRequest{
+		Author: &ds.BasicUser{GitLabID: 1},
+		// 3 is removed, 100 is not a teammate
+		Reviewers: []*ds.BasicUser{{GitLabID: 1}, {GitLabID: 2}, {GitLabID: 100}},
+	}
+
+	res := syncSLAReviewers(tracked, team, mr, nil, now)
+	require.Equal(t, []*ds.SLAReviewer{tracked[0]}, res)
+
+	notes := []*ds.Note{
+		{System: true, ReviewRequested: []int{2, 3}, CreatedAt: before.Add(-time.Hour)},
+		{System: true, ReviewRequested: []int{3}, CreatedAt: before.Add(30 * time.Minute)},
+		{System: true, ReviewRequested: []int{3}, CreatedAt: now.Add(time.Minute)},
+	}
+
+	mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{GitLabID: 3})
+	res = syncSLAReviewers(res, team, mr, notes, now)
+	require.Len(t, res, 2)
+	require.Equal(t, before, res[0].RequestedAt, "tracked reviewer keeps the request time")
+	require.Equal(t, before.Add(30*time.Minute), res[1].RequestedAt, "returned reviewer is tracked from the last request")
+
+	mr = &ds.MergeRequest{Author: &ds.BasicUser{GitLabID: 2}, Reviewers: []*ds.BasicUser{{GitLabID: 1}}}
+	res = syncSLAReviewers(nil, team, mr, notes, now)
+	require.Equal(t, now, res[0].RequestedAt, "reviewer without the request note is tracked from now")
+}
+
+func TestService_replacementReviewer(t *testing.T) {
+	t.Parallel()
+
+	member := func(id int, labels ...ds.UserLabel) *ds.User {
+		return &ds.User{BasicUser: &ds.BasicUser{GitLabID: id}, Labels: labels}
+	}
+
+	picker := &pickerStub{}
+	s := &Service{
+		picker: picker,
+		teams: []*ds.Team{{
+			Name: "backend",
+			Members: []*ds.User{
+				member(1, ds.DeveloperLabel), member(2, ds.DeveloperLabel), member(3, ds.DeveloperLabel),
+				member(4, ds.DeveloperLabel), member(10, ds.LeadLabel), member(11, ds.LeadLabel),
+			},
+		}},
+	}
+
+	mr := &ds.MergeRequest{
+		Author:    &ds.BasicUser{GitLabID: 1},
+		Reviewers: []*ds.BasicUser{{GitLabID: 2}, {GitLabID: 3}, {GitLabID: 10}},
+	}
+
+	replacement, err := s.replacementReviewer(mr, 2, map[int]bool{2: true})
+	require.NoError(t, err)
+	require.Equal(t, 4, replacement.GitLabID)
+	require.Equal(t, selection.PoolDevelopers, picker.pools[0])
+	require.Equal(t, []int{4}, picker.candidates[0], "present developers which are not reviewers")
+
+	replacement, err = s.replacementReviewer(mr, 10, map[int]bool{10: true})
+	require.NoError(t, err)
+	require.Equal(t, 11, replacement.GitLabID)
+	require.Equal(t, selection.PoolLeads, picker.pools[1])
+
+	replacement, err = s.replacementReviewer(mr, 2, map[int]bool{2: true, 4: true})
+	require.NoError(t, err)
+	require.Nil(t, replacement, "nobody to replace with")
+}
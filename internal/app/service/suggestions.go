@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// Suggestion is a replacement of lines of the new file version proposed by the AI review.
+//
+// The review replies with blocks like:
+//
+//	```suggestion:internal/app/service/service.go:12-13
+//	Wrap the error to keep the context
+//	-	return err
+//	-}
+//	+	return errors.Wrap(err, "failed to load")
+//	+}
+//	```
+//
+// where "-" lines are the current lines 12-13 and "+" lines replace them.
+type Suggestion struct {
+	Path string
+	// FromLine and ToLine are 1-based lines of the new file version
+	FromLine    int
+	ToLine      int
+	Comment     string
+	Original    []string
+	Replacement []string
+}
+
+var suggestionBlock = regexp.MustCompile("(?ms)^```suggestion:([^\\n:]+):(\\d+)(?:-(\\d+))?[ \\t]*\\n(.*?)^```[ \\t]*$\\n?")
+
+// ExtractSuggestions cuts suggestion blocks out of the review comment
+func ExtractSuggestions(comment string) ([]*Suggestion, string) {
+	suggestions := make([]*Suggestion, 0)
+
+	rest := suggestionBlock.ReplaceAllStringFunc(comment, func(block string) string {
+		m := suggestionBlock.FindStringSubmatch(block)
+
+		from, _ := strconv.Atoi(m[2])
+		to := from
+		if m[3] != "" {
+			to, _ = strconv.Atoi(m[3])
+		}
+
+		sg := &Suggestion{
+			Path:     strings.TrimSpace(m[1]),
+			FromLine: from,
+			ToLine:   to,
+		}
+
+		comments := make([]string, 0)
+		for _, line := range strings.Split(strings.TrimSuffix(m[4], "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				sg.Original = append(sg.Original, line[1:])
+			case strings.HasPrefix(line, "+"):
+				sg.Replacement = append(sg.Replacement, line[1:])
+			case strings.TrimSpace(line) != "":
+				comments = append(comments, line)
+			}
+		}
+
+		sg.Comment = strings.Join(comments, "\n")
+		suggestions = append(suggestions, sg)
+
+		return ""
+	})
+
+	return suggestions, strings.TrimSpace(rest)
+}
+
+// Validate checks that all lines are added by the diff, so the suggestion can be positioned on new lines only,
+// and that the original lines are the same as in the current file. added are numbers of added lines.
+func (sg *Suggestion) Validate(content []byte, added []int) error {
+	if sg.FromLine < 1 || sg.ToLine < sg.FromLine {
+		return errors.Errorf("invalid range %d-%d", sg.FromLine, sg.ToLine)
+	}
+
+	if len(sg.Original) != sg.ToLine-sg.FromLine+1 {
+		return errors.Errorf("range %d-%d has %d original lines", sg.FromLine, sg.ToLine, len(sg.Original))
+	}
+
+	// unchanged context lines need the old line in the position, which suggestions don't have
+	for line := sg.FromLine; line <= sg.ToLine; line++ {
+		if !lo.Contains(added, line) {
+			return errors.Errorf("line %d is not added by the diff", line)
+		}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if sg.ToLine > len(lines) {
+		return errors.Errorf("range %d-%d is out of the file", sg.FromLine, sg.ToLine)
+	}
+
+	for i, original := range sg.Original {
+		current := lines[sg.FromLine-1+i]
+		if strings.TrimRight(current, " \t\r") != strings.TrimRight(original, " \t\r") {
+			return errors.Errorf("line %d does not match", sg.FromLine+i)
+		}
+	}
+
+	return nil
+}
+
+// Render formats the suggestion as a GitLab suggestion, the comment must be positioned on FromLine
+func (sg *Suggestion) Render() string {
+	var msg strings.Builder
+
+	if sg.Comment != "" {
+		msg.WriteString(sg.Comment)
+		msg.WriteString("\n\n")
+	}
+
+	msg.WriteString(fmt.Sprintf("```suggestion:-0+%d\n", sg.ToLine-sg.FromLine))
+	for _, line := range sg.Replacement {
+		msg.WriteString(line)
+		msg.WriteString("\n")
+	}
+	msg.WriteString("```")
+
+	return msg.String()
+}
+
+// RenderAsDiff formats the suggestion for places without suggestions support (e.g. commit notes)
+func (sg *Suggestion) RenderAsDiff() string {
+	var msg strings.Builder
+
+	msg.WriteString(fmt.Sprintf("`%s:%d-%d`", sg.Path, sg.FromLine, sg.ToLine))
+	if sg.Comment != "" {
+		msg.WriteString(" ")
+		msg.WriteString(sg.Comment)
+	}
+
+	msg.WriteString("\n```diff\n")
+	for _, line := range sg.Original {
+		msg.WriteString("-" + line + "\n")
+	}
+	for _, line := range sg.Replacement {
+		msg.WriteString("+" + line + "\n")
+	}
+	msg.WriteString("```")
+
+	return msg.String()
+}
+
+// validSuggestions drops suggestions which don't match the file at the ref
+func (s *Service) validSuggestions(projectID int, ref string, diffs []*Diff, suggestions []*Suggestion) []*Suggestion {
+	valid := make([]*Suggestion, 0, len(suggestions))
+	files := make(map[string][]byte)
+
+	added := make(map[string][]int, len(diffs))
+	for _, diff := range diffs {
+		if !diff.DeletedFile {
+			added[diff.NewPath] = lo.Map(addedLines(diff.Content), func(line addedLine, _ int) int { return line.number })
+		}
+	}
+
+	for _, sg := range suggestions {
+		l := log.With().Int("project_id", projectID).Str("path", sg.Path).Int("from", sg.FromLine).Int("to", sg.ToLine).Logger()
+
+		fileAdded, ok := added[sg.Path]
+		if !ok {
+			l.Debug().Msg("suggestion dropped, file is not changed")
+			continue
+		}
+
+		content, ok := files[sg.Path]
+		if !ok {
+			var err error
+
+			content, err = s.gitlab.GetRawFile(projectID, sg.Path, ref)
+			if err != nil {
+				l.Error().Err(err).Msg("failed to fetch file of suggestion")
+				continue
+			}
+
+			files[sg.Path] = content
+		}
+
+		err := sg.Validate(content, fileAdded)
+		if err != nil {
+			l.Debug().Err(err).Msg("suggestion dropped")
+			continue
+		}
+
+		valid = append(valid, sg)
+	}
+
+	return valid
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const reviewWithSuggestions = "- The error is returned without context.\n" +
+	"```suggestion:internal/app/service/service.go:12-13\n" +
+	"Wrap the error to keep the context\n" +
+	"-\treturn err\n" +
+	"-}\n" +
+	"+\treturn errors.Wrap(err, \"failed to load\")\n" +
+	"+}\n" +
+	"```\n" +
+	"- Naming is inconsistent.\n" +
+	"```suggestion:main.go:3\n" +
+	"-var Foo = 1\n" +
+	"+var foo = 1\n" +
+	"```\n"
+
+func TestExtractSuggestions(t *testing.T) {
+	t.Parallel()
+
+	suggestions, rest := ExtractSuggestions(reviewWithSuggestions)
+
+	require.Equal(t, "- The error is returned without context.\n- Naming is inconsistent.", rest)
+	require.Equal(t, []*Suggestion{
+		{
+			Path:        "internal/app/service/service.go",
+			FromLine:    12,
+			ToLine:      13,
+			Comment:     "Wrap the error to keep the context",
+			Original:    []string{"\treturn err", "}"},
+			Replacement: []string{"\treturn errors.Wrap(err, \"failed to load\")", "}"},
+		},
+		{
+			Path:        "main.go",
+			FromLine:    3,
+			ToLine:      3,
+			Original:    []string{"var Foo = 1"},
+			Replacement: []string{"var foo = 1"},
+		},
+	}, suggestions)
+}
+
+func TestSuggestion_Validate(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("package main\n\nvar Foo = 1\nvar Bar = 2\n")
+	added := []int{3, 4}
+
+	valid := &Suggestion{Path: "main.go", FromLine: 3, ToLine: 3, Original: []string{"var Foo = 1 "}}
+	require.NoError(t, valid.Validate(content, added), "trailing spaces are ignored")
+
+	invalid := map[string]*Suggestion{
+		"line mismatch":   {FromLine: 3, ToLine: 3, Original: []string{"var Baz = 1"}},
+		"count mismatch":  {FromLine: 3, ToLine: 4, Original: []string{"var Foo = 1"}},
+		"context line":    {FromLine: 1, ToLine: 1, Original: []string{"package main"}},
+		"partly context":  {FromLine: 2, ToLine: 3, Original: []string{"", "var Foo = 1"}},
+		"out of the diff": {FromLine: 5, ToLine: 5, Original: []string{""}},
+		"reversed range":  {FromLine: 4, ToLine: 3},
+	}
+
+	for name, sg := range invalid {
+		require.Error(t, sg.Validate(content, added), name)
+	}
+
+	require.Error(t, valid.Validate([]byte("package main\n"), []int{3}), "out of the file")
+}
+
+func TestSuggestion_Render(t *testing.T) {
+	t.Parallel()
+
+	sg := &Suggestion{
+		Path:        "main.go",
+		FromLine:    3,
+		ToLine:      4,
+		Comment:     "Unexport",
+		Original:    []string{"var Foo = 1", "var Bar = 2"},
+		Replacement: []string{"var foo = 1"},
+	}
+
+	require.Equal(t, "Unexport\n\n```suggestion:-0+1\nvar foo = 1\n```", sg.Render())
+	require.Equal(t, "`main.go:3-4` Unexport\n```diff\n-var Foo = 1\n-var Bar = 2\n+var foo = 1\n```", sg.RenderAsDiff())
+}
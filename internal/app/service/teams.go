@@ -5,16 +5,137 @@ import (
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
 
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/service/worker"
 	"github.com/jokerlee/gitlab-review-bot/internal/pkg/logger"
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
 )
 
-func (s *Service) loadTeams() (err error) {
-	s.teams, err = s.r.Teams()
+// ReloadTeams replaces teams with stored ones (e.g. after labels of a member are changed),
+// teams are kept if stored ones are invalid
+func (s *Service) ReloadTeams() error {
+	return s.loadTeams()
+}
+
+func (s *Service) loadTeams() error {
+	teams, err := s.r.Teams()
 	if err != nil {
 		return errors.Wrap(err, "failed to load teams")
 	}
 
+	slas := make(map[string]*teamSLA)
+
+	for _, team := range teams {
+		if team.SLA != nil {
+			slas[team.ID], err = parseSLA(team.SLA)
+			if err != nil {
+				return errors.Wrapf(err, "invalid sla of team %s", team.Name)
+			}
+		}
+
+		err = ds.ValidateActions("on_approved", team.OnApproved)
+		if err != nil {
+			return errors.Wrapf(err, "invalid actions of team %s", team.Name)
+		}
+
+		for _, path := range team.Paths {
+			_, err = glob.Compile(path)
+			if err != nil {
+				return errors.Wrapf(err, "invalid paths of team %s", team.Name)
+			}
+		}
+
+		for skill, paths := range team.Skills {
+			for _, path := range paths {
+				_, err = glob.Compile(path)
+				if err != nil {
+					return errors.Wrapf(err, "invalid paths of skill %s of team %s", skill, team.Name)
+				}
+			}
+		}
+
+		err = s.validateBranches(team)
+		if err != nil {
+			return errors.Wrapf(err, "invalid branches of team %s", team.Name)
+		}
+
+		validator, ok := s.policies[team.Policy].(SettingsValidator)
+		if !ok {
+			continue
+		}
+
+		err = validator.ValidateSettings(team)
+		if err != nil {
+			return errors.Wrapf(err, "invalid policy settings of team %s", team.Name)
+		}
+	}
+
+	// teams are replaced, never changed, so readers of current teams are not affected
+	s.teamsMu.Lock()
+	s.teams, s.slas = teams, slas
+	s.teamsMu.Unlock()
+
+	return nil
+}
+
+// currentTeams returns teams loaded last
+func (s *Service) currentTeams() []*ds.Team {
+	s.teamsMu.RLock()
+	defer s.teamsMu.RUnlock()
+
+	return s.teams
+}
+
+// teamSLA returns the parsed review SLA of the team, false if the team has no SLA
+func (s *Service) teamSLA(teamID string) (*teamSLA, bool) {
+	s.teamsMu.RLock()
+	defer s.teamsMu.RUnlock()
+
+	sla, ok := s.slas[teamID]
+
+	return sla, ok
+}
+
+// validateBranches checks patterns of branch rules of the team, their policies and policy settings
+func (s *Service) validateBranches(team *ds.Team) error {
+	for i, rule := range team.Branches {
+		for _, pattern := range []string{rule.TargetBranch, rule.SourceBranch} {
+			if pattern == "" {
+				continue
+			}
+
+			_, err := glob.Compile(pattern)
+			if err != nil {
+				return errors.Wrapf(err, "rule %d", i)
+			}
+		}
+
+		policy := rule.Policy
+		if policy == "" {
+			policy = team.Policy
+		}
+
+		if policy == ds.PolicyNone {
+			continue
+		}
+
+		p, ok := s.policies[policy]
+		if !ok {
+			return errors.Errorf("rule %d: unknown policy %s", i, policy)
+		}
+
+		validator, ok := p.(SettingsValidator)
+		if !ok {
+			continue
+		}
+
+		// the team as merge requests of the rule see it
+		err := validator.ValidateSettings(team.WithRule(rule))
+		if err != nil {
+			return errors.Wrapf(err, "rule %d", i)
+		}
+	}
+
 	return nil
 }
 
@@ -26,7 +147,7 @@ func (s *Service) initNotifications() error {
 		cron.SkipIfStillRunning(l),
 	))
 
-	for _, team := range s.teams {
+	for _, team := range s.currentTeams() {
 		if !team.Notifications.Enabled {
 			continue
 		}
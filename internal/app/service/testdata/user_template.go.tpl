@@ -4,7 +4,7 @@
 {{ if len .ReviewerMR -}}
 :crossed_fingers:  *Ты ревьювер {{len .ReviewerMR}} {{plural (len .ReviewerMR) "реквеста" "реквестов" "реквестов"}}:*
 {{ range .ReviewerMR }}
-*{{.Title}}*
+*{{.Title}}*{{ with skills $.User . }} _(нужны твои навыки: {{ . }})_{{ end }}
 {{.URL}} менялся *{{.UpdatedAt | since}}*
 {{ end -}}
 {{- end }}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// TraineeStats are shadow reviews of the trainee in the team
+type TraineeStats struct {
+	Team      string
+	TraineeID int
+	// Name is empty if the trainee is not a member anymore
+	Name     string
+	Assigned int
+	// Shadowed are reviews approved by the trainee
+	Shadowed int
+	// Graduated trainees are not labeled as trainees anymore
+	Graduated bool
+}
+
+// BuildTraineeStats counts shadow reviews of the team by trainees, current trainees without reviews are listed too
+func BuildTraineeStats(team *ds.Team, reviews []*ds.ShadowReview) []*TraineeStats {
+	byID := make(map[int]*TraineeStats)
+
+	get := func(id int) *TraineeStats {
+		stats, ok := byID[id]
+		if !ok {
+			stats = &TraineeStats{Team: team.Name, TraineeID: id}
+
+			if member := team.Member(id); member != nil {
+				stats.Name = member.Name
+				stats.Graduated = !member.Labels.Has(ds.TraineeLabel)
+			}
+
+			byID[id] = stats
+		}
+
+		return stats
+	}
+
+	for _, trainee := range ds.Trainees(team.Members) {
+		get(trainee.GitLabID)
+	}
+
+	for _, review := range reviews {
+		stats := get(review.TraineeID)
+		stats.Assigned++
+
+		if review.ApprovedAt != nil {
+			stats.Shadowed++
+		}
+	}
+
+	res := make([]*TraineeStats, 0, len(byID))
+	for _, stats := range byID {
+		res = append(res, stats)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].TraineeID < res[j].TraineeID })
+
+	return res
+}
+
+// FormatTraineeStats renders stats as a plain text list
+func FormatTraineeStats(stats []*TraineeStats) string {
+	if len(stats) == 0 {
+		return "no trainees\n"
+	}
+
+	var out strings.Builder
+
+	for _, s := range stats {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("user %d", s.TraineeID)
+		}
+
+		out.WriteString(fmt.Sprintf("%s: %s shadowed %d of %d reviews", s.Team, name, s.Shadowed, s.Assigned))
+
+		if s.Graduated {
+			out.WriteString(", graduated")
+		}
+
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+func TestBuildTraineeStats(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	team := &ds.Team{
+		Name: "backend",
+		Members: []*ds.User{
+			{BasicUser: &ds.BasicUser{GitLabID: 1, Name: "Ann"}, Labels: ds.UserLabels{ds.DeveloperLabel}},
+			{BasicUser: &ds.BasicUser{GitLabID: 2, Name: "Bob"}, Labels: ds.UserLabels{ds.TraineeLabel}},
+			{BasicUser: &ds.BasicUser{GitLabID: 3, Name: "Eve"}, Labels: ds.UserLabels{ds.TraineeLabel}},
+		},
+	}
+
+	reviews := []*ds.ShadowReview{
+		{TraineeID: 1, MergeRequestID: 1, ApprovedAt: &ts},
+		{TraineeID: 2, MergeRequestID: 1, ApprovedAt: &ts},
+		{TraineeID: 2, MergeRequestID: 2},
+		{TraineeID: 9, MergeRequestID: 3},
+	}
+
+	require.Equal(t, `backend: Ann shadowed 1 of 1 reviews, graduated
+backend: Bob shadowed 1 of 2 reviews
+backend: Eve shadowed 0 of 0 reviews
+backend: user 9 shadowed 0 of 1 reviews
+`, FormatTraineeStats(BuildTraineeStats(team, reviews)))
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -23,6 +24,8 @@ type NotificationService interface {
 	GetAuthoredReviewedMRs(team *ds.Team, users []*ds.User) (authorToMR, reviewerToMR map[int][]*ds.MergeRequest, err error)
 	UserNotification(user *ds.User, team *ds.Team, authorToMR, reviewerToMR map[int][]*ds.MergeRequest) (message string, err error)
 	TeamNotification(team *ds.Team, authorToMR, reviewerToMR map[int][]*ds.MergeRequest) (message string, err error)
+	// AbsentUsers returns GitLab IDs of users on vacation or day off
+	AbsentUsers(at time.Time) (map[int]bool, error)
 }
 
 type Notifications struct {
@@ -66,7 +69,13 @@ func (n *Notifications) Run() {
 		return
 	}
 
-	slackMessages, err := n.slackMessages(members, authorToMR, reviewerToMR)
+	absent, err := n.svc.AbsentUsers(time.Now())
+	if err != nil {
+		// reminding absent users is better than reminding nobody
+		l.Error().Err(err).Msg("failed to get absent users")
+	}
+
+	slackMessages, err := n.slackMessages(members, absent, authorToMR, reviewerToMR)
 	if err != nil {
 		l.Error().Err(err).Msg("failed to generate slack messages")
 		return
@@ -87,12 +96,18 @@ func (n *Notifications) Run() {
 
 func (n *Notifications) slackMessages(
 	devs []*ds.User,
+	absent map[int]bool,
 	authorToMR, reviewerToMR map[int][]*ds.MergeRequest,
 ) ([]SlackMessage, error) {
 
 	slackMessages := make([]SlackMessage, 0, len(devs)+1)
 
 	for _, dev := range devs {
+		// absent users are reminded after they return, the team channel still lists their MRs
+		if absent[dev.GitLabID] {
+			continue
+		}
+
 		message, err := n.svc.UserNotification(dev, n.team, authorToMR, reviewerToMR)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to generate user notification")
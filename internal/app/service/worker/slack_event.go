@@ -11,25 +11,38 @@ type SlackWorkerRepository interface {
 	UserBySlackID(slackID string) (*ds.User, *ds.Team, error)
 }
 
+type AbsenceService interface {
+	// HandleAbsenceCommand returns the reply to the /away command
+	HandleAbsenceCommand(user *ds.User, text string) (string, error)
+}
+
 type SlackWorker struct {
-	svc    NotificationService
-	r      SlackWorkerRepository
-	slack  SlackClient
-	events chan ds.UserEvent
-	close  chan struct{}
+	svc      NotificationService
+	absences AbsenceService
+	r        SlackWorkerRepository
+	slack    SlackClient
+	events   chan ds.UserEvent
+	close    chan struct{}
 }
 
 func (w *SlackWorker) Close() {
 	w.close <- struct{}{}
 }
 
-func NewSlackWorker(svc NotificationService, r SlackWorkerRepository, slack SlackClient, events chan ds.UserEvent) *SlackWorker {
+func NewSlackWorker(
+	svc NotificationService,
+	absences AbsenceService,
+	r SlackWorkerRepository,
+	slack SlackClient,
+	events chan ds.UserEvent,
+) *SlackWorker {
 	return &SlackWorker{
-		svc:    svc,
-		r:      r,
-		slack:  slack,
-		events: events,
-		close:  make(chan struct{}),
+		svc:      svc,
+		absences: absences,
+		r:        r,
+		slack:    slack,
+		events:   events,
+		close:    make(chan struct{}),
 	}
 }
 
@@ -48,7 +61,7 @@ func (w *SlackWorker) Run() {
 }
 
 func (w *SlackWorker) processEvent(event ds.UserEvent) error {
-	if event.Type != ds.UserEventTypeMRRequest {
+	if event.Type != ds.UserEventTypeMRRequest && event.Type != ds.UserEventTypeAbsence {
 		return nil
 	}
 
@@ -61,6 +74,10 @@ func (w *SlackWorker) processEvent(event ds.UserEvent) error {
 		return nil
 	}
 
+	if event.Type == ds.UserEventTypeAbsence {
+		return w.processAbsence(event, user)
+	}
+
 	authorToMR, reviewerToMR, err := w.svc.GetAuthoredReviewedMRs(team, []*ds.User{user})
 	if err != nil {
 		return errors.Wrap(err, "failed to get authored and reviewed mrs")
@@ -78,3 +95,17 @@ func (w *SlackWorker) processEvent(event ds.UserEvent) error {
 
 	return nil
 }
+
+func (w *SlackWorker) processAbsence(event ds.UserEvent, user *ds.User) error {
+	msg, err := w.absences.HandleAbsenceCommand(user, event.Text)
+	if err != nil {
+		return errors.Wrap(err, "failed to handle absence command")
+	}
+
+	err = w.slack.SendMessage(event.UserID, msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to send message")
+	}
+
+	return nil
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/jokerlee/gitlab-review-bot/internal/app/api"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/repository"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
 	"github.com/jokerlee/gitlab-review-bot/internal/pkg/client/gitlab"
@@ -30,7 +32,10 @@ type App struct {
 	openaiClient *openai.Client
 
 	policies map[ds.PolicyName]service.Policy
+	// strategy picks reviewers of live policies
+	strategy selection.Strategy
 	service  *service.Service
+	api      *api.Server
 
 	// graceful shutdown
 	ctx      context.Context
@@ -69,6 +74,11 @@ func New(configPath string) (*App, error) {
 		return nil, errors.Wrap(err, "failed to init service")
 	}
 
+	err = app.initAPI()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init api")
+	}
+
 	return app, nil
 }
 
@@ -77,16 +87,23 @@ func (a *App) Run() error {
 
 	a.logger.Info().Msg("app started")
 
-	//err = a.service.SubscribeOnSlack()
-	//if err != nil {
-	//	return errors.Wrap(err, "failed to subscribe on slack events")
-	//}
+	// slash commands need the socket mode app token
+	if a.cfg.SlackAppToken != "" {
+		err = a.service.SubscribeOnSlack()
+		if err != nil {
+			return errors.Wrap(err, "failed to subscribe on slack events")
+		}
+	}
 
 	err = a.service.SubscribeOnProjects(a.cfg.PullPeriod)
 	if err != nil {
 		return errors.Wrap(err, "failed to subscribe on projects")
 	}
 
+	if a.api != nil {
+		a.api.Run()
+	}
+
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
 
@@ -20,6 +20,15 @@ func (a *App) initClients() error {
 		return errors.Wrap(err, "failed to init slack client")
 	}
 
-	a.openaiClient, err = openai.New(a.ctx, a.cfg.OpenAIToken, a.cfg.OpenAIProxyUrl)
+	// AI review is disabled without a token, diff rules still work
+	if a.cfg.OpenAIToken == "" {
+		return nil
+	}
+
+	a.openaiClient, err = openai.New(a.ctx, a.cfg.OpenAIToken, a.cfg.OpenAIProxyUrl, a.cfg.OpenAIRunTimeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to init openai client")
+	}
+
 	return nil
 }
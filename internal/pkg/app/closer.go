@@ -11,6 +11,13 @@ import (
 func (a *App) closer() {
 	var err error
 
+	if a.api != nil {
+		err = a.api.Close()
+		if err != nil {
+			a.logger.Error().Err(err).Msg("failed to close api")
+		}
+	}
+
 	err = a.service.Close()
 	if err != nil {
 		a.logger.Error().Err(err).Msg("failed to close service")
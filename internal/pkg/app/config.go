@@ -7,6 +7,8 @@ import (
 	"github.com/gookit/config/v2/yamlv3"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
 )
 
 type Config struct {
@@ -18,6 +20,20 @@ type Config struct {
 	SlackBotToken    string `config:"slack_bot_token"`
 	SlackAppToken    string `config:"slack_app_token"`
 
+	AISuppressMinVotes  int     `config:"ai_suppress_min_votes"`
+	AISuppressDownRatio float64 `config:"ai_suppress_down_ratio"`
+
+	ReviewerSelection string          `config:"reviewer_selection"`
+	DryRunPolicies    []ds.PolicyName `config:"dry_run_policies"`
+
+	ReReviewThreshold int `config:"rereview_threshold"`
+
+	AbsentReviewers string `config:"absent_reviewers"`
+	AbsenceTimezone string `config:"absence_timezone"`
+
+	APIListen string `config:"api_listen"`
+	APIToken  string `config:"api_token"`
+
 	Mongo struct {
 		Host string `config:"host"`
 		Port int    `config:"port"`
@@ -26,7 +42,16 @@ type Config struct {
 		DB   string `config:"db"`
 	} `config:"mongo"`
 
-	PullPeriod time.Duration `config:"-"`
+	PullPeriod       time.Duration  `config:"-"`
+	AIReviewCacheTTL time.Duration  `config:"-"`
+	OpenAIRunTimeout time.Duration  `config:"-"`
+	AIFeedbackPoll   time.Duration  `config:"-"`
+	AIFeedbackWindow time.Duration  `config:"-"`
+	ReviewLoadWindow time.Duration  `config:"-"`
+	AbsenceCheck     time.Duration  `config:"-"`
+	AbsenceLocation  *time.Location `config:"-"`
+	AbsenceMinEvent  time.Duration  `config:"-"`
+	SLACheckPeriod   time.Duration  `config:"-"`
 }
 
 func (a *App) initConfig(configPath string) error {
@@ -53,5 +78,69 @@ func (a *App) initConfig(configPath string) error {
 		return errors.Wrap(err, "failed to parse pull_period")
 	}
 
+	a.cfg.AIReviewCacheTTL, err = time.ParseDuration(config.String("ai_review_cache_ttl", "720h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse ai_review_cache_ttl")
+	}
+
+	a.cfg.OpenAIRunTimeout, err = time.ParseDuration(config.String("openai_run_timeout", "5m"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse openai_run_timeout")
+	}
+
+	a.cfg.AIFeedbackPoll, err = time.ParseDuration(config.String("ai_feedback_poll_period", "1h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse ai_feedback_poll_period")
+	}
+
+	a.cfg.AIFeedbackWindow, err = time.ParseDuration(config.String("ai_feedback_window", "336h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse ai_feedback_window")
+	}
+
+	a.cfg.ReviewLoadWindow, err = time.ParseDuration(config.String("review_load_window", "168h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse review_load_window")
+	}
+
+	a.cfg.AbsenceCheck, err = time.ParseDuration(config.String("absence_check_period", "1h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse absence_check_period")
+	}
+
+	a.cfg.AbsenceMinEvent, err = time.ParseDuration(config.String("absence_min_event", "4h"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse absence_min_event")
+	}
+
+	a.cfg.SLACheckPeriod, err = time.ParseDuration(config.String("sla_check_period", "15m"))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse sla_check_period")
+	}
+
+	// the service falls back to the local time zone
+	if a.cfg.AbsenceTimezone != "" {
+		a.cfg.AbsenceLocation, err = time.LoadLocation(a.cfg.AbsenceTimezone)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse absence_timezone")
+		}
+	}
+
+	switch a.cfg.AbsentReviewers {
+	case "":
+		a.cfg.AbsentReviewers = "flag"
+	case "flag", "reassign":
+	default:
+		return errors.Errorf("unknown absent_reviewers %q", a.cfg.AbsentReviewers)
+	}
+
+	if a.cfg.ReviewerSelection == "" {
+		a.cfg.ReviewerSelection = "random"
+	}
+
+	if a.cfg.AISuppressDownRatio == 0 {
+		a.cfg.AISuppressDownRatio = 0.7
+	}
+
 	return nil
 }
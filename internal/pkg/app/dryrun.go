@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// DryRunReport compares decisions of dry-run policies made during the period with actual merge requests
+func DryRunReport(configPath string, period time.Duration) ([]*service.DryRunReport, error) {
+	a := &App{}
+
+	a.ctx, a.closeCtx = context.WithCancel(context.Background())
+	defer a.closeCtx()
+
+	err := a.initConfig(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init config")
+	}
+
+	a.initLogger()
+
+	err = a.initRepository()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init repository")
+	}
+
+	defer func() {
+		_ = a.mongoClient.Disconnect(context.Background())
+	}()
+
+	decisions, err := a.repository.DecisionsCreatedAfter(time.Now().Add(-period))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch decisions")
+	}
+
+	mrs := make(map[int]*ds.MergeRequest)
+
+	for _, decision := range decisions {
+		if _, ok := mrs[decision.MergeRequestID]; ok {
+			continue
+		}
+
+		mr, err := a.repository.MergeRequestByID(decision.MergeRequestID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch merge request")
+		}
+
+		if mr != nil {
+			mrs[decision.MergeRequestID] = mr
+		}
+	}
+
+	return service.BuildDryRunReport(decisions, mrs), nil
+}
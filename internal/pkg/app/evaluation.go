@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/evaluation"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/client/gitlab"
+	"github.com/jokerlee/gitlab-review-bot/internal/pkg/client/openai"
+)
+
+// Evaluate replays the corpus through the review pipeline with the configured OpenAI client
+// and redaction settings of stored projects
+func Evaluate(configPath string, cases []*evaluation.Case, baseline *evaluation.Report) (*evaluation.Report, error) {
+	a := &App{}
+
+	a.ctx, a.closeCtx = context.WithCancel(context.Background())
+	defer a.closeCtx()
+
+	err := a.initConfig(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init config")
+	}
+
+	a.initLogger()
+
+	err = a.initRepository()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init repository")
+	}
+
+	defer func() {
+		_ = a.mongoClient.Disconnect(context.Background())
+	}()
+
+	projects, err := a.repository.Projects()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch projects")
+	}
+
+	a.openaiClient, err = openai.New(a.ctx, a.cfg.OpenAIToken, a.cfg.OpenAIProxyUrl, a.cfg.OpenAIRunTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init openai client")
+	}
+
+	return evaluation.Run(a.openaiClient, cases, baseline, projects)
+}
+
+// ExportCorpus saves the latest stored merge requests and commits of every project with their diffs as cases
+func ExportCorpus(configPath string, dir string, limit int) error {
+	a := &App{}
+
+	a.ctx, a.closeCtx = context.WithCancel(context.Background())
+	defer a.closeCtx()
+
+	err := a.initConfig(configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	a.initLogger()
+
+	err = a.initRepository()
+	if err != nil {
+		return errors.Wrap(err, "failed to init repository")
+	}
+
+	defer func() {
+		_ = a.mongoClient.Disconnect(context.Background())
+	}()
+
+	a.gitlabClient, err = gitlab.New(a.ctx, a.cfg.GitlabServerUrl, a.cfg.GitlabToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to init gitlab client")
+	}
+
+	projects, err := a.repository.Projects()
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch projects")
+	}
+
+	for _, project := range projects {
+		err = a.exportProject(project, dir, limit)
+		if err != nil {
+			return errors.Wrapf(err, "failed to export project %s", project.Name)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) exportProject(project *ds.Project, dir string, limit int) error {
+	mrs, err := a.repository.MergeRequestsByProject(project.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch merge requests")
+	}
+
+	// fixtures are stored on disk, so they are redacted even if the project turned redaction off
+	redactor, err := service.NewRedactor(project.Redaction)
+	if err != nil {
+		return errors.Wrap(err, "invalid redaction settings")
+	}
+
+	// the latest first
+	sort.Slice(mrs, func(i, j int) bool {
+		return newerFirst(mrs[i].CreatedAt, mrs[j].CreatedAt)
+	})
+
+	for i, mr := range mrs {
+		if i >= limit {
+			break
+		}
+
+		diffs, err := a.gitlabClient.GetMergeRequestDiff(mr.ProjectID, mr.IID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get diff of merge request %d", mr.IID)
+		}
+
+		diffs, _ = service.RedactDiffs(redactor, diffs)
+		description, _ := redactor.Redact(mr.Description)
+		name := fmt.Sprintf("mr-%d-%d", mr.ProjectID, mr.IID)
+
+		err = evaluation.SaveCase(dir, evaluation.NewCase(name, mr.ProjectID, mr.Title, description, diffs))
+		if err != nil {
+			return err
+		}
+
+		log.Info().Str("case", name).Msg("case exported")
+	}
+
+	commits, err := a.repository.CommitByProject(project.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch commits")
+	}
+
+	sort.Slice(commits, func(i, j int) bool {
+		return newerFirst(commits[i].CreatedAt, commits[j].CreatedAt)
+	})
+
+	for i, commit := range commits {
+		if i >= limit {
+			break
+		}
+
+		diffs, err := a.gitlabClient.GetCommitDiff(commit.ProjectID, commit.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get diff of commit %s", commit.ShortID)
+		}
+
+		diffs, _ = service.RedactDiffs(redactor, diffs)
+		message, _ := redactor.Redact(commit.Message)
+		name := fmt.Sprintf("commit-%d-%s", commit.ProjectID, commit.ShortID)
+
+		err = evaluation.SaveCase(dir, evaluation.NewCase(name, commit.ProjectID, commit.Title, message, diffs))
+		if err != nil {
+			return err
+		}
+
+		log.Info().Str("case", name).Msg("case exported")
+	}
+
+	return nil
+}
+
+// newerFirst orders times descending, unknown times are the last
+func newerFirst(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+
+	return a.After(*b)
+}
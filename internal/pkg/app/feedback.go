@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// FeedbackReport builds per-team usefulness of AI comments created during the period
+func FeedbackReport(configPath string, period time.Duration) ([]*service.TeamFeedbackReport, error) {
+	a := &App{}
+
+	a.ctx, a.closeCtx = context.WithCancel(context.Background())
+	defer a.closeCtx()
+
+	err := a.initConfig(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init config")
+	}
+
+	a.initLogger()
+
+	err = a.initRepository()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init repository")
+	}
+
+	defer func() {
+		_ = a.mongoClient.Disconnect(context.Background())
+	}()
+
+	teams, err := a.repository.Teams()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch teams")
+	}
+
+	comments, err := a.repository.AICommentsCreatedAfter(time.Now().Add(-period))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch ai comments")
+	}
+
+	return service.BuildFeedbackReport(teams, comments), nil
+}
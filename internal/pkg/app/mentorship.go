@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// TraineeStats counts shadow reviews of trainees of all teams
+func TraineeStats(configPath string) ([]*service.TraineeStats, error) {
+	a := &App{}
+
+	a.ctx, a.closeCtx = context.WithCancel(context.Background())
+	defer a.closeCtx()
+
+	err := a.initConfig(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init config")
+	}
+
+	a.initLogger()
+
+	err = a.initRepository()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init repository")
+	}
+
+	defer func() {
+		_ = a.mongoClient.Disconnect(context.Background())
+	}()
+
+	teams, err := a.repository.Teams()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch teams")
+	}
+
+	stats := make([]*service.TraineeStats, 0)
+
+	for _, team := range teams {
+		reviews, err := a.repository.ShadowReviews(team.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch shadow reviews")
+		}
+
+		if len(reviews) == 0 && team.Mentorship == nil {
+			continue
+		}
+
+		stats = append(stats, service.BuildTraineeStats(team, reviews)...)
+	}
+
+	return stats, nil
+}
@@ -1,30 +1,157 @@
 package app
 
 import (
+	"math/rand"
+	"net/http"
+	"time"
+
 	"github.com/pkg/errors"
 
+	"github.com/jokerlee/gitlab-review-bot/internal/app/api"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/actions"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/codeowners"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/declarative"
+	dr "github.com/jokerlee/gitlab-review-bot/internal/app/policy/developers-riot"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/dryrun"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/mentorship"
 	rd "github.com/jokerlee/gitlab-review-bot/internal/app/policy/reinventing-democracy"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/policy/selection"
 	tlar "github.com/jokerlee/gitlab-review-bot/internal/app/policy/team-lead-always-right"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
 )
 
+// selectionState is where the reviewer selection keeps rotation cursors and reasons of picks
+type selectionState interface {
+	selection.CursorRepository
+	selection.PickReasonRepository
+}
+
+// newStrategy returns the reviewer selection strategy keeping its state in the state repository
+func (a *App) newStrategy(state selectionState, seed int64) (*selection.Available, error) {
+	weights := selection.DefaultWeights
+	weights.Window = a.cfg.ReviewLoadWindow
+
+	strategies := map[string]selection.ConstrainedStrategy{
+		selection.NameRandom:     selection.NewRandom(rand.New(rand.NewSource(seed))),
+		selection.NameLoadAware:  selection.NewLoadAware(a.repository, rand.New(rand.NewSource(seed+1)), weights),
+		selection.NameRoundRobin: selection.NewRoundRobin(state),
+	}
+
+	def, ok := strategies[a.cfg.ReviewerSelection]
+	if !ok {
+		return nil, errors.Errorf("unknown reviewer_selection %q", a.cfg.ReviewerSelection)
+	}
+
+	// absent teammates are never picked, teams may choose own strategy and how code owners are picked,
+	// a teammate with skills of changed paths is preferred
+	return selection.NewAvailable(
+		selection.NewConstraints(selection.NewPerTeam(def, strategies), codeowners.New(a.gitlabClient), state),
+		a.repository), nil
+}
+
 func (a *App) initPolicies() error {
 	a.policies = make(map[ds.PolicyName]service.Policy)
 
-	a.policies[rd.PolicyName] = rd.New(a.repository, a.gitlabClient)
-	a.policies[tlar.PolicyName] = tlar.New(a.repository, a.gitlabClient)
+	seed := time.Now().UnixNano()
+
+	strategy, err := a.newStrategy(a.repository, seed)
+	if err != nil {
+		return err
+	}
+
+	// picks of dry-run policies don't move rotation cursors of live ones and don't save reasons
+	dryStrategy, err := a.newStrategy(dryrun.NewSelection(a.repository), seed+2)
+	if err != nil {
+		return err
+	}
+
+	a.strategy = strategy
+
+	runner := actions.New(a.gitlabClient, a.slackClient, http.DefaultClient)
+
+	// trainees of teams shadow reviews of every policy
+	shadowed := func(p service.Policy) service.Policy {
+		return mentorship.New(p, a.repository, a.gitlabClient, strategy, serviceTeams{a})
+	}
+
+	a.policies[rd.PolicyName] = shadowed(rd.New(a.repository, a.gitlabClient, strategy, runner))
+	a.policies[tlar.PolicyName] = shadowed(tlar.New(a.repository, a.gitlabClient, strategy, runner))
+	a.policies[dr.PolicyName] = shadowed(dr.New(a.repository, a.gitlabClient, strategy, runner))
+	a.policies[declarative.PolicyName] = shadowed(declarative.New(a.repository, a.gitlabClient, strategy, runner))
+
+	// dry-run policies keep own metadata and store decisions instead of calling GitLab
+	rdDry := dryrun.New(a.repository, rd.PolicyName)
+	tlarDry := dryrun.New(a.repository, tlar.PolicyName)
+	drDry := dryrun.New(a.repository, dr.PolicyName)
+	declarativeDry := dryrun.New(a.repository, declarative.PolicyName)
+
+	a.policies[rd.PolicyName.DryRun()] = rd.New(rdDry, rdDry, dryStrategy, rdDry)
+	a.policies[tlar.PolicyName.DryRun()] = tlar.New(tlarDry, tlarDry, dryStrategy, tlarDry)
+	a.policies[dr.PolicyName.DryRun()] = dr.New(drDry, drDry, dryStrategy, drDry)
+	a.policies[declarative.PolicyName.DryRun()] = declarative.New(declarativeDry, declarativeDry, dryStrategy, declarativeDry)
 
 	return nil
 }
 
+// serviceTeams reloads teams of the service, which is created after policies
+type serviceTeams struct {
+	a *App
+}
+
+func (t serviceTeams) ReloadTeams() error {
+	return t.a.service.ReloadTeams()
+}
+
 func (a *App) initService() error {
 	var err error
 
-	a.service, err = service.New(a.repository, a.gitlabClient, a.policies, a.slackClient, a.openaiClient)
+	// avoid a typed nil interface, the service checks the client for nil
+	var openaiClient service.OpenAIClient
+	if a.openaiClient != nil {
+		openaiClient = a.openaiClient
+	}
+
+	a.service, err = service.New(service.Config{
+		AIReviewCacheTTL: a.cfg.AIReviewCacheTTL,
+		AIFeedback: service.FeedbackConfig{
+			PollPeriod:        a.cfg.AIFeedbackPoll,
+			Window:            a.cfg.AIFeedbackWindow,
+			SuppressMinVotes:  a.cfg.AISuppressMinVotes,
+			SuppressDownRatio: a.cfg.AISuppressDownRatio,
+		},
+		Absence: service.AbsenceConfig{
+			CheckPeriod: a.cfg.AbsenceCheck,
+			Reassign:    a.cfg.AbsentReviewers == "reassign",
+			Location:    a.cfg.AbsenceLocation,
+			MinEvent:    a.cfg.AbsenceMinEvent,
+		},
+		SLA: service.SLAConfig{
+			CheckPeriod: a.cfg.SLACheckPeriod,
+		},
+		ReReview: service.ReReviewConfig{
+			Threshold: a.cfg.ReReviewThreshold,
+		},
+		DryRunPolicies: a.cfg.DryRunPolicies,
+	}, a.repository, a.gitlabClient, a.policies, a.strategy, a.slackClient, openaiClient)
 	if err != nil {
 		return errors.Wrap(err, "failed to init service")
 	}
 
 	return nil
 }
+
+func (a *App) initAPI() error {
+	if a.cfg.APIListen == "" {
+		return nil
+	}
+
+	var err error
+
+	a.api, err = api.New(a.cfg.APIListen, a.cfg.APIToken, a.service)
+	if err != nil {
+		return errors.Wrap(err, "failed to init api")
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package gitlab
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// MergeRequestChangedPaths returns new and old paths of all changed files of the merge request
+func (c *Client) MergeRequestChangedPaths(projectID int, iid int) ([]string, error) {
+	paths := make([]string, 0)
+	seen := make(map[string]bool)
+
+	opts := &gitlab.ListMergeRequestDiffsOptions{Page: 1, PerPage: 100}
+
+	for {
+		c.rl.Take()
+		diffs, resp, err := c.gitlab.MergeRequests.ListMergeRequestDiffs(projectID, iid, opts, gitlab.WithContext(c.ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "error get diffs of the merge request")
+		}
+
+		for _, diff := range diffs {
+			for _, path := range []string{diff.NewPath, diff.OldPath} {
+				if path != "" && !seen[path] {
+					seen[path] = true
+					paths = append(paths, path)
+				}
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return paths, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// CodeOwnerUsers resolves the CODEOWNERS owner (@username, @group/subgroup or email) into GitLab IDs of users,
+// unknown owners resolve into nothing
+func (c *Client) CodeOwnerUsers(owner string) ([]int, error) {
+	if !strings.HasPrefix(owner, "@") {
+		return c.usersByEmail(owner)
+	}
+
+	name := strings.TrimPrefix(owner, "@")
+
+	c.rl.Take()
+	users, _, err := c.gitlab.Users.ListUsers(&gitlab.ListUsersOptions{Username: &name}, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error get user %s", name)
+	}
+
+	if len(users) > 0 {
+		return []int{users[0].ID}, nil
+	}
+
+	return c.groupMembers(name)
+}
+
+func (c *Client) usersByEmail(email string) ([]int, error) {
+	c.rl.Take()
+	users, _, err := c.gitlab.Users.ListUsers(&gitlab.ListUsersOptions{Search: &email}, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error search user %s", email)
+	}
+
+	ids := make([]int, 0, 1)
+
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) || strings.EqualFold(user.PublicEmail, email) {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (c *Client) groupMembers(group string) ([]int, error) {
+	ids := make([]int, 0)
+
+	opts := &gitlab.ListGroupMembersOptions{ListOptions: gitlab.ListOptions{Page: 1, PerPage: 100}}
+
+	for {
+		c.rl.Take()
+		members, resp, err := c.gitlab.Groups.ListAllGroupMembers(group, opts, gitlab.WithContext(c.ctx))
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return ids, nil
+			}
+
+			return nil, errors.Wrapf(err, "error get members of group %s", group)
+		}
+
+		for _, member := range members {
+			ids = append(ids, member.ID)
+		}
+
+		if resp.NextPage == 0 {
+			return ids, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
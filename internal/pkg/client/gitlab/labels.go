@@ -0,0 +1,24 @@
+package gitlab
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// UpdateMergeRequestLabels adds and removes labels of the merge request, other labels stay untouched
+func (c *Client) UpdateMergeRequestLabels(projectID int, mrID int, add []string, remove []string) error {
+	addLabels := gitlab.Labels(add)
+	removeLabels := gitlab.Labels(remove)
+
+	c.rl.Take()
+	// docs: https://docs.gitlab.com/ee/api/merge_requests.html#update-mr
+	_, _, err := c.gitlab.MergeRequests.UpdateMergeRequest(projectID, mrID, &gitlab.UpdateMergeRequestOptions{
+		AddLabels:    &addLabels,
+		RemoveLabels: &removeLabels,
+	}, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return errors.Wrap(err, "error updating labels of the merge request")
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package gitlab
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+// MergeWhenPipelineSucceeds sets auto-merge of the known revision, newer commits are not merged
+func (c *Client) MergeWhenPipelineSucceeds(mr *ds.MergeRequest) error {
+	c.rl.Take()
+	// docs: https://docs.gitlab.com/ee/api/merge_requests.html#merge-a-merge-request
+	_, _, err := c.gitlab.MergeRequests.AcceptMergeRequest(mr.ProjectID, mr.IID, &gitlab.AcceptMergeRequestOptions{
+		MergeWhenPipelineSucceeds: gitlab.Bool(true),
+		SHA:                       gitlab.String(mr.SHA),
+	}, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return errors.Wrap(err, "error setting merge when pipeline succeeds")
+	}
+
+	return nil
+}
@@ -89,6 +89,7 @@ func mergeRequestConvert(req *gitlab.MergeRequest) *ds.MergeRequest {
 		Assignees:    assignees,
 		Reviewers:    reviewers,
 		Draft:        req.Draft,
+		Labels:       req.Labels,
 		SHA:          req.SHA,
 		URL:          req.WebURL,
 		UpdatedAt:    req.UpdatedAt,
@@ -1,6 +1,7 @@
 package gitlab
 
 import (
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
 	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
 	"github.com/pkg/errors"
 	"github.com/xanzy/go-gitlab"
@@ -37,10 +38,10 @@ func (c *Client) GetMergeRequestDiff(projectID int, mrID int) (result []*service
 	return
 }
 
-func (c *Client) AddCommentToMergeRequests(projectID int, mrID int, comment string) error {
+func (c *Client) AddCommentToMergeRequests(projectID int, mrID int, comment string) (*service.Discussion, error) {
 	c.rl.Take()
 	var now = time.Now()
-	_, _, err := c.gitlab.Discussions.CreateMergeRequestDiscussion(
+	discussion, _, err := c.gitlab.Discussions.CreateMergeRequestDiscussion(
 		projectID,
 		mrID,
 		&gitlab.CreateMergeRequestDiscussionOptions{
@@ -49,8 +50,64 @@ func (c *Client) AddCommentToMergeRequests(projectID int, mrID int, comment stri
 		})
 
 	if err != nil {
-		return errors.Wrap(err, "error add comment to merge request")
+		return nil, errors.Wrap(err, "error add comment to merge request")
 	}
 
-	return nil
+	return discussionConvert(discussion), nil
+}
+
+// AddPositionedCommentToMergeRequest starts a discussion on the line of the merge request diff
+func (c *Client) AddPositionedCommentToMergeRequest(projectID int, mrID int, position service.CommentPosition, comment string) (*service.Discussion, error) {
+	c.rl.Take()
+	mr, _, err := c.gitlab.MergeRequests.GetMergeRequest(projectID, mrID, nil, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "error get diff refs of the merge request")
+	}
+
+	oldPath := position.OldPath
+	if oldPath == "" {
+		oldPath = position.Path
+	}
+
+	c.rl.Take()
+	var now = time.Now()
+	discussion, _, err := c.gitlab.Discussions.CreateMergeRequestDiscussion(
+		projectID,
+		mrID,
+		&gitlab.CreateMergeRequestDiscussionOptions{
+			Body:      &comment,
+			CreatedAt: &now,
+			Position: &gitlab.NotePosition{
+				BaseSHA:      mr.DiffRefs.BaseSha,
+				StartSHA:     mr.DiffRefs.StartSha,
+				HeadSHA:      mr.DiffRefs.HeadSha,
+				PositionType: "text",
+				NewPath:      position.Path,
+				NewLine:      position.Line,
+				OldPath:      oldPath,
+			},
+		},
+		gitlab.WithContext(c.ctx))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error add positioned comment to merge request")
+	}
+
+	return discussionConvert(discussion), nil
+}
+
+func discussionConvert(discussion *gitlab.Discussion) *service.Discussion {
+	result := &service.Discussion{ID: discussion.ID}
+	if len(discussion.Notes) > 0 {
+		result.NoteID = discussion.Notes[0].ID
+	}
+
+	return result
+}
+
+// CommentMergeRequest adds a note to the merge request
+func (c *Client) CommentMergeRequest(mr *ds.MergeRequest, comment string) error {
+	_, err := c.AddCommentToMergeRequests(mr.ProjectID, mr.IID, comment)
+
+	return err
 }
@@ -0,0 +1,50 @@
+package gitlab
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+const (
+	emojiThumbsUp   = "thumbsup"
+	emojiThumbsDown = "thumbsdown"
+)
+
+// MergeRequestNoteFeedback counts 👍/👎 on the note and replies of other users in its discussion
+func (c *Client) MergeRequestNoteFeedback(projectID int, mrID int, discussionID string, noteID int) (*ds.AIFeedback, error) {
+	c.rl.Take()
+	// docs: https://docs.gitlab.com/ee/api/award_emoji.html#list-a-comments-award-emoji
+	emojis, _, err := c.gitlab.AwardEmoji.ListMergeRequestAwardEmojiOnNote(projectID, mrID, noteID,
+		&gitlab.ListAwardEmojiOptions{PerPage: perPage},
+		gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "error list award emoji of the note")
+	}
+
+	feedback := &ds.AIFeedback{}
+
+	for _, emoji := range emojis {
+		switch emoji.Name {
+		case emojiThumbsUp:
+			feedback.Up++
+		case emojiThumbsDown:
+			feedback.Down++
+		}
+	}
+
+	c.rl.Take()
+	discussion, _, err := c.gitlab.Discussions.GetMergeRequestDiscussion(projectID, mrID, discussionID, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "error get discussion of the note")
+	}
+
+	for _, note := range discussion.Notes {
+		if note.ID != noteID && !note.System {
+			feedback.Replies++
+		}
+	}
+
+	return feedback, nil
+}
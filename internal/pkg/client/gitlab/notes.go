@@ -0,0 +1,118 @@
+package gitlab
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+)
+
+const (
+	noteReviewRequested      = "requested review from "
+	noteReviewRequestRemoved = "removed review request for "
+)
+
+var mentionRe = regexp.MustCompile(`@([A-Za-z0-9_.-]*[A-Za-z0-9_-])`)
+
+// reviewRequestedUsernames parses users requested to review from the system note
+// like "requested review from @alice, @bob and @carol and removed review request for @dave"
+func reviewRequestedUsernames(body string) []string {
+	_, requested, ok := strings.Cut(body, noteReviewRequested)
+	if !ok {
+		return nil
+	}
+
+	requested, _, _ = strings.Cut(requested, noteReviewRequestRemoved)
+
+	usernames := make([]string, 0, 1)
+	for _, match := range mentionRe.FindAllStringSubmatch(requested, -1) {
+		usernames = append(usernames, match[1])
+	}
+
+	return usernames
+}
+
+// MergeRequestNotes returns comments and system notes of the merge request from the oldest one
+func (c *Client) MergeRequestNotes(projectID int, iid int) ([]*ds.Note, error) {
+	notes := make([]*ds.Note, 0)
+	// users are resolved once per the timeline, review is usually requested from the same people
+	userIDs := make(map[string]int)
+
+	opts := &gitlab.ListMergeRequestNotesOptions{
+		ListOptions: gitlab.ListOptions{Page: 1, PerPage: perPage},
+		OrderBy:     gitlab.String("created_at"),
+		Sort:        gitlab.String("asc"),
+	}
+
+	for i := 1; i <= maxPages; i++ {
+		c.rl.Take()
+		// docs: https://docs.gitlab.com/ee/api/notes.html#list-all-merge-request-notes
+		page, resp, err := c.gitlab.Notes.ListMergeRequestNotes(projectID, iid, opts, gitlab.WithContext(c.ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "error list notes of the merge request")
+		}
+
+		for _, note := range page {
+			if note.CreatedAt == nil {
+				continue
+			}
+
+			n := &ds.Note{
+				AuthorID:  note.Author.ID,
+				Body:      note.Body,
+				System:    note.System,
+				CreatedAt: *note.CreatedAt,
+			}
+
+			if note.System {
+				n.ReviewRequested, err = c.userIDs(reviewRequestedUsernames(note.Body), userIDs)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			notes = append(notes, n)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return notes, nil
+}
+
+// userIDs resolves usernames into GitLab IDs using and filling the cache, unknown users are skipped
+func (c *Client) userIDs(usernames []string, cache map[string]int) ([]int, error) {
+	ids := make([]int, 0, len(usernames))
+
+	for _, username := range usernames {
+		id, ok := cache[username]
+		if !ok {
+			username := username
+
+			c.rl.Take()
+			users, _, err := c.gitlab.Users.ListUsers(&gitlab.ListUsersOptions{Username: &username}, gitlab.WithContext(c.ctx))
+			if err != nil {
+				return nil, errors.Wrapf(err, "error get user %s", username)
+			}
+
+			if len(users) > 0 {
+				id = users[0].ID
+			}
+
+			cache[username] = id
+		}
+
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
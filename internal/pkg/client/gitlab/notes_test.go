@@ -0,0 +1,25 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewRequestedUsernames(t *testing.T) {
+	tc := []struct {
+		body string
+		out  []string
+	}{
+		{body: "requested review from @alice", out: []string{"alice"}},
+		{body: "requested review from @alice, @bob.smith and @carol-1", out: []string{"alice", "bob.smith", "carol-1"}},
+		{body: "requested review from @alice and removed review request for @bob", out: []string{"alice"}},
+		{body: "removed review request for @bob and requested review from @alice", out: []string{"alice"}},
+		{body: "removed review request for @bob", out: nil},
+		{body: "approved this merge request", out: nil},
+	}
+
+	for _, tt := range tc {
+		require.Equal(t, tt.out, reviewRequestedUsernames(tt.body), tt.body)
+	}
+}
@@ -0,0 +1,28 @@
+package gitlab
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// GetRawFile returns content of the file at the ref (branch, tag or SHA), nil if the file does not exist
+func (c *Client) GetRawFile(projectID int, path string, ref string) ([]byte, error) {
+	c.rl.Take()
+	// docs: https://docs.gitlab.com/ee/api/repository_files.html#get-raw-file-from-repository
+	content, resp, err := c.gitlab.RepositoryFiles.GetRawFile(
+		projectID,
+		path,
+		&gitlab.GetRawFileOptions{Ref: &ref},
+		gitlab.WithContext(c.ctx))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "error getting raw file %s", path)
+	}
+
+	return content, nil
+}
@@ -39,5 +39,14 @@ func (c *Client) SetReviewers(mr *ds.MergeRequest, reviewers []int) error {
 		}
 	}
 
+	// policies of other involved teams add their reviewers on top of these
+	mr.Reviewers = make([]*ds.BasicUser, 0, len(actual.Reviewers))
+	for _, reviewer := range actual.Reviewers {
+		mr.Reviewers = append(mr.Reviewers, &ds.BasicUser{
+			Name:     reviewer.Name,
+			GitLabID: reviewer.ID,
+		})
+	}
+
 	return nil
 }
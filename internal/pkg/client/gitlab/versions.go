@@ -0,0 +1,69 @@
+package gitlab
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/jokerlee/gitlab-review-bot/internal/app/ds"
+	"github.com/jokerlee/gitlab-review-bot/internal/app/service"
+)
+
+// MergeRequestVersions returns diff versions of the merge request from the newest one
+func (c *Client) MergeRequestVersions(projectID int, iid int) ([]*ds.MergeRequestVersion, error) {
+	versions := make([]*ds.MergeRequestVersion, 0)
+
+	opts := &gitlab.GetMergeRequestDiffVersionsOptions{Page: 1, PerPage: perPage}
+
+	for i := 1; i <= maxPages; i++ {
+		c.rl.Take()
+		// docs: https://docs.gitlab.com/ee/api/merge_requests.html#get-merge-request-diff-versions
+		page, resp, err := c.gitlab.MergeRequests.GetMergeRequestDiffVersions(projectID, iid, opts, gitlab.WithContext(c.ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "error get versions of the merge request")
+		}
+
+		for _, version := range page {
+			if version.CreatedAt == nil {
+				continue
+			}
+
+			versions = append(versions, &ds.MergeRequestVersion{
+				ID:        version.ID,
+				HeadSHA:   version.HeadCommitSHA,
+				CreatedAt: *version.CreatedAt,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return versions, nil
+}
+
+// MergeRequestVersionDiff returns changes of the merge request at the version
+func (c *Client) MergeRequestVersionDiff(projectID int, iid int, versionID int) ([]*service.Diff, error) {
+	c.rl.Take()
+	// docs: https://docs.gitlab.com/ee/api/merge_requests.html#get-a-single-merge-request-diff-version
+	version, _, err := c.gitlab.MergeRequests.GetSingleMergeRequestDiffVersion(projectID, iid, versionID, gitlab.WithContext(c.ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "error get version of the merge request")
+	}
+
+	result := make([]*service.Diff, 0, len(version.Diffs))
+	for _, diff := range version.Diffs {
+		result = append(result, &service.Diff{
+			Content:     diff.Diff,
+			NewPath:     diff.NewPath,
+			OldPath:     diff.OldPath,
+			NewFile:     diff.NewFile,
+			DeletedFile: diff.DeletedFile,
+			RenamedFile: diff.RenamedFile,
+		})
+	}
+
+	return result, nil
+}
@@ -1,13 +1,19 @@
 package openai
 
 import (
-	"fmt"
 	"github.com/pkg/errors"
 	"github.com/sashabaranov/go-openai"
-	"time"
 )
 
 const AssistantName = "Code Mentor II"
+
+// PromptVersion must be changed on every change of Instructions or the prompt format,
+// it invalidates cached reviews
+const PromptVersion = "3"
+
+// ReviewModel is used for code review runs
+const ReviewModel = openai.GPT4TurboPreview
+
 const Instructions = "The GPT is designed to act as a code reviewer. " +
 	"Its primary function is to assist users by identifying issues in their code. " +
 	"It focuses on pinpointing naming inconsistencies, coding style breaches, concurrency pitfalls, " +
@@ -15,10 +21,29 @@ const Instructions = "The GPT is designed to act as a code reviewer. " +
 	"and other code smells that could hinder maintainability and performance. " +
 	"Do not repeat what the code diff is doing, just give modification advice"
 
+// SuggestionFormat asks for replacement code of exact line ranges, the bot turns it into GitLab suggestions
+const SuggestionFormat = "When you can propose replacement code for specific lines, " +
+	"add a block for each proposal in this exact format:\n" +
+	"```suggestion:<file path>:<first line>-<last line>\n" +
+	"<one sentence why>\n" +
+	"-<current line, copied exactly>\n" +
+	"+<replacement line>\n" +
+	"```\n" +
+	"Line numbers are in the new version of the file, every current line of the range must be listed with '-'. " +
+	"Only lines added by the diff (starting with '+') can be replaced."
+
 func (c *Client) GenerateAICodeReviewComment(diff string) (string, error) {
 	return c.generateAICodeReviewCommentByAssistant(diff)
 }
 
+func (c *Client) Model() string {
+	return ReviewModel
+}
+
+func (c *Client) PromptVersion() string {
+	return PromptVersion
+}
+
 func (c *Client) generateAICodeReviewCommentByChat(diff string) (string, error) {
 	diff = Instructions + "\n Please do code review for below code change: \n" + truncate(diff, 16000)
 
@@ -54,8 +79,10 @@ func (c *Client) generateAICodeReviewCommentByAssistant(diff string) (string, er
 		return "", errors.Wrap(err, "failed to CreateThread from openai")
 	}
 
-	model := openai.GPT4TurboPreview
-	instruction := "please review this code diff, give modification advice"
+	defer c.deleteThread(thread.ID)
+
+	model := ReviewModel
+	instruction := "please review this code diff, give modification advice. " + SuggestionFormat
 	run, err := c.openai.CreateRun(c.ctx, thread.ID, openai.RunRequest{
 		AssistantID:  assistant.ID,
 		Model:        &model,
@@ -78,16 +105,17 @@ func (c *Client) retrieveAssistant() (assistant openai.Assistant, err error) {
 	}
 
 	for _, item := range resp.Assistants {
-		if *item.Name == AssistantName {
-			assistant = item
+		if item.Name != nil && *item.Name == AssistantName {
+			return item, nil
 		}
 	}
 
-	if assistant.ID == "" {
-		assistant, err = c.createAssistant()
+	assistant, err = c.createAssistant()
+	if err != nil {
+		err = errors.Wrap(err, "failed to CreateAssistant from openai")
 	}
 
-	return assistant, nil
+	return assistant, err
 }
 
 // createAssistant for first Run
@@ -104,35 +132,6 @@ func (c *Client) createAssistant() (assistant openai.Assistant, err error) {
 	})
 }
 
-// wait until run completed or failed
-func (c *Client) waitRunToComplete(threadId string, runId string) (string, error) {
-	for {
-		run, err := c.openai.RetrieveRun(c.ctx, threadId, runId)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to RetrieveRun from openai")
-		}
-		switch run.Status {
-		case openai.RunStatusQueued:
-		case openai.RunStatusInProgress:
-		case openai.RunStatusCancelling:
-			time.Sleep(1 * time.Second)
-			// keep waiting
-			continue
-		case openai.RunStatusFailed:
-		case openai.RunStatusExpired:
-		case openai.RunStatusRequiresAction:
-			return "", errors.Wrap(err, fmt.Sprintf("openai Run failed with status: %s", run.Status))
-		case openai.RunStatusCompleted:
-			messages, err := c.openai.ListMessage(c.ctx, threadId, nil, nil, nil, nil)
-			if err != nil {
-				return "", err
-			} else {
-				return messages.Messages[0].Content[0].Text.Value, nil
-			}
-		}
-	}
-}
-
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -2,24 +2,37 @@ package openai
 
 import (
 	"context"
-	"github.com/sashabaranov/go-openai"
-	"go.uber.org/ratelimit"
 	"net/http"
 	"net/url"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"go.uber.org/ratelimit"
 )
 
 type Client struct {
-	ctx    context.Context
-	openai *openai.Client
-	rl     ratelimit.Limiter
-	closer chan struct{}
+	ctx     context.Context
+	openai  *openai.Client
+	rl      ratelimit.Limiter
+	polling RunPolling
+	closer  chan struct{}
+}
+
+// RunPolling controls waiting for Assistants runs
+type RunPolling struct {
+	// Timeout is the total deadline of a run, the run is cancelled after it
+	Timeout time.Duration
+	// MinInterval is the first interval between polls, it is doubled after every poll
+	MinInterval time.Duration
+	// MaxInterval is the upper bound of the interval between polls
+	MaxInterval time.Duration
 }
 
 func (c *Client) Close() {
 	c.closer <- struct{}{}
 }
 
-func New(rootCtx context.Context, openaiToken, openaiProxyUrl string) (*Client, error) {
+func New(rootCtx context.Context, openaiToken, openaiProxyUrl string, runTimeout time.Duration) (*Client, error) {
 	config := openai.DefaultConfig(openaiToken)
 	if len(openaiProxyUrl) != 0 {
 		proxyUrl, _ := url.Parse(openaiProxyUrl)
@@ -29,12 +42,20 @@ func New(rootCtx context.Context, openaiToken, openaiProxyUrl string) (*Client,
 			},
 		}
 	}
-	c := &Client{
-		ctx:    rootCtx,
-		openai: openai.NewClientWithConfig(config),
-		rl:     ratelimit.New(1),
-		closer: make(chan struct{}),
-	}
 
-	return c, nil
+	return newClient(rootCtx, config, RunPolling{
+		Timeout:     runTimeout,
+		MinInterval: 500 * time.Millisecond,
+		MaxInterval: 5 * time.Second,
+	}), nil
+}
+
+func newClient(rootCtx context.Context, config openai.ClientConfig, polling RunPolling) *Client {
+	return &Client{
+		ctx:     rootCtx,
+		openai:  openai.NewClientWithConfig(config),
+		rl:      ratelimit.New(1),
+		polling: polling,
+		closer:  make(chan struct{}),
+	}
 }
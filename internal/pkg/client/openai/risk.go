@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+)
+
+const riskInstructions = "You assess the risk of code changes for reviewers. " +
+	"High risk is a change which may break production, lose or corrupt data, or break API clients. " +
+	"Low risk is a local change which is easy to review and to revert."
+
+// ClassifyRisk asks for a JSON judgement of the risk of the change
+func (c *Client) ClassifyRisk(message string) (string, error) {
+	c.rl.Take()
+	response, err := c.openai.CreateChatCompletion(c.ctx, openai.ChatCompletionRequest{
+		Model: ReviewModel,
+		Messages: []openai.ChatCompletionMessage{{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: riskInstructions,
+		}, {
+			Role:    openai.ChatMessageRoleUser,
+			Content: truncate(message, 16000),
+		}},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to CreateChatCompletion from openai")
+	}
+
+	if len(response.Choices) == 0 {
+		return "", errors.New("no choices in openai response")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,129 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/sashabaranov/go-openai"
+)
+
+// cleanupTimeout is used for requests which must be done even if the client is closed
+const cleanupTimeout = 10 * time.Second
+
+// runStatusCancelled is missing in the openai package
+const runStatusCancelled openai.RunStatus = "cancelled"
+
+// waitRunToComplete polls the run with backoff until it reaches a terminal state.
+// The run is cancelled if the deadline is exceeded or the client is closed.
+func (c *Client) waitRunToComplete(threadID string, runID string) (string, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, c.polling.Timeout)
+	defer cancel()
+
+	interval := c.polling.MinInterval
+
+	for {
+		run, err := c.openai.RetrieveRun(ctx, threadID, runID)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.cancelRun(threadID, runID)
+				return "", errors.Wrapf(ctx.Err(), "run %s is not completed", runID)
+			}
+
+			return "", errors.Wrap(err, "failed to RetrieveRun from openai")
+		}
+
+		switch run.Status {
+		case openai.RunStatusCompleted:
+			return c.runReply(ctx, threadID, runID)
+		case openai.RunStatusFailed, openai.RunStatusExpired, runStatusCancelled:
+			return "", runError(run)
+		case openai.RunStatusRequiresAction:
+			// the assistant has no tools, so there is nothing to submit
+			c.cancelRun(threadID, runID)
+			return "", runError(run)
+		case openai.RunStatusQueued, openai.RunStatusInProgress, openai.RunStatusCancelling:
+			// keep waiting
+		default:
+			return "", errors.Errorf("openai run %s has unknown status: %s", runID, run.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.cancelRun(threadID, runID)
+			return "", errors.Wrapf(ctx.Err(), "run %s is not completed", runID)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > c.polling.MaxInterval {
+			interval = c.polling.MaxInterval
+		}
+	}
+}
+
+// runReply returns text of the assistant messages created by the run
+func (c *Client) runReply(ctx context.Context, threadID string, runID string) (string, error) {
+	order := "desc"
+
+	messages, err := c.openai.ListMessage(ctx, threadID, nil, &order, nil, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to ListMessage from openai")
+	}
+
+	reply := make([]string, 0, 1)
+
+	// newest first, so the reply parts are collected in reverse order
+	for i := len(messages.Messages) - 1; i >= 0; i-- {
+		msg := messages.Messages[i]
+
+		if msg.Role != openai.ChatMessageRoleAssistant || msg.RunID == nil || *msg.RunID != runID {
+			continue
+		}
+
+		for _, content := range msg.Content {
+			if content.Text != nil {
+				reply = append(reply, content.Text.Value)
+			}
+		}
+	}
+
+	if len(reply) == 0 {
+		return "", errors.Errorf("openai run %s completed without reply", runID)
+	}
+
+	return strings.Join(reply, "\n\n"), nil
+}
+
+// cancelRun stops the run, it works even if the client context is done
+func (c *Client) cancelRun(threadID string, runID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	_, err := c.openai.CancelRun(ctx, threadID, runID)
+	if err != nil {
+		log.Error().Err(err).Str("thread_id", threadID).Str("run_id", runID).Msg("failed to cancel openai run")
+	}
+}
+
+// deleteThread removes the thread created for a review, it works even if the client context is done
+func (c *Client) deleteThread(threadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	_, err := c.openai.DeleteThread(ctx, threadID)
+	if err != nil {
+		log.Error().Err(err).Str("thread_id", threadID).Msg("failed to delete openai thread")
+	}
+}
+
+func runError(run openai.Run) error {
+	if run.LastError == nil {
+		return errors.Errorf("openai run %s finished with status: %s", run.ID, run.Status)
+	}
+
+	return errors.Errorf("openai run %s finished with status: %s, %s: %s",
+		run.ID, run.Status, run.LastError.Code, run.LastError.Message)
+}
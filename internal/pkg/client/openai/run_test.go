@@ -0,0 +1,197 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer emulates Assistants API: the run goes through the statuses one by one on every retrieve
+type fakeServer struct {
+	mu       sync.Mutex
+	statuses []openai.RunStatus
+	lastErr  *openai.RunLastError
+	polls    int
+	canceled bool
+	deleted  bool
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+
+	var resp any
+
+	switch {
+	case r.Method == http.MethodGet && path == "/assistants":
+		name := AssistantName
+		resp = openai.AssistantsList{Assistants: []openai.Assistant{{ID: "asst_1", Name: &name}}}
+	case r.Method == http.MethodPost && path == "/threads":
+		resp = openai.Thread{ID: "thread_1"}
+	case r.Method == http.MethodDelete && path == "/threads/thread_1":
+		f.deleted = true
+		resp = openai.ThreadDeleteResponse{ID: "thread_1", Deleted: true}
+	case r.Method == http.MethodPost && path == "/threads/thread_1/runs":
+		resp = openai.Run{ID: "run_1", Status: openai.RunStatusQueued}
+	case r.Method == http.MethodGet && path == "/threads/thread_1/runs/run_1":
+		status := f.statuses[len(f.statuses)-1]
+		if f.polls < len(f.statuses) {
+			status = f.statuses[f.polls]
+		}
+		f.polls++
+
+		run := openai.Run{ID: "run_1", Status: status}
+		if status == openai.RunStatusFailed {
+			run.LastError = f.lastErr
+		}
+		resp = run
+	case r.Method == http.MethodPost && path == "/threads/thread_1/runs/run_1/cancel":
+		f.canceled = true
+		resp = openai.Run{ID: "run_1", Status: openai.RunStatusCancelling}
+	case r.Method == http.MethodGet && path == "/threads/thread_1/messages":
+		runID := "run_1"
+		resp = openai.MessagesList{Messages: []openai.Message{
+			{
+				Role:    openai.ChatMessageRoleAssistant,
+				RunID:   &runID,
+				Content: []openai.MessageContent{{Type: "text", Text: &openai.MessageText{Value: "Rename x to count"}}},
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: []openai.MessageContent{{Type: "text", Text: &openai.MessageText{Value: "diff"}}},
+			},
+		}}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func testClient(ctx context.Context, t *testing.T, f *fakeServer, timeout time.Duration) *Client {
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	config := openai.DefaultConfig("test-token")
+	config.BaseURL = srv.URL + "/v1"
+
+	return newClient(ctx, config, RunPolling{
+		Timeout:     timeout,
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+	})
+}
+
+func TestClient_waitRunToComplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		statuses []openai.RunStatus
+		lastErr  *openai.RunLastError
+		timeout  time.Duration
+		want     string
+		wantErr  string
+		canceled bool
+	}{
+		{
+			name:     "completed after queue",
+			statuses: []openai.RunStatus{openai.RunStatusQueued, openai.RunStatusInProgress, openai.RunStatusCompleted},
+			timeout:  time.Second,
+			want:     "Rename x to count",
+		},
+		{
+			name:     "failed with error details",
+			statuses: []openai.RunStatus{openai.RunStatusInProgress, openai.RunStatusFailed},
+			lastErr:  &openai.RunLastError{Code: openai.RunErrorRateLimitExceeded, Message: "quota exceeded"},
+			timeout:  time.Second,
+			wantErr:  "rate_limit_exceeded: quota exceeded",
+		},
+		{
+			name:     "expired",
+			statuses: []openai.RunStatus{openai.RunStatusExpired},
+			timeout:  time.Second,
+			wantErr:  "finished with status: expired",
+		},
+		{
+			name:     "cancelled",
+			statuses: []openai.RunStatus{openai.RunStatusCancelling, runStatusCancelled},
+			timeout:  time.Second,
+			wantErr:  "finished with status: cancelled",
+		},
+		{
+			name:     "requires action is cancelled",
+			statuses: []openai.RunStatus{openai.RunStatusRequiresAction},
+			timeout:  time.Second,
+			wantErr:  "finished with status: requires_action",
+			canceled: true,
+		},
+		{
+			name:     "deadline exceeded",
+			statuses: []openai.RunStatus{openai.RunStatusInProgress},
+			timeout:  50 * time.Millisecond,
+			wantErr:  "is not completed",
+			canceled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeServer{statuses: tt.statuses, lastErr: tt.lastErr}
+			c := testClient(context.Background(), t, f, tt.timeout)
+
+			actual, err := c.waitRunToComplete("thread_1", "run_1")
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.want, actual)
+			require.Equal(t, tt.canceled, f.canceled, "run cancellation")
+		})
+	}
+}
+
+func TestClient_waitRunToComplete_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := &fakeServer{statuses: []openai.RunStatus{openai.RunStatusInProgress}}
+	c := testClient(ctx, t, f, time.Minute)
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := c.waitRunToComplete("thread_1", "run_1")
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, f.canceled, "run must be cancelled on shutdown")
+}
+
+func TestClient_GenerateAICodeReviewComment_DeletesThread(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeServer{statuses: []openai.RunStatus{openai.RunStatusInProgress, openai.RunStatusCompleted}}
+	c := testClient(context.Background(), t, f, time.Second)
+
+	actual, err := c.GenerateAICodeReviewComment("diff")
+	require.NoError(t, err)
+	require.Equal(t, "Rename x to count", actual)
+	require.True(t, f.deleted, "thread must be deleted")
+}
@@ -43,7 +43,8 @@ func (c *Client) Subscribe() (chan ds.UserEvent, error) {
 	c.slackSocket = socketmode.New(c.slack)
 
 	handler := socketmode.NewSocketmodeHandler(c.slackSocket)
-	handler.HandleSlashCommand("/mr", mrRequestHandler(eventsChan)) // TODO: should be configurable
+	handler.HandleSlashCommand("/mr", commandHandler(eventsChan, ds.UserEventTypeMRRequest)) // TODO: should be configurable
+	handler.HandleSlashCommand("/away", commandHandler(eventsChan, ds.UserEventTypeAbsence))
 	handler.HandleDefault(func(evt *socketmode.Event, client *socketmode.Client) {
 	})
 
@@ -57,7 +58,7 @@ func (c *Client) Subscribe() (chan ds.UserEvent, error) {
 	return eventsChan, nil
 }
 
-func mrRequestHandler(eventsChan chan ds.UserEvent) func(*socketmode.Event, *socketmode.Client) {
+func commandHandler(eventsChan chan ds.UserEvent, eventType ds.UserEventType) func(*socketmode.Event, *socketmode.Client) {
 	return func(evt *socketmode.Event, client *socketmode.Client) {
 		cmd, ok := evt.Data.(slack.SlashCommand)
 		if !ok {
@@ -65,8 +66,9 @@ func mrRequestHandler(eventsChan chan ds.UserEvent) func(*socketmode.Event, *soc
 		}
 
 		eventsChan <- ds.UserEvent{
-			Type:   ds.UserEventTypeMRRequest,
+			Type:   eventType,
 			UserID: cmd.UserID,
+			Text:   cmd.Text,
 		}
 
 		client.Ack(*evt.Request, nil)
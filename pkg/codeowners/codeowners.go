@@ -0,0 +1,196 @@
+// Package codeowners parses GitLab CODEOWNERS files.
+//
+//   - patterns are gitignore-like (see pkg/glob), a pattern also matches everything inside a matched directory
+//   - the last matching entry of a section wins, owners of all sections are combined
+//   - `[Section] @owner` sets default owners of entries without owners, `^[Section]` is an optional section
+//   - owners are `@username`, `@group/subgroup` or emails
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jokerlee/gitlab-review-bot/pkg/glob"
+)
+
+// Locations of the CODEOWNERS file in the order GitLab looks for it
+var Locations = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+type Entry struct {
+	Pattern *glob.Pattern
+	Owners  []string
+}
+
+type Section struct {
+	Name     string
+	Optional bool
+	Entries  []*Entry
+}
+
+type File struct {
+	Sections []*Section
+}
+
+// Parse parses content of the CODEOWNERS file, entries before any section belong to the unnamed section
+func Parse(content []byte) (*File, error) {
+	f := &File{}
+	current := &Section{}
+	f.Sections = append(f.Sections, current)
+
+	var defaults []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if section, owners, ok := parseSection(line); ok {
+			current = section
+			defaults = owners
+			f.Sections = append(f.Sections, current)
+
+			continue
+		}
+
+		fields := splitFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := glob.Compile(fields[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", n)
+		}
+
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = defaults
+		}
+
+		current.Entries = append(current.Entries, &Entry{Pattern: pattern, Owners: owners})
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read codeowners")
+	}
+
+	return f, nil
+}
+
+// parseSection parses headers like "[Docs]", "^[Docs][2] @docs-team"
+func parseSection(line string) (*Section, []string, bool) {
+	section := &Section{}
+
+	if strings.HasPrefix(line, "^[") {
+		section.Optional = true
+		line = line[1:]
+	}
+
+	if !strings.HasPrefix(line, "[") {
+		return nil, nil, false
+	}
+
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return nil, nil, false
+	}
+
+	section.Name = strings.TrimSpace(line[1:end])
+	rest := line[end+1:]
+
+	// number of required approvals
+	if strings.HasPrefix(rest, "[") {
+		end = strings.Index(rest, "]")
+		if end < 0 {
+			return nil, nil, false
+		}
+
+		rest = rest[end+1:]
+	}
+
+	return section, strings.Fields(rest), true
+}
+
+// splitFields splits the line by spaces except escaped ones and drops the trailing comment
+func splitFields(line string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == '#' && current.Len() == 0:
+			return fields
+		case c == ' ' || c == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
+
+// Owners returns owners of the path from every section, without duplicates
+func (f *File) Owners(filePath string) []string {
+	seen := make(map[string]bool)
+	owners := make([]string, 0)
+
+	for _, section := range f.Sections {
+		var matched *Entry
+
+		// the last matching entry wins
+		for _, entry := range section.Entries {
+			if match(entry.Pattern, filePath) {
+				matched = entry
+			}
+		}
+
+		if matched == nil {
+			continue
+		}
+
+		for _, owner := range matched.Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	return owners
+}
+
+// match checks the path and its parent directories
+func match(p *glob.Pattern, filePath string) bool {
+	for filePath != "." && filePath != "/" && filePath != "" {
+		if p.Match(filePath) {
+			return true
+		}
+
+		filePath = path.Dir(filePath)
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const example = `# default owners
+* @alice
+
+*.go @bob @backend/core
+/docs/ @carol
+/internal/app/repository @dave
+internal/app/repository/teams.go
+my\ file.txt @erin # with a comment
+
+[Database] @dba-team
+migrations/
+*.sql @frank
+
+^[Frontend][2]
+*.ts @gina
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	f, err := Parse([]byte(example))
+	require.NoError(t, err)
+	require.Len(t, f.Sections, 3)
+	require.Equal(t, "Database", f.Sections[1].Name)
+	require.True(t, f.Sections[2].Optional)
+	require.Equal(t, []string{"@dba-team"}, f.Sections[1].Entries[0].Owners, "section default owners")
+	require.Empty(t, f.Sections[0].Entries[4].Owners, "no owners")
+}
+
+func TestFile_Owners(t *testing.T) {
+	t.Parallel()
+
+	f, err := Parse([]byte(example))
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		"README.md":                           {"@alice"},
+		"cmd/main.go":                         {"@bob", "@backend/core"},
+		"docs/setup.md":                       {"@carol"},
+		"pkg/docs/setup.md":                   {"@alice"},
+		"internal/app/repository/projects.go": {"@dave"},
+		"internal/app/repository/teams.go":    {},
+		"my file.txt":                         {"@erin"},
+		"migrations/001_init.up.sql":          {"@alice", "@frank"},
+		"migrations/002.yml":                  {"@alice", "@dba-team"},
+		"web/app.ts":                          {"@alice", "@gina"},
+	}
+
+	for path, want := range tests {
+		require.Equal(t, want, f.Owners(path), path)
+	}
+}
@@ -0,0 +1,137 @@
+// Package glob matches slash-separated paths against gitignore-like patterns.
+//
+//   - `*` matches any sequence of characters except `/`
+//   - `**` matches any sequence of characters including `/` (zero or more directories)
+//   - `?` matches any single character except `/`
+//   - a pattern without `/` matches the file name in any directory (e.g. `*.go`)
+//   - a pattern ending with `/` matches everything inside the directory
+//
+// Anchored patterns always match the whole path, e.g. branch names.
+package glob
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Pattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+// Compile converts the pattern into a regular expression
+func Compile(pattern string) (*Pattern, error) {
+	return compile(pattern, strings.Contains(strings.TrimSuffix(pattern, "/"), "/"))
+}
+
+// CompileAnchored is like Compile but a pattern without `/` matches the whole path only
+func CompileAnchored(pattern string) (*Pattern, error) {
+	return compile(pattern, true)
+}
+
+func compile(pattern string, anchored bool) (*Pattern, error) {
+	if pattern == "" {
+		return nil, errors.New("empty glob pattern")
+	}
+
+	p := strings.TrimPrefix(pattern, "/")
+
+	if strings.HasSuffix(p, "/") {
+		p += "**"
+	}
+
+	var re strings.Builder
+
+	re.WriteString("^")
+
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+
+				// "**/" matches zero or more directories
+				if i+1 < len(p) && p[i+1] == '/' {
+					i++
+					re.WriteString("(?:.*/)?")
+				} else {
+					re.WriteString(".*")
+				}
+
+				continue
+			}
+
+			re.WriteString("[^/]*")
+		case '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	re.WriteString("$")
+
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid glob pattern %q", pattern)
+	}
+
+	return &Pattern{source: pattern, re: compiled}, nil
+}
+
+// MustCompile is like Compile but panics on invalid pattern
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func (p *Pattern) Match(path string) bool {
+	return p.re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match compiles the pattern and matches the path, invalid patterns never match
+func Match(pattern, path string) bool {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	return p.Match(path)
+}
+
+// MatchAnchored compiles the anchored pattern and matches the path, invalid patterns never match
+func MatchAnchored(pattern, path string) bool {
+	p, err := CompileAnchored(pattern)
+	if err != nil {
+		return false
+	}
+
+	return p.Match(path)
+}
+
+// MatchAny checks if the path matches at least one of the patterns
+func MatchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, path) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*.go", path: "main.go", want: true},
+		{pattern: "*.go", path: "internal/app/ds/team.go", want: true},
+		{pattern: "*.go", path: "internal/app/ds/team.gox", want: false},
+		{pattern: "*_test.go", path: "internal/app/ds/team_test.go", want: true},
+		{pattern: "config/config.dist.yml", path: "config/config.dist.yml", want: true},
+		{pattern: "/config/config.dist.yml", path: "config/config.dist.yml", want: true},
+		{pattern: "config/config.dist.yml", path: "other/config/config.dist.yml", want: false},
+		{pattern: "migrations/*.up.sql", path: "migrations/0001_init.up.sql", want: true},
+		{pattern: "migrations/*.up.sql", path: "migrations/old/0001_init.up.sql", want: false},
+		{pattern: "migrations/**/*.sql", path: "migrations/old/0001_init.up.sql", want: true},
+		{pattern: "migrations/**/*.sql", path: "migrations/0001_init.up.sql", want: true},
+		{pattern: "proto/", path: "proto/api/v1/service.proto", want: true},
+		{pattern: "proto/", path: "internal/proto/x.go", want: true},
+		{pattern: "/proto/", path: "internal/proto/x.go", want: false},
+		{pattern: "docs/**", path: "docs/readme/assets/gopher.png", want: true},
+		{pattern: "release/*", path: "release/1.0.0", want: true},
+		{pattern: "release/*", path: "release/1.0/hotfix", want: false},
+		{pattern: "file?.txt", path: "file1.txt", want: true},
+		{pattern: "file?.txt", path: "file10.txt", want: false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, Match(tt.pattern, tt.path), "%s ~ %s", tt.pattern, tt.path)
+	}
+}
+
+func TestMatchAnchored(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "main", path: "main", want: true},
+		{pattern: "main", path: "feature/main", want: false},
+		{pattern: "*", path: "feature/main", want: false},
+		{pattern: "**", path: "feature/main", want: true},
+		{pattern: "hotfix-*", path: "hotfix-1", want: true},
+		{pattern: "hotfix-*", path: "team/hotfix-1", want: false},
+		{pattern: "release/*", path: "release/1.0.0", want: true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, MatchAnchored(tt.pattern, tt.path), "%s ~ %s", tt.pattern, tt.path)
+	}
+}
+
+func TestCompile_Empty(t *testing.T) {
+	_, err := Compile("")
+	require.Error(t, err)
+}
@@ -0,0 +1,209 @@
+// Package ics reads events of iCalendar (RFC 5545) files, only the fields needed for absences are supported.
+package ics
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	// End is exclusive, for all-day events it is the next day after the last one
+	End    time.Time
+	AllDay bool
+	// Cancelled events have STATUS:CANCELLED
+	Cancelled bool
+}
+
+// Parse returns VEVENTs of the calendar, dates without a time zone are in loc
+func Parse(content []byte, loc *time.Location) ([]*Event, error) {
+	events := make([]*Event, 0)
+
+	var (
+		current  *Event
+		hasEnd   bool
+		duration time.Duration
+	)
+
+	for n, line := range unfold(content) {
+		name, params, value := property(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &Event{}
+			hasEnd = false
+			duration = 0
+		case name == "END" && value == "VEVENT" && current != nil:
+			if current.Start.IsZero() {
+				return nil, errors.Errorf("line %d: event %q without DTSTART", n+1, current.UID)
+			}
+
+			if !hasEnd {
+				switch {
+				case duration > 0:
+					current.End = current.Start.Add(duration)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				default:
+					current.End = current.Start
+				}
+			}
+
+			events = append(events, current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "STATUS":
+			current.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART" || name == "DTEND":
+			t, allDay, err := parseTime(value, params, loc)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", n+1)
+			}
+
+			if name == "DTSTART" {
+				current.Start, current.AllDay = t, allDay
+			} else {
+				current.End, hasEnd = t, true
+			}
+		case name == "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", n+1)
+			}
+
+			duration = d
+		}
+	}
+
+	return events, nil
+}
+
+// unfold joins lines continued with a leading space or tab
+func unfold(content []byte) []string {
+	lines := make([]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// property splits "DTSTART;TZID=Europe/Berlin:20240501T090000" into the name, params and value
+func property(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		k, v, _ := strings.Cut(part, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, errors.Wrapf(err, "invalid date %q", value)
+		}
+
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, errors.Wrapf(err, "invalid time %q", value)
+		}
+
+		return t, false, nil
+	}
+
+	if tzid, ok := params["TZID"]; ok {
+		tz, err := time.LoadLocation(tzid)
+		if err == nil {
+			loc = tz
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "invalid time %q", value)
+	}
+
+	return t, false, nil
+}
+
+// parseDuration supports day, week and time durations like "P1D", "P2W", "PT8H30M"
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if s == value || strings.HasPrefix(value, "-") {
+		return 0, errors.Errorf("invalid duration %q", value)
+	}
+
+	var (
+		d      time.Duration
+		number int
+		inTime bool
+	)
+
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int(c-'0')
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		case c == 'W':
+			d += time.Duration(number) * 7 * 24 * time.Hour
+		case c == 'D':
+			d += time.Duration(number) * 24 * time.Hour
+		case c == 'H' && inTime:
+			d += time.Duration(number) * time.Hour
+		case c == 'M' && inTime:
+			d += time.Duration(number) * time.Minute
+		case c == 'S' && inTime:
+			d += time.Duration(number) * time.Second
+		default:
+			return 0, errors.Errorf("invalid duration %q", value)
+		}
+
+		number = 0
+	}
+
+	return d, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,75 @@
+package ics
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/vacations.ics")
+	require.NoError(t, err)
+
+	events, err := Parse(content, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	require.Equal(t, &Event{
+		UID:     "vacation-1@example.com",
+		Summary: "Vacation, sea",
+		Start:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC),
+		AllDay:  true,
+	}, events[0])
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	require.Equal(t, "Doctor appointment", events[1].Summary, "folded line")
+	require.True(t, events[1].Start.Equal(time.Date(2024, 5, 15, 9, 0, 0, 0, berlin)))
+	require.Equal(t, 3*time.Hour, events[1].End.Sub(events[1].Start))
+
+	require.True(t, events[2].AllDay)
+	require.Equal(t, 24*time.Hour, events[2].End.Sub(events[2].Start), "all-day event without DTEND lasts a day")
+
+	require.True(t, events[3].Cancelled)
+	require.Equal(t, time.Date(2024, 6, 3, 18, 0, 0, 0, time.UTC), events[3].End)
+}
+
+func TestParse_invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte("BEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT\n"), time.UTC)
+	require.Error(t, err)
+
+	_, err = Parse([]byte("BEGIN:VEVENT\nDTSTART:2024-05-01\nEND:VEVENT\n"), time.UTC)
+	require.Error(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Duration{
+		"P1D":      24 * time.Hour,
+		"P2W":      14 * 24 * time.Hour,
+		"PT8H30M":  8*time.Hour + 30*time.Minute,
+		"P1DT12H":  36 * time.Hour,
+		"+PT15M":   15 * time.Minute,
+		"PT1H0M5S": time.Hour + 5*time.Second,
+	}
+
+	for value, want := range tests {
+		d, err := parseDuration(value)
+		require.NoError(t, err, value)
+		require.Equal(t, want, d, value)
+	}
+
+	for _, value := range []string{"1D", "-P1D", "P1X", "P1H"} {
+		_, err := parseDuration(value)
+		require.Error(t, err, value)
+	}
+}
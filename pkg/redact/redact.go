@@ -0,0 +1,205 @@
+// Package redact masks secrets and personal data in text (e.g. unified diffs) before it leaves the service.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DetectorPrivateKey  = "private_key"
+	DetectorAWSKey      = "aws_access_key"
+	DetectorGitHubToken = "github_token"
+	DetectorGitLabToken = "gitlab_token"
+	DetectorSlackToken  = "slack_token"
+	DetectorOpenAIKey   = "openai_key"
+	DetectorGoogleKey   = "google_api_key"
+	DetectorStripeKey   = "stripe_key"
+	DetectorJWT         = "jwt"
+	DetectorEntropy     = "high_entropy_string"
+	DetectorEmail       = "email"
+)
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY( BLOCK)?-----`)
+)
+
+// Detector finds sensitive values in a line of text
+type Detector struct {
+	Name string
+	// Secret is false for personal data (e.g. emails), such findings are masked but not reported as leaked secrets
+	Secret  bool
+	Pattern *regexp.Regexp
+	// Validate is an optional additional check of the matched value
+	Validate func(match string) bool
+}
+
+// Finding describes a masked value, the value itself is never kept
+type Finding struct {
+	Detector string
+	Secret   bool
+	// Line is 0-based line number in the original text
+	Line int
+}
+
+// BuiltinDetectors returns detectors of well-known key formats, high-entropy strings and emails
+func BuiltinDetectors() []Detector {
+	return []Detector{
+		{Name: DetectorAWSKey, Secret: true, Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: DetectorGitHubToken, Secret: true, Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+		{Name: DetectorGitLabToken, Secret: true, Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}`)},
+		{Name: DetectorSlackToken, Secret: true, Pattern: regexp.MustCompile(`\bx(ox[abprs]|app)-[A-Za-z0-9\-]{10,}`)},
+		{Name: DetectorOpenAIKey, Secret: true, Pattern: regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`)},
+		{Name: DetectorGoogleKey, Secret: true, Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`)},
+		{Name: DetectorStripeKey, Secret: true, Pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}`)},
+		{Name: DetectorJWT, Secret: true, Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+		{Name: DetectorEntropy, Secret: true, Pattern: regexp.MustCompile(`[A-Za-z0-9+/_\-]{24,}={0,2}`), Validate: highEntropy},
+		{Name: DetectorEmail, Secret: false, Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	}
+}
+
+// Redactor masks values found by detectors
+type Redactor struct {
+	detectors  []Detector
+	privateKey bool
+}
+
+// Pattern is a named custom regular expression
+type Pattern struct {
+	Name  string
+	Regex string
+}
+
+// New creates Redactor with builtin detectors (except disabled ones) and custom patterns.
+// Custom patterns are treated as secrets.
+func New(custom []Pattern, disabled ...string) (*Redactor, error) {
+	r := &Redactor{privateKey: true}
+
+	off := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		off[name] = true
+	}
+
+	r.privateKey = !off[DetectorPrivateKey]
+
+	for _, d := range BuiltinDetectors() {
+		if off[d.Name] {
+			continue
+		}
+
+		r.detectors = append(r.detectors, d)
+	}
+
+	for i, p := range custom {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile custom pattern %q", p.Name)
+		}
+
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("custom_%d", i)
+		}
+
+		r.detectors = append(r.detectors, Detector{Name: name, Secret: true, Pattern: re})
+	}
+
+	return r, nil
+}
+
+// Mask returns replacement of the value found by the detector
+func Mask(detector string) string {
+	return "[REDACTED:" + detector + "]"
+}
+
+// Redact masks all the findings in the text. Private key blocks are masked line by line entirely.
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	lines := strings.Split(text, "\n")
+	findings := make([]Finding, 0)
+
+	inPrivateKey := false
+
+	for i, line := range lines {
+		if r.privateKey && (inPrivateKey || privateKeyBegin.MatchString(line)) {
+			if !inPrivateKey {
+				findings = append(findings, Finding{Detector: DetectorPrivateKey, Secret: true, Line: i})
+			}
+
+			inPrivateKey = !privateKeyEnd.MatchString(line)
+			lines[i] = keepDiffPrefix(line) + Mask(DetectorPrivateKey)
+
+			continue
+		}
+
+		for _, d := range r.detectors {
+			line = d.Pattern.ReplaceAllStringFunc(line, func(match string) string {
+				if d.Validate != nil && !d.Validate(match) {
+					return match
+				}
+
+				findings = append(findings, Finding{Detector: d.Name, Secret: d.Secret, Line: i})
+
+				return Mask(d.Name)
+			})
+		}
+
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "\n"), findings
+}
+
+// keepDiffPrefix returns "+", "-" or " " prefix of a unified diff line
+func keepDiffPrefix(line string) string {
+	if line != "" && strings.ContainsAny(line[:1], "+- ") {
+		return line[:1]
+	}
+
+	return ""
+}
+
+// highEntropy detects random-looking tokens: mixed case with digits and Shannon entropy above 4.3 bits per char
+// (long CamelCase identifiers are usually below).
+// Hex-only strings (e.g. commit SHAs, checksums) are intentionally ignored.
+func highEntropy(s string) bool {
+	var upper, lower, digit bool
+
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	return entropy(s) >= 4.3
+}
+
+func entropy(s string) float64 {
+	freq := make(map[rune]float64, len(s))
+	for _, c := range s {
+		freq[c]++
+	}
+
+	var e float64
+
+	n := float64(len(s))
+	for _, f := range freq {
+		p := f / n
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
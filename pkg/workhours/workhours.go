@@ -0,0 +1,169 @@
+// Package workhours counts time within working hours of working days.
+package workhours
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxDays limits the search of working time, a calendar without working days would loop forever
+const maxDays = 3 * 366
+
+type Calendar struct {
+	loc *time.Location
+	// start and end of the working day since midnight
+	start time.Duration
+	end   time.Duration
+	days  map[time.Weekday]bool
+}
+
+// New creates the calendar, start and end are "HH:MM", weekdays are working days
+func New(loc *time.Location, start, end string, weekdays []time.Weekday) (*Calendar, error) {
+	c := &Calendar{
+		loc:  loc,
+		days: make(map[time.Weekday]bool, len(weekdays)),
+	}
+
+	var err error
+
+	c.start, err = parseClock(start)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid start")
+	}
+
+	c.end, err = parseClock(end)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid end")
+	}
+
+	if c.end <= c.start {
+		return nil, errors.New("working day must end after it starts")
+	}
+
+	for _, day := range weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return nil, errors.Errorf("invalid weekday %d", day)
+		}
+
+		c.days[day] = true
+	}
+
+	if len(c.days) == 0 {
+		return nil, errors.New("no working days")
+	}
+
+	return c, nil
+}
+
+func parseClock(clock string) (time.Duration, error) {
+	h, m, ok := strings.Cut(clock, ":")
+	if !ok {
+		return 0, errors.Errorf("%q is not HH:MM", clock)
+	}
+
+	hours, err := strconv.Atoi(h)
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, errors.Errorf("%q is not HH:MM", clock)
+	}
+
+	minutes, err := strconv.Atoi(m)
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes > 0) {
+		return 0, errors.Errorf("%q is not HH:MM", clock)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// DayLength is working time of a working day
+func (c *Calendar) DayLength() time.Duration {
+	return c.end - c.start
+}
+
+// ParseDuration parses time.Duration, or working days like "2d"
+func (c *Calendar) ParseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || n < 0 {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+
+		return time.Duration(n) * c.DayLength(), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid duration %q", s)
+	}
+
+	if d < 0 {
+		return 0, errors.Errorf("negative duration %q", s)
+	}
+
+	return d, nil
+}
+
+// workingDay returns working hours of the day of t
+func (c *Calendar) workingDay(t time.Time) (time.Time, time.Time, bool) {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+
+	return midnight.Add(c.start), midnight.Add(c.end), c.days[midnight.Weekday()]
+}
+
+// Add returns the moment when d of working time passes since from
+func (c *Calendar) Add(from time.Time, d time.Duration) time.Time {
+	t := from.In(c.loc)
+
+	for i := 0; i < maxDays; i++ {
+		start, end, working := c.workingDay(t)
+
+		if working && t.Before(end) {
+			if t.Before(start) {
+				t = start
+			}
+
+			left := end.Sub(t)
+			if d <= left {
+				return t.Add(d)
+			}
+
+			d -= left
+		}
+
+		// the next midnight, AddDate keeps it right on DST changes
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc).AddDate(0, 0, 1)
+	}
+
+	return t
+}
+
+// Between returns working time from one moment to another, 0 if to is before from
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	var d time.Duration
+
+	t := from.In(c.loc)
+
+	for i := 0; i < maxDays && t.Before(to); i++ {
+		start, end, working := c.workingDay(t)
+
+		if working {
+			if t.Before(start) {
+				t = start
+			}
+
+			if to.Before(end) {
+				end = to
+			}
+
+			if end.After(t) {
+				d += end.Sub(t)
+			}
+		}
+
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc).AddDate(0, 0, 1)
+	}
+
+	return d
+}
@@ -0,0 +1,93 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func calendar(t *testing.T) *Calendar {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	c, err := New(loc, "10:00", "18:00", weekdays)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		start, end string
+		days       []time.Weekday
+	}{
+		{name: "end before start", start: "18:00", end: "10:00", days: weekdays},
+		{name: "invalid clock", start: "10", end: "18:00", days: weekdays},
+		{name: "invalid minutes", start: "10:60", end: "18:00", days: weekdays},
+		{name: "no days", start: "10:00", end: "18:00"},
+		{name: "invalid day", start: "10:00", end: "18:00", days: []time.Weekday{7}},
+	}
+
+	for _, tt := range tests {
+		_, err := New(time.UTC, tt.start, tt.end, tt.days)
+		require.Error(t, err, tt.name)
+	}
+}
+
+func TestCalendar_Add(t *testing.T) {
+	t.Parallel()
+
+	c := calendar(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, c.loc)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{name: "same day", from: at(4, 11, 0), d: 4 * time.Hour, want: at(4, 15, 0)},
+		{name: "before working hours", from: at(4, 7, 0), d: time.Hour, want: at(4, 11, 0)},
+		{name: "next day", from: at(4, 16, 0), d: 4 * time.Hour, want: at(5, 12, 0)},
+		{name: "after working hours", from: at(4, 20, 0), d: time.Hour, want: at(5, 11, 0)},
+		{name: "over the weekend", from: at(8, 17, 0), d: 2 * time.Hour, want: at(11, 11, 0)},
+		{name: "on the weekend", from: at(9, 12, 0), d: 30 * time.Minute, want: at(11, 10, 30)},
+		{name: "the end of the day", from: at(4, 14, 0), d: 4 * time.Hour, want: at(4, 18, 0)},
+		// summer time starts on Sunday March 31
+		{name: "over DST change", from: at(29, 17, 0), d: 2 * time.Hour, want: time.Date(2024, 4, 1, 11, 0, 0, 0, c.loc)},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, c.Add(tt.from, tt.d), tt.name)
+		require.Equal(t, tt.d, c.Between(tt.from, tt.want), tt.name)
+	}
+
+	require.Zero(t, c.Between(at(5, 12, 0), at(4, 12, 0)), "to is before from")
+}
+
+func TestCalendar_ParseDuration(t *testing.T) {
+	t.Parallel()
+
+	c := calendar(t)
+
+	d, err := c.ParseDuration("4h")
+	require.NoError(t, err)
+	require.Equal(t, 4*time.Hour, d)
+
+	d, err = c.ParseDuration("2d")
+	require.NoError(t, err)
+	require.Equal(t, 16*time.Hour, d, "a day is a working day")
+
+	for _, s := range []string{"", "d", "-1h", "2w"} {
+		_, err = c.ParseDuration(s)
+		require.Error(t, err, s)
+	}
+}